@@ -0,0 +1,632 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: album/v1/album.proto
+
+package albumv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AlbumEvent_Type int32
+
+const (
+	AlbumEvent_TYPE_UNSPECIFIED AlbumEvent_Type = 0
+	AlbumEvent_TYPE_CREATED     AlbumEvent_Type = 1
+	AlbumEvent_TYPE_UPDATED     AlbumEvent_Type = 2
+	AlbumEvent_TYPE_DELETED     AlbumEvent_Type = 3
+)
+
+// Enum value maps for AlbumEvent_Type.
+var (
+	AlbumEvent_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_CREATED",
+		2: "TYPE_UPDATED",
+		3: "TYPE_DELETED",
+	}
+	AlbumEvent_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_CREATED":     1,
+		"TYPE_UPDATED":     2,
+		"TYPE_DELETED":     3,
+	}
+)
+
+func (x AlbumEvent_Type) Enum() *AlbumEvent_Type {
+	p := new(AlbumEvent_Type)
+	*p = x
+	return p
+}
+
+func (x AlbumEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AlbumEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_album_v1_album_proto_enumTypes[0].Descriptor()
+}
+
+func (AlbumEvent_Type) Type() protoreflect.EnumType {
+	return &file_album_v1_album_proto_enumTypes[0]
+}
+
+func (x AlbumEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AlbumEvent_Type.Descriptor instead.
+func (AlbumEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_album_v1_album_proto_rawDescGZIP(), []int{8, 0}
+}
+
+type Album struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Artist        string                 `protobuf:"bytes,2,opt,name=artist,proto3" json:"artist,omitempty"`
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Year          int32                  `protobuf:"varint,4,opt,name=year,proto3" json:"year,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Album) Reset() {
+	*x = Album{}
+	mi := &file_album_v1_album_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Album) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Album) ProtoMessage() {}
+
+func (x *Album) ProtoReflect() protoreflect.Message {
+	mi := &file_album_v1_album_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Album.ProtoReflect.Descriptor instead.
+func (*Album) Descriptor() ([]byte, []int) {
+	return file_album_v1_album_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Album) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Album) GetArtist() string {
+	if x != nil {
+		return x.Artist
+	}
+	return ""
+}
+
+func (x *Album) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Album) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+type CreateAlbumRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Album         *Album                 `protobuf:"bytes,1,opt,name=album,proto3" json:"album,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAlbumRequest) Reset() {
+	*x = CreateAlbumRequest{}
+	mi := &file_album_v1_album_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAlbumRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAlbumRequest) ProtoMessage() {}
+
+func (x *CreateAlbumRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_album_v1_album_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAlbumRequest.ProtoReflect.Descriptor instead.
+func (*CreateAlbumRequest) Descriptor() ([]byte, []int) {
+	return file_album_v1_album_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateAlbumRequest) GetAlbum() *Album {
+	if x != nil {
+		return x.Album
+	}
+	return nil
+}
+
+type GetAlbumRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAlbumRequest) Reset() {
+	*x = GetAlbumRequest{}
+	mi := &file_album_v1_album_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAlbumRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAlbumRequest) ProtoMessage() {}
+
+func (x *GetAlbumRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_album_v1_album_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAlbumRequest.ProtoReflect.Descriptor instead.
+func (*GetAlbumRequest) Descriptor() ([]byte, []int) {
+	return file_album_v1_album_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetAlbumRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListAlbumsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PageSize      int32                  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAlbumsRequest) Reset() {
+	*x = ListAlbumsRequest{}
+	mi := &file_album_v1_album_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAlbumsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAlbumsRequest) ProtoMessage() {}
+
+func (x *ListAlbumsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_album_v1_album_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAlbumsRequest.ProtoReflect.Descriptor instead.
+func (*ListAlbumsRequest) Descriptor() ([]byte, []int) {
+	return file_album_v1_album_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListAlbumsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListAlbumsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListAlbumsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Albums        []*Album               `protobuf:"bytes,1,rep,name=albums,proto3" json:"albums,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAlbumsResponse) Reset() {
+	*x = ListAlbumsResponse{}
+	mi := &file_album_v1_album_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAlbumsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAlbumsResponse) ProtoMessage() {}
+
+func (x *ListAlbumsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_album_v1_album_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAlbumsResponse.ProtoReflect.Descriptor instead.
+func (*ListAlbumsResponse) Descriptor() ([]byte, []int) {
+	return file_album_v1_album_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListAlbumsResponse) GetAlbums() []*Album {
+	if x != nil {
+		return x.Albums
+	}
+	return nil
+}
+
+func (x *ListAlbumsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type UpdateAlbumRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Album         *Album                 `protobuf:"bytes,1,opt,name=album,proto3" json:"album,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAlbumRequest) Reset() {
+	*x = UpdateAlbumRequest{}
+	mi := &file_album_v1_album_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAlbumRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAlbumRequest) ProtoMessage() {}
+
+func (x *UpdateAlbumRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_album_v1_album_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAlbumRequest.ProtoReflect.Descriptor instead.
+func (*UpdateAlbumRequest) Descriptor() ([]byte, []int) {
+	return file_album_v1_album_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateAlbumRequest) GetAlbum() *Album {
+	if x != nil {
+		return x.Album
+	}
+	return nil
+}
+
+type DeleteAlbumRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteAlbumRequest) Reset() {
+	*x = DeleteAlbumRequest{}
+	mi := &file_album_v1_album_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAlbumRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAlbumRequest) ProtoMessage() {}
+
+func (x *DeleteAlbumRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_album_v1_album_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAlbumRequest.ProtoReflect.Descriptor instead.
+func (*DeleteAlbumRequest) Descriptor() ([]byte, []int) {
+	return file_album_v1_album_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteAlbumRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type WatchAlbumsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchAlbumsRequest) Reset() {
+	*x = WatchAlbumsRequest{}
+	mi := &file_album_v1_album_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchAlbumsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchAlbumsRequest) ProtoMessage() {}
+
+func (x *WatchAlbumsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_album_v1_album_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchAlbumsRequest.ProtoReflect.Descriptor instead.
+func (*WatchAlbumsRequest) Descriptor() ([]byte, []int) {
+	return file_album_v1_album_proto_rawDescGZIP(), []int{7}
+}
+
+type AlbumEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          AlbumEvent_Type        `protobuf:"varint,1,opt,name=type,proto3,enum=album.v1.AlbumEvent_Type" json:"type,omitempty"`
+	Album         *Album                 `protobuf:"bytes,2,opt,name=album,proto3" json:"album,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlbumEvent) Reset() {
+	*x = AlbumEvent{}
+	mi := &file_album_v1_album_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlbumEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlbumEvent) ProtoMessage() {}
+
+func (x *AlbumEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_album_v1_album_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlbumEvent.ProtoReflect.Descriptor instead.
+func (*AlbumEvent) Descriptor() ([]byte, []int) {
+	return file_album_v1_album_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AlbumEvent) GetType() AlbumEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return AlbumEvent_TYPE_UNSPECIFIED
+}
+
+func (x *AlbumEvent) GetAlbum() *Album {
+	if x != nil {
+		return x.Album
+	}
+	return nil
+}
+
+var File_album_v1_album_proto protoreflect.FileDescriptor
+
+const file_album_v1_album_proto_rawDesc = "" +
+	"\n" +
+	"\x14album/v1/album.proto\x12\balbum.v1\x1a\x1bgoogle/protobuf/empty.proto\"Y\n" +
+	"\x05Album\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06artist\x18\x02 \x01(\tR\x06artist\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x12\n" +
+	"\x04year\x18\x04 \x01(\x05R\x04year\";\n" +
+	"\x12CreateAlbumRequest\x12%\n" +
+	"\x05album\x18\x01 \x01(\v2\x0f.album.v1.AlbumR\x05album\"!\n" +
+	"\x0fGetAlbumRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"O\n" +
+	"\x11ListAlbumsRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\"e\n" +
+	"\x12ListAlbumsResponse\x12'\n" +
+	"\x06albums\x18\x01 \x03(\v2\x0f.album.v1.AlbumR\x06albums\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\";\n" +
+	"\x12UpdateAlbumRequest\x12%\n" +
+	"\x05album\x18\x01 \x01(\v2\x0f.album.v1.AlbumR\x05album\"$\n" +
+	"\x12DeleteAlbumRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x14\n" +
+	"\x12WatchAlbumsRequest\"\xb6\x01\n" +
+	"\n" +
+	"AlbumEvent\x12-\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x19.album.v1.AlbumEvent.TypeR\x04type\x12%\n" +
+	"\x05album\x18\x02 \x01(\v2\x0f.album.v1.AlbumR\x05album\"R\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fTYPE_CREATED\x10\x01\x12\x10\n" +
+	"\fTYPE_UPDATED\x10\x02\x12\x10\n" +
+	"\fTYPE_DELETED\x10\x032\x95\x03\n" +
+	"\fAlbumService\x12<\n" +
+	"\vCreateAlbum\x12\x1c.album.v1.CreateAlbumRequest\x1a\x0f.album.v1.Album\x126\n" +
+	"\bGetAlbum\x12\x19.album.v1.GetAlbumRequest\x1a\x0f.album.v1.Album\x12G\n" +
+	"\n" +
+	"ListAlbums\x12\x1b.album.v1.ListAlbumsRequest\x1a\x1c.album.v1.ListAlbumsResponse\x12<\n" +
+	"\vUpdateAlbum\x12\x1c.album.v1.UpdateAlbumRequest\x1a\x0f.album.v1.Album\x12C\n" +
+	"\vDeleteAlbum\x12\x1c.album.v1.DeleteAlbumRequest\x1a\x16.google.protobuf.Empty\x12C\n" +
+	"\vWatchAlbums\x12\x1c.album.v1.WatchAlbumsRequest\x1a\x14.album.v1.AlbumEvent0\x01B9Z7go-masters/10-cloud_ready/cloudapp/api/album/v1;albumv1b\x06proto3"
+
+var (
+	file_album_v1_album_proto_rawDescOnce sync.Once
+	file_album_v1_album_proto_rawDescData []byte
+)
+
+func file_album_v1_album_proto_rawDescGZIP() []byte {
+	file_album_v1_album_proto_rawDescOnce.Do(func() {
+		file_album_v1_album_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_album_v1_album_proto_rawDesc), len(file_album_v1_album_proto_rawDesc)))
+	})
+	return file_album_v1_album_proto_rawDescData
+}
+
+var file_album_v1_album_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_album_v1_album_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_album_v1_album_proto_goTypes = []any{
+	(AlbumEvent_Type)(0),       // 0: album.v1.AlbumEvent.Type
+	(*Album)(nil),              // 1: album.v1.Album
+	(*CreateAlbumRequest)(nil), // 2: album.v1.CreateAlbumRequest
+	(*GetAlbumRequest)(nil),    // 3: album.v1.GetAlbumRequest
+	(*ListAlbumsRequest)(nil),  // 4: album.v1.ListAlbumsRequest
+	(*ListAlbumsResponse)(nil), // 5: album.v1.ListAlbumsResponse
+	(*UpdateAlbumRequest)(nil), // 6: album.v1.UpdateAlbumRequest
+	(*DeleteAlbumRequest)(nil), // 7: album.v1.DeleteAlbumRequest
+	(*WatchAlbumsRequest)(nil), // 8: album.v1.WatchAlbumsRequest
+	(*AlbumEvent)(nil),         // 9: album.v1.AlbumEvent
+	(*emptypb.Empty)(nil),      // 10: google.protobuf.Empty
+}
+var file_album_v1_album_proto_depIdxs = []int32{
+	1,  // 0: album.v1.CreateAlbumRequest.album:type_name -> album.v1.Album
+	1,  // 1: album.v1.ListAlbumsResponse.albums:type_name -> album.v1.Album
+	1,  // 2: album.v1.UpdateAlbumRequest.album:type_name -> album.v1.Album
+	0,  // 3: album.v1.AlbumEvent.type:type_name -> album.v1.AlbumEvent.Type
+	1,  // 4: album.v1.AlbumEvent.album:type_name -> album.v1.Album
+	2,  // 5: album.v1.AlbumService.CreateAlbum:input_type -> album.v1.CreateAlbumRequest
+	3,  // 6: album.v1.AlbumService.GetAlbum:input_type -> album.v1.GetAlbumRequest
+	4,  // 7: album.v1.AlbumService.ListAlbums:input_type -> album.v1.ListAlbumsRequest
+	6,  // 8: album.v1.AlbumService.UpdateAlbum:input_type -> album.v1.UpdateAlbumRequest
+	7,  // 9: album.v1.AlbumService.DeleteAlbum:input_type -> album.v1.DeleteAlbumRequest
+	8,  // 10: album.v1.AlbumService.WatchAlbums:input_type -> album.v1.WatchAlbumsRequest
+	1,  // 11: album.v1.AlbumService.CreateAlbum:output_type -> album.v1.Album
+	1,  // 12: album.v1.AlbumService.GetAlbum:output_type -> album.v1.Album
+	5,  // 13: album.v1.AlbumService.ListAlbums:output_type -> album.v1.ListAlbumsResponse
+	1,  // 14: album.v1.AlbumService.UpdateAlbum:output_type -> album.v1.Album
+	10, // 15: album.v1.AlbumService.DeleteAlbum:output_type -> google.protobuf.Empty
+	9,  // 16: album.v1.AlbumService.WatchAlbums:output_type -> album.v1.AlbumEvent
+	11, // [11:17] is the sub-list for method output_type
+	5,  // [5:11] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_album_v1_album_proto_init() }
+func file_album_v1_album_proto_init() {
+	if File_album_v1_album_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_album_v1_album_proto_rawDesc), len(file_album_v1_album_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_album_v1_album_proto_goTypes,
+		DependencyIndexes: file_album_v1_album_proto_depIdxs,
+		EnumInfos:         file_album_v1_album_proto_enumTypes,
+		MessageInfos:      file_album_v1_album_proto_msgTypes,
+	}.Build()
+	File_album_v1_album_proto = out.File
+	file_album_v1_album_proto_goTypes = nil
+	file_album_v1_album_proto_depIdxs = nil
+}
@@ -7,6 +7,10 @@ import (
 	"syscall"
 
 	"go-masters/10-cloud_ready/cloudapp/internal/config"
+	"go-masters/10-cloud_ready/cloudapp/internal/db/postgres"
+	"go-masters/10-cloud_ready/cloudapp/internal/events"
+	"go-masters/10-cloud_ready/cloudapp/internal/grpcserver"
+	"go-masters/10-cloud_ready/cloudapp/internal/outbox"
 	"go-masters/10-cloud_ready/cloudapp/internal/server"
 
 	"github.com/rs/zerolog/log"
@@ -23,11 +27,40 @@ func main() {
 		log.Fatal().Err(err).Msg("Ошибка при загрузке конфигурации")
 	}
 
+	// Инициализируем БД, общую для HTTP и gRPC
+	store, err := postgres.New(cfg.DBConnStr)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Ошибка инициализации БД")
+	}
+
+	// События изменений доставляются из outbox во внутреннюю шину:
+	// напрямую или через LISTEN/NOTIFY, если экземпляров несколько
+	bus := events.NewBus()
+	sinks, err := outbox.SinksFromConfig(cfg.Outbox)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Ошибка настройки outbox")
+	}
+	if cfg.EventsNotify {
+		sinks = append(sinks, outbox.SinkFunc{SinkName: "notify", Fn: store.Notify})
+		go store.Listen(ctx, bus)
+	} else {
+		sinks = append(sinks, outbox.NewBusSink(bus))
+	}
+	relay := outbox.NewRelay(store, sinks...)
+	if cfg.Outbox.PollInterval > 0 {
+		relay.PollInterval = cfg.Outbox.PollInterval
+	}
+	if cfg.Outbox.MaxAttempts > 0 {
+		relay.MaxAttempts = cfg.Outbox.MaxAttempts
+	}
+	go relay.Run(ctx)
+
 	// Инициализируем сервер
-	srv, err := server.New(cfg)
+	srv, err := server.New(cfg, store, bus)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Ошибка при инициализации сервера")
 	}
+	grpcSrv := grpcserver.New(cfg, store, bus)
 
 	// Запускаем сервер в отдельной горутине
 	go func() {
@@ -36,6 +69,12 @@ func main() {
 			cancel()
 		}
 	}()
+	go func() {
+		if err := grpcSrv.Start(ctx); err != nil {
+			log.Error().Err(err).Msg("Ошибка при запуске gRPC сервера")
+			cancel()
+		}
+	}()
 
 	// Ожидаем сигналы ОС для graceful shutdown
 	quit := make(chan os.Signal, 1)
@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// Решения, фиксируемые в журнале аудита.
+const (
+	DecisionAllowed = "allowed"
+	DecisionDenied  = "denied"
+)
+
+// Entry - запись журнала аудита.
+type Entry struct {
+	RequestID  string
+	UserID     string
+	Role       string
+	Method     string
+	Path       string
+	Permission string
+	Decision   string
+	Status     int
+}
+
+// Logger - журнал аудита поверх zerolog.
+type Logger struct {
+	logger zerolog.Logger
+}
+
+func New(logger zerolog.Logger) *Logger {
+	return &Logger{logger: logger.With().Str("component", "audit").Logger()}
+}
+
+// Log записывает событие в журнал аудита.
+func (l *Logger) Log(_ context.Context, e Entry) {
+	l.logger.Info().
+		Str("request_id", e.RequestID).
+		Str("user_id", e.UserID).
+		Str("role", e.Role).
+		Str("method", e.Method).
+		Str("path", e.Path).
+		Str("permission", e.Permission).
+		Str("decision", e.Decision).
+		Int("status", e.Status).
+		Msg("Аудит запроса")
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+)
+
+// Заголовки, из которых извлекается информация о вызывающей стороне.
+// Предполагается, что они выставляются доверенным API-шлюзом.
+const (
+	HeaderUserID = "X-User-Id"
+	HeaderRole   = "X-User-Role"
+)
+
+// Role - роль пользователя.
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// Valid сообщает, является ли роль известной.
+func (r Role) Valid() bool {
+	switch r {
+	case RoleViewer, RoleEditor, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// Caller - вызывающая сторона запроса.
+type Caller struct {
+	ID   string
+	Role Role
+}
+
+// Anonymous - вызывающая сторона, не передавшая идентификатор.
+var Anonymous = Caller{ID: "anonymous", Role: RoleViewer}
+
+type ctxKey struct{}
+
+// WithCaller возвращает контекст, содержащий вызывающую сторону.
+func WithCaller(ctx context.Context, c Caller) context.Context {
+	return context.WithValue(ctx, ctxKey{}, c)
+}
+
+// FromContext возвращает вызывающую сторону из контекста.
+// Если она не была установлена, возвращается Anonymous.
+func FromContext(ctx context.Context) Caller {
+	c, ok := ctx.Value(ctxKey{}).(Caller)
+	if !ok {
+		return Anonymous
+	}
+	return c
+}
+
+// IdentifyMiddleware - middleware для определения вызывающей стороны.
+// Неизвестная или отсутствующая роль понижается до viewer.
+func IdentifyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := Anonymous
+		if id := r.Header.Get(HeaderUserID); id != "" {
+			c.ID = id
+			if role := Role(r.Header.Get(HeaderRole)); role.Valid() {
+				c.Role = role
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithCaller(r.Context(), c)))
+	})
+}
@@ -0,0 +1,31 @@
+package auth
+
+// Permission - разрешение на выполнение операции.
+type Permission string
+
+const (
+	PermAlbumsRead   Permission = "albums:read"
+	PermAlbumsCreate Permission = "albums:create"
+	PermAlbumsUpdate Permission = "albums:update"
+	PermAlbumsDelete Permission = "albums:delete"
+)
+
+// Policy - соответствие ролей и разрешённых им операций.
+type Policy map[Role][]Permission
+
+// DefaultPolicy - политика доступа по умолчанию.
+var DefaultPolicy = Policy{
+	RoleViewer: {PermAlbumsRead},
+	RoleEditor: {PermAlbumsRead, PermAlbumsCreate, PermAlbumsUpdate},
+	RoleAdmin:  {PermAlbumsRead, PermAlbumsCreate, PermAlbumsUpdate, PermAlbumsDelete},
+}
+
+// Allowed сообщает, разрешена ли операция для роли.
+func (p Policy) Allowed(role Role, perm Permission) bool {
+	for _, granted := range p[role] {
+		if granted == perm {
+			return true
+		}
+	}
+	return false
+}
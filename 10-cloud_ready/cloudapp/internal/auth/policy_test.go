@@ -0,0 +1,26 @@
+package auth
+
+import "testing"
+
+func TestPolicy_Allowed(t *testing.T) {
+	tests := []struct {
+		name string
+		role Role
+		perm Permission
+		want bool
+	}{
+		{name: "viewer reads", role: RoleViewer, perm: PermAlbumsRead, want: true},
+		{name: "viewer creates", role: RoleViewer, perm: PermAlbumsCreate, want: false},
+		{name: "editor updates", role: RoleEditor, perm: PermAlbumsUpdate, want: true},
+		{name: "editor deletes", role: RoleEditor, perm: PermAlbumsDelete, want: false},
+		{name: "admin deletes", role: RoleAdmin, perm: PermAlbumsDelete, want: true},
+		{name: "unknown role", role: Role("guest"), perm: PermAlbumsRead, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultPolicy.Allowed(tt.role, tt.perm); got != tt.want {
+				t.Errorf("Allowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
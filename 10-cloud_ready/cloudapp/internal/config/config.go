@@ -3,13 +3,31 @@ package config
 import (
 	"log"
 	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Cfg struct {
 	Port      string `mapstructure:"port"`
+	GRPCPort  string `mapstructure:"grpc_port"`
 	DBConnStr string `mapstructure:"db_conn_str"`
+	// Рассылать события изменений через LISTEN/NOTIFY
+	// (для запуска нескольких экземпляров сервиса).
+	EventsNotify bool `mapstructure:"events_notify"`
+	// Доставка событий из transactional outbox.
+	Outbox Outbox `mapstructure:"outbox"`
+}
+
+// Outbox - настройки доставки событий из outbox.
+type Outbox struct {
+	// Дополнительные приемники событий: log, file, webhook.
+	Sinks        []string      `mapstructure:"sinks"`
+	FilePath     string        `mapstructure:"file_path"`
+	WebhookURL   string        `mapstructure:"webhook_url"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// Число попыток доставки события до отметки о недоставке.
+	MaxAttempts int `mapstructure:"max_attempts"`
 }
 
 var (
@@ -41,9 +59,13 @@ func Load(path string) (*Cfg, error) {
 
 		// Устанавливаем альтернативные имена для переменных окружения
 		viper.BindEnv("port", "MYAPP_PORT")
+		viper.BindEnv("grpc_port", "MYAPP_GRPC_PORT")
 
 		// Устанавливаем значения по умолчанию
 		viper.SetDefault("port", 10000)
+		viper.SetDefault("grpc_port", 9090)
+		viper.SetDefault("outbox.poll_interval", time.Second)
+		viper.SetDefault("outbox.max_attempts", 20)
 
 		instance = &Cfg{}
 		if err = viper.Unmarshal(instance); err != nil {
@@ -2,10 +2,21 @@ package db
 
 import (
 	"context"
+	"errors"
 	"go-masters/10-cloud_ready/cloudapp/internal/models"
 )
 
+// ErrNotFound - запрошенная запись не найдена.
+var ErrNotFound = errors.New("not found")
+
 type DB interface {
-	AddAlbum(context.Context, models.Album) error
+	AddAlbum(context.Context, models.Album) (models.Album, error)
 	ListAlbums(context.Context) ([]models.Album, error)
+	// ListAlbumsAfter возвращает до limit альбомов с идентификатором
+	// больше afterID в порядке идентификаторов. Постраничный вывод по ключу
+	// не пропускает и не повторяет альбомы при изменениях между запросами.
+	ListAlbumsAfter(ctx context.Context, afterID, limit int) ([]models.Album, error)
+	GetAlbum(ctx context.Context, id string) (models.Album, error)
+	UpdateAlbum(context.Context, models.Album) error
+	DeleteAlbum(ctx context.Context, id string) error
 }
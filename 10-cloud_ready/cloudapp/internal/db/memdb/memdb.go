@@ -1,24 +1,116 @@
 package memdb
 
 import (
+	"cmp"
 	"context"
+	"slices"
+	"strconv"
+	"sync"
 
+	"go-masters/10-cloud_ready/cloudapp/internal/db"
 	"go-masters/10-cloud_ready/cloudapp/internal/models"
 )
 
 type MemDB struct {
-	data []models.Album
+	mu     sync.RWMutex
+	data   []models.Album
+	nextID int
 }
 
 func New() *MemDB {
 	return &MemDB{}
 }
 
-func (m *MemDB) AddAlbum(_ context.Context, album models.Album) error {
+func (m *MemDB) AddAlbum(_ context.Context, album models.Album) (models.Album, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if album.ID == "" {
+		m.nextID++
+		album.ID = strconv.Itoa(m.nextID)
+	}
 	m.data = append(m.data, album)
-	return nil
+	return album, nil
 }
 
 func (m *MemDB) ListAlbums(_ context.Context) ([]models.Album, error) {
-	return m.data, nil
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return slices.Clone(m.data), nil
+}
+
+// ListAlbumsAfter сравнивает идентификаторы как числа. Альбомы
+// с нечисловыми идентификаторами, заданными при добавлении явно,
+// в постраничный вывод не попадают.
+func (m *MemDB) ListAlbumsAfter(_ context.Context, afterID, limit int) ([]models.Album, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	type keyed struct {
+		id    int
+		album models.Album
+	}
+	var page []keyed
+	for _, a := range m.data {
+		id, err := strconv.Atoi(a.ID)
+		if err != nil || id <= afterID {
+			continue
+		}
+		page = append(page, keyed{id, a})
+	}
+	slices.SortFunc(page, func(a, b keyed) int {
+		return cmp.Compare(a.id, b.id)
+	})
+
+	res := make([]models.Album, 0, min(limit, len(page)))
+	for _, k := range page[:min(limit, len(page))] {
+		res = append(res, k.album)
+	}
+	return res, nil
+}
+
+func (m *MemDB) GetAlbum(_ context.Context, id string) (models.Album, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := m.index(id)
+	if i < 0 {
+		return models.Album{}, db.ErrNotFound
+	}
+	return m.data[i], nil
+}
+
+func (m *MemDB) UpdateAlbum(_ context.Context, album models.Album) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.index(album.ID)
+	if i < 0 {
+		return db.ErrNotFound
+	}
+	m.data[i] = album
+	return nil
+}
+
+func (m *MemDB) DeleteAlbum(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.index(id)
+	if i < 0 {
+		return db.ErrNotFound
+	}
+	m.data = append(m.data[:i], m.data[i+1:]...)
+	return nil
+}
+
+// index возвращает позицию альбома в срезе или -1.
+func (m *MemDB) index(id string) int {
+	for i, a := range m.data {
+		if a.ID == id {
+			return i
+		}
+	}
+	return -1
 }
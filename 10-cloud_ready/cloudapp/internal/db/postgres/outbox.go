@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"go-masters/10-cloud_ready/cloudapp/internal/events"
+	"go-masters/10-cloud_ready/cloudapp/internal/models"
+	"go-masters/10-cloud_ready/cloudapp/internal/outbox"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// notifyChannel - канал LISTEN/NOTIFY для событий изменения альбомов.
+const notifyChannel = "album_events"
+
+// writeOutbox записывает событие в outbox в рамках транзакции изменения.
+func writeOutbox(ctx context.Context, tx pgx.Tx, t events.Type, album models.Album) error {
+	payload, err := json.Marshal(album)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		ctx,
+		"INSERT INTO album_outbox (event_type, payload) VALUES ($1, $2)",
+		string(t),
+		payload)
+	return err
+}
+
+// outboxLockKey - ключ advisory-блокировки, под которой relay назначает
+// номера событий.
+const outboxLockKey = 0x616c62756d73 // "albums"
+
+// Claim реализует outbox.Store. Записи арендуются сдвигом next_attempt_at,
+// а SKIP LOCKED позволяет нескольким экземплярам relay работать параллельно.
+//
+// Номера событий назначаются здесь, в порядке появления записей
+// в outbox (фиксации транзакций изменения), поэтому событие не получает
+// номер меньше уже доставленного. Порядок доставки не гарантируется
+// между экземплярами relay, выбирающими записи одновременно, и для
+// повторных попыток после ошибки приемника.
+func (pg *Postgres) Claim(ctx context.Context, limit int, lease time.Duration) ([]outbox.Record, error) {
+	var records []outbox.Record
+	err := pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		// Блокировка упорядочивает назначение номеров между экземплярами.
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", outboxLockKey); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `
+			UPDATE album_outbox o SET event_id = n.event_id
+			FROM (
+				SELECT id, nextval('album_events_id_seq') AS event_id
+				FROM (
+					SELECT id FROM album_outbox
+					WHERE event_id IS NULL
+					ORDER BY id
+				) s
+			) n
+			WHERE o.id = n.id`)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, `
+			UPDATE album_outbox
+			SET attempts = attempts + 1,
+				next_attempt_at = now() + $2::interval
+			WHERE id IN (
+				SELECT id FROM album_outbox
+				WHERE delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= now()
+				ORDER BY event_id
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING event_id, event_type, payload, attempts, delivered_sinks`,
+			limit,
+			lease,
+		)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				rec     outbox.Record
+				typ     string
+				payload []byte
+			)
+			if err := rows.Scan(&rec.Event.ID, &typ, &payload, &rec.Attempt, &rec.Delivered); err != nil {
+				return err
+			}
+			rec.Event.Type = events.Type(typ)
+			if err := json.Unmarshal(payload, &rec.Event.Album); err != nil {
+				return err
+			}
+			records = append(records, rec)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING не гарантирует порядок строк.
+	slices.SortFunc(records, func(a, b outbox.Record) int {
+		return int(a.Event.ID - b.Event.ID)
+	})
+	return records, nil
+}
+
+// Complete реализует outbox.Store.
+func (pg *Postgres) Complete(ctx context.Context, id int64) error {
+	_, err := pg.pool.Exec(
+		ctx,
+		"UPDATE album_outbox SET delivered_at = now(), last_error = NULL WHERE event_id = $1",
+		id)
+	return err
+}
+
+// Retry реализует outbox.Store.
+func (pg *Postgres) Retry(ctx context.Context, id int64, delivered []string, next time.Time, reason string) error {
+	_, err := pg.pool.Exec(
+		ctx,
+		"UPDATE album_outbox SET delivered_sinks = $2, next_attempt_at = $3, last_error = $4 WHERE event_id = $1",
+		id,
+		delivered,
+		next,
+		reason)
+	return err
+}
+
+// Fail реализует outbox.Store.
+func (pg *Postgres) Fail(ctx context.Context, id int64, delivered []string, reason string) error {
+	_, err := pg.pool.Exec(
+		ctx,
+		"UPDATE album_outbox SET delivered_sinks = $2, failed_at = now(), last_error = $3 WHERE event_id = $1",
+		id,
+		delivered,
+		reason)
+	return err
+}
+
+// Notify рассылает событие всем экземплярам сервиса через NOTIFY.
+// Используется как приемник outbox в режиме нескольких экземпляров.
+func (pg *Postgres) Notify(ctx context.Context, e events.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = pg.pool.Exec(ctx, "SELECT pg_notify($1, $2)", notifyChannel, string(payload))
+	return err
+}
+
+// Listen получает события через LISTEN и публикует их в локальную шину.
+// Блокируется до отмены контекста, при потере соединения переподключается.
+func (pg *Postgres) Listen(ctx context.Context, bus *events.Bus) {
+	for ctx.Err() == nil {
+		err := pg.listen(ctx, bus)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Error().Err(err).Msg("Ошибка LISTEN, повторное подключение")
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (pg *Postgres) listen(ctx context.Context, bus *events.Bus) error {
+	conn, err := pg.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+		return err
+	}
+
+	for {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var e events.Event
+		if err := json.Unmarshal([]byte(n.Payload), &e); err != nil {
+			log.Error().Err(err).Msg("Некорректное событие в NOTIFY")
+			continue
+		}
+		bus.Publish(e)
+	}
+}
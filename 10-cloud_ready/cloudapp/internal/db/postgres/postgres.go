@@ -2,10 +2,15 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"go-masters/10-cloud_ready/cloudapp/internal/db"
+	"go-masters/10-cloud_ready/cloudapp/internal/events"
 	"go-masters/10-cloud_ready/cloudapp/internal/models"
 	"os"
 	"path/filepath"
+	"strconv"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
@@ -15,6 +20,9 @@ type Postgres struct {
 	pool *pgxpool.Pool
 }
 
+// New подключается к БД и применяет миграции.
+// Изменения альбомов записываются вместе с событием в outbox
+// в одной транзакции, доставку событий выполняет outbox.Relay.
 func New(connstr string) (*Postgres, error) {
 	pool, err := pgxpool.New(context.Background(), connstr)
 	if err != nil {
@@ -51,22 +59,46 @@ func (pg *Postgres) applyMigrations() error {
 	return db.Close()
 }
 
-func (pg *Postgres) AddAlbum(ctx context.Context, album models.Album) error {
-	_, err := pg.pool.Exec(
-		ctx,
-		"INSERT INTO albums (id  artist, title, year) VALUES ($1, $2, $3, $4)",
-		album.ID,
-		album.Artist,
-		album.Title,
-		album.Year)
-	return err
+func (pg *Postgres) AddAlbum(ctx context.Context, album models.Album) (models.Album, error) {
+	err := pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(
+			ctx,
+			"INSERT INTO albums (artist, title, year) VALUES ($1, $2, $3) RETURNING id",
+			album.Artist,
+			album.Title,
+			album.Year,
+		).Scan(&album.ID)
+		if err != nil {
+			return err
+		}
+
+		return writeOutbox(ctx, tx, events.Created, album)
+	})
+	return album, err
 }
 
 func (pg *Postgres) ListAlbums(ctx context.Context) ([]models.Album, error) {
-	rows, err := pg.pool.Query(ctx, "SELECT id, artist, title, year FROM albums")
+	rows, err := pg.pool.Query(ctx, "SELECT id, artist, title, year FROM albums ORDER BY id")
 	if err != nil {
 		return nil, err
 	}
+	return scanAlbums(rows)
+}
+
+func (pg *Postgres) ListAlbumsAfter(ctx context.Context, afterID, limit int) ([]models.Album, error) {
+	rows, err := pg.pool.Query(
+		ctx,
+		"SELECT id, artist, title, year FROM albums WHERE id > $1 ORDER BY id LIMIT $2",
+		afterID,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanAlbums(rows)
+}
+
+func scanAlbums(rows pgx.Rows) ([]models.Album, error) {
 	defer rows.Close()
 
 	var albums []models.Album
@@ -89,3 +121,72 @@ func (pg *Postgres) ListAlbums(ctx context.Context) ([]models.Album, error) {
 
 	return albums, nil
 }
+
+// validID сообщает, может ли id быть идентификатором альбома. Столбец id
+// числовой, и нечисловой идентификатор дал бы ошибку приведения вместо
+// отсутствующей записи.
+func validID(id string) bool {
+	_, err := strconv.Atoi(id)
+	return err == nil
+}
+
+func (pg *Postgres) GetAlbum(ctx context.Context, id string) (models.Album, error) {
+	if !validID(id) {
+		return models.Album{}, db.ErrNotFound
+	}
+	var album models.Album
+	err := pg.pool.QueryRow(
+		ctx,
+		"SELECT id, artist, title, year FROM albums WHERE id = $1",
+		id,
+	).Scan(
+		&album.ID,
+		&album.Artist,
+		&album.Title,
+		&album.Year,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Album{}, db.ErrNotFound
+	}
+	return album, err
+}
+
+func (pg *Postgres) UpdateAlbum(ctx context.Context, album models.Album) error {
+	if !validID(album.ID) {
+		return db.ErrNotFound
+	}
+	return pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(
+			ctx,
+			"UPDATE albums SET artist = $2, title = $3, year = $4 WHERE id = $1",
+			album.ID,
+			album.Artist,
+			album.Title,
+			album.Year)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return db.ErrNotFound
+		}
+
+		return writeOutbox(ctx, tx, events.Updated, album)
+	})
+}
+
+func (pg *Postgres) DeleteAlbum(ctx context.Context, id string) error {
+	if !validID(id) {
+		return db.ErrNotFound
+	}
+	return pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, "DELETE FROM albums WHERE id = $1", id)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return db.ErrNotFound
+		}
+
+		return writeOutbox(ctx, tx, events.Deleted, models.Album{ID: id})
+	})
+}
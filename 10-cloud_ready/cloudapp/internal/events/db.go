@@ -0,0 +1,45 @@
+package events
+
+import (
+	"context"
+
+	"go-masters/10-cloud_ready/cloudapp/internal/db"
+	"go-masters/10-cloud_ready/cloudapp/internal/models"
+)
+
+// PublishingDB - обертка над db.DB, публикующая событие в шину
+// после каждой успешной записи. Предназначена для хранилищ, которые
+// не публикуют события сами (например, memdb).
+type PublishingDB struct {
+	db.DB
+	bus *Bus
+}
+
+func NewPublishingDB(store db.DB, bus *Bus) *PublishingDB {
+	return &PublishingDB{DB: store, bus: bus}
+}
+
+func (p *PublishingDB) AddAlbum(ctx context.Context, album models.Album) (models.Album, error) {
+	album, err := p.DB.AddAlbum(ctx, album)
+	if err != nil {
+		return album, err
+	}
+	p.bus.Publish(Event{Type: Created, Album: album})
+	return album, nil
+}
+
+func (p *PublishingDB) UpdateAlbum(ctx context.Context, album models.Album) error {
+	if err := p.DB.UpdateAlbum(ctx, album); err != nil {
+		return err
+	}
+	p.bus.Publish(Event{Type: Updated, Album: album})
+	return nil
+}
+
+func (p *PublishingDB) DeleteAlbum(ctx context.Context, id string) error {
+	if err := p.DB.DeleteAlbum(ctx, id); err != nil {
+		return err
+	}
+	p.bus.Publish(Event{Type: Deleted, Album: models.Album{ID: id}})
+	return nil
+}
@@ -0,0 +1,125 @@
+package events
+
+import (
+	"sync"
+
+	"go-masters/10-cloud_ready/cloudapp/internal/models"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Type - тип изменения альбома.
+type Type string
+
+const (
+	Created Type = "created"
+	Updated Type = "updated"
+	Deleted Type = "deleted"
+)
+
+// Размер истории событий по умолчанию, доступной для возобновления подписки.
+const DefaultHistorySize = 1024
+
+// Event - событие изменения альбома.
+type Event struct {
+	ID    int64        `json:"id"`
+	Type  Type         `json:"type"`
+	Album models.Album `json:"album"`
+}
+
+// Bus - внутренняя шина событий с рассылкой всем подписчикам.
+// Шина хранит последние события, чтобы клиент мог возобновить
+// получение изменений с известного ему идентификатора.
+type Bus struct {
+	mu      sync.RWMutex
+	subs    map[int]chan Event
+	next    int
+	lastID  int64
+	history []Event
+	size    int
+}
+
+func NewBus() *Bus {
+	return NewBusWithHistory(DefaultHistorySize)
+}
+
+// NewBusWithHistory создает шину, хранящую size последних событий.
+func NewBusWithHistory(size int) *Bus {
+	return &Bus{
+		subs: make(map[int]chan Event),
+		size: size,
+	}
+}
+
+// Publish рассылает событие подписчикам. Если идентификатор события
+// не задан, шина присваивает его сама. Публикация не блокируется:
+// если буфер подписчика заполнен, подписка отменяется и ее канал
+// закрывается, чтобы клиент переподключился с последнего полученного
+// идентификатора, а не пропустил событие незаметно.
+func (b *Bus) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if e.ID == 0 {
+		e.ID = b.lastID + 1
+	}
+	b.lastID = max(b.lastID, e.ID)
+
+	b.history = append(b.history, e)
+	if len(b.history) > b.size {
+		b.history = b.history[len(b.history)-b.size:]
+	}
+
+	for id, ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			log.Warn().Int("subscriber", id).Msg("Буфер подписчика заполнен, подписка отменена")
+			delete(b.subs, id)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe создает подписку с буфером заданного размера.
+// Для отмены подписки необходимо вызвать возвращаемую функцию.
+// Канал закрывается при отмене подписки, в том числе шиной,
+// если подписчик не успевает читать события.
+func (b *Bus) Subscribe(buf int) (<-chan Event, func()) {
+	_, ch, cancel := b.SubscribeFrom(-1, buf)
+	return ch, cancel
+}
+
+// SubscribeFrom создает подписку и возвращает сохраненные в истории
+// события с идентификатором больше lastID. Отрицательный lastID
+// означает подписку только на новые события. События, вытесненные
+// из истории, восстановить нельзя.
+func (b *Bus) SubscribeFrom(lastID int64, buf int) ([]Event, <-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []Event
+	if lastID >= 0 {
+		for _, e := range b.history {
+			if e.ID > lastID {
+				missed = append(missed, e)
+			}
+		}
+	}
+
+	id := b.next
+	b.next++
+	ch := make(chan Event, buf)
+	b.subs[id] = ch
+
+	return missed, ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		// Подписка могла быть уже отменена шиной.
+		if _, ok := b.subs[id]; ok {
+			delete(b.subs, id)
+			close(ch)
+		}
+	}
+}
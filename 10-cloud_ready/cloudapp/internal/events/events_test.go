@@ -0,0 +1,62 @@
+package events
+
+import (
+	"testing"
+
+	"go-masters/10-cloud_ready/cloudapp/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_SubscribeFrom(t *testing.T) {
+	bus := NewBusWithHistory(2)
+
+	bus.Publish(Event{Type: Created, Album: models.Album{ID: "1"}})
+	bus.Publish(Event{Type: Created, Album: models.Album{ID: "2"}})
+	bus.Publish(Event{Type: Updated, Album: models.Album{ID: "2"}})
+
+	missed, ch, cancel := bus.SubscribeFrom(1, 1)
+	defer cancel()
+
+	// Событие с ID 1 вытеснено из истории, но оно и не запрашивалось.
+	assert.Equal(t, []int64{2, 3}, ids(missed))
+
+	bus.Publish(Event{ID: 10, Type: Deleted, Album: models.Album{ID: "1"}})
+	e := <-ch
+	assert.Equal(t, int64(10), e.ID)
+
+	// Следующий идентификатор продолжает максимальный из опубликованных.
+	bus.Publish(Event{Type: Created})
+	assert.Equal(t, int64(11), (<-ch).ID)
+
+	missed, _, cancelNew := bus.SubscribeFrom(-1, 1)
+	defer cancelNew()
+	assert.Empty(t, missed)
+}
+
+func TestBus_SlowSubscriber(t *testing.T) {
+	bus := NewBus()
+	ch, cancel := bus.Subscribe(1)
+
+	bus.Publish(Event{Type: Created})
+	// Буфер заполнен: подписка отменяется, а не теряет событие незаметно.
+	bus.Publish(Event{Type: Created})
+
+	e, ok := <-ch
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), e.ID)
+	_, ok = <-ch
+	assert.False(t, ok)
+
+	// Повторная отмена безопасна.
+	cancel()
+	cancel()
+}
+
+func ids(events []Event) []int64 {
+	var res []int64
+	for _, e := range events {
+		res = append(res, e.ID)
+	}
+	return res
+}
@@ -0,0 +1,181 @@
+package grpcserver
+
+import (
+	"context"
+	"strconv"
+
+	albumv1 "go-masters/10-cloud_ready/cloudapp/api/album/v1"
+	"go-masters/10-cloud_ready/cloudapp/internal/db"
+	"go-masters/10-cloud_ready/cloudapp/internal/events"
+	"go-masters/10-cloud_ready/cloudapp/internal/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+	// Размер буфера подписки на изменения.
+	watchBuffer = 64
+)
+
+// albumService - реализация albumv1.AlbumServiceServer поверх db.DB.
+type albumService struct {
+	albumv1.UnimplementedAlbumServiceServer
+
+	db  db.DB
+	bus *events.Bus
+}
+
+func (s *albumService) CreateAlbum(ctx context.Context, req *albumv1.CreateAlbumRequest) (*albumv1.Album, error) {
+	if err := validateAlbum(req.GetAlbum()); err != nil {
+		return nil, err
+	}
+
+	album, err := s.db.AddAlbum(ctx, fromProto(req.GetAlbum()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(album), nil
+}
+
+func (s *albumService) GetAlbum(ctx context.Context, req *albumv1.GetAlbumRequest) (*albumv1.Album, error) {
+	album, err := s.db.GetAlbum(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(album), nil
+}
+
+func (s *albumService) ListAlbums(ctx context.Context, req *albumv1.ListAlbumsRequest) (*albumv1.ListAlbumsResponse, error) {
+	size := int(req.GetPageSize())
+	switch {
+	case size < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size не может быть отрицательным")
+	case size == 0:
+		size = defaultPageSize
+	case size > maxPageSize:
+		size = maxPageSize
+	}
+
+	// Токен страницы - идентификатор последнего альбома предыдущей страницы.
+	after := 0
+	if req.GetPageToken() != "" {
+		var err error
+		after, err = strconv.Atoi(req.GetPageToken())
+		if err != nil || after < 0 {
+			return nil, status.Error(codes.InvalidArgument, "некорректный page_token")
+		}
+	}
+
+	// Лишний альбом показывает, что есть следующая страница.
+	albums, err := s.db.ListAlbumsAfter(ctx, after, size+1)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &albumv1.ListAlbumsResponse{}
+	for _, a := range albums[:min(size, len(albums))] {
+		resp.Albums = append(resp.Albums, toProto(a))
+	}
+	if len(albums) > size {
+		resp.NextPageToken = albums[size-1].ID
+	}
+	return resp, nil
+}
+
+func (s *albumService) UpdateAlbum(ctx context.Context, req *albumv1.UpdateAlbumRequest) (*albumv1.Album, error) {
+	if req.GetAlbum().GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "не указан идентификатор альбома")
+	}
+	if err := validateAlbum(req.GetAlbum()); err != nil {
+		return nil, err
+	}
+
+	album := fromProto(req.GetAlbum())
+	if err := s.db.UpdateAlbum(ctx, album); err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(album), nil
+}
+
+func (s *albumService) DeleteAlbum(ctx context.Context, req *albumv1.DeleteAlbumRequest) (*emptypb.Empty, error) {
+	if err := s.db.DeleteAlbum(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *albumService) WatchAlbums(_ *albumv1.WatchAlbumsRequest, stream grpc.ServerStreamingServer[albumv1.AlbumEvent]) error {
+	ch, unsubscribe := s.bus.Subscribe(watchBuffer)
+	defer unsubscribe()
+
+	// Заголовки отправляются после оформления подписки, чтобы клиент
+	// мог дождаться момента, с которого события не будут пропущены.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e, ok := <-ch:
+			// Клиент не успевал читать события, подписка отменена.
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "клиент не успевает получать события")
+			}
+			err := stream.Send(&albumv1.AlbumEvent{
+				Type:  eventType(e.Type),
+				Album: toProto(e.Album),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func validateAlbum(a *albumv1.Album) error {
+	if a.GetArtist() == "" || a.GetTitle() == "" {
+		return status.Error(codes.InvalidArgument, "artist и title обязательны")
+	}
+	if a.GetYear() < 0 {
+		return status.Error(codes.InvalidArgument, "year не может быть отрицательным")
+	}
+	return nil
+}
+
+func toProto(a models.Album) *albumv1.Album {
+	return &albumv1.Album{
+		Id:     a.ID,
+		Artist: a.Artist,
+		Title:  a.Title,
+		Year:   int32(a.Year),
+	}
+}
+
+func fromProto(a *albumv1.Album) models.Album {
+	return models.Album{
+		ID:     a.GetId(),
+		Artist: a.GetArtist(),
+		Title:  a.GetTitle(),
+		Year:   int(a.GetYear()),
+	}
+}
+
+func eventType(t events.Type) albumv1.AlbumEvent_Type {
+	switch t {
+	case events.Created:
+		return albumv1.AlbumEvent_TYPE_CREATED
+	case events.Updated:
+		return albumv1.AlbumEvent_TYPE_UPDATED
+	case events.Deleted:
+		return albumv1.AlbumEvent_TYPE_DELETED
+	}
+	return albumv1.AlbumEvent_TYPE_UNSPECIFIED
+}
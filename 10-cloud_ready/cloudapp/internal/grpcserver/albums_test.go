@@ -0,0 +1,149 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	albumv1 "go-masters/10-cloud_ready/cloudapp/api/album/v1"
+	"go-masters/10-cloud_ready/cloudapp/internal/auth"
+	"go-masters/10-cloud_ready/cloudapp/internal/config"
+	"go-masters/10-cloud_ready/cloudapp/internal/db/memdb"
+	"go-masters/10-cloud_ready/cloudapp/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func dial(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	bus := events.NewBus()
+	srv := New(&config.Cfg{}, events.NewPublishingDB(memdb.New(), bus), bus)
+
+	lis := bufconn.Listen(1 << 20)
+	go srv.server.Serve(lis)
+	t.Cleanup(srv.server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func as(role auth.Role) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(),
+		auth.HeaderUserID, "user-1",
+		auth.HeaderRole, string(role),
+	)
+}
+
+func TestAlbumService(t *testing.T) {
+	client := albumv1.NewAlbumServiceClient(dial(t))
+
+	_, err := client.CreateAlbum(as(auth.RoleViewer), &albumv1.CreateAlbumRequest{
+		Album: &albumv1.Album{Artist: "A", Title: "T"},
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.CreateAlbum(as(auth.RoleEditor), &albumv1.CreateAlbumRequest{
+		Album: &albumv1.Album{Artist: "A"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	for _, title := range []string{"One", "Two", "Three"} {
+		_, err := client.CreateAlbum(as(auth.RoleEditor), &albumv1.CreateAlbumRequest{
+			Album: &albumv1.Album{Artist: "A", Title: title, Year: 2000},
+		})
+		require.NoError(t, err)
+	}
+
+	page, err := client.ListAlbums(as(auth.RoleViewer), &albumv1.ListAlbumsRequest{PageSize: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Albums, 2)
+	assert.NotEmpty(t, page.NextPageToken)
+
+	page, err = client.ListAlbums(as(auth.RoleViewer), &albumv1.ListAlbumsRequest{
+		PageSize:  2,
+		PageToken: page.NextPageToken,
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Albums, 1)
+	assert.Equal(t, "Three", page.Albums[0].Title)
+	assert.Empty(t, page.NextPageToken)
+
+	_, err = client.DeleteAlbum(as(auth.RoleAdmin), &albumv1.DeleteAlbumRequest{Id: "1"})
+	require.NoError(t, err)
+
+	_, err = client.GetAlbum(as(auth.RoleViewer), &albumv1.GetAlbumRequest{Id: "1"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAlbumService_ListPages(t *testing.T) {
+	client := albumv1.NewAlbumServiceClient(dial(t))
+
+	for _, title := range []string{"One", "Two", "Three", "Four"} {
+		_, err := client.CreateAlbum(as(auth.RoleEditor), &albumv1.CreateAlbumRequest{
+			Album: &albumv1.Album{Artist: "A", Title: title},
+		})
+		require.NoError(t, err)
+	}
+
+	page, err := client.ListAlbums(as(auth.RoleViewer), &albumv1.ListAlbumsRequest{PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Albums, 2)
+
+	// Удаление альбома с уже выданной страницы не сдвигает следующую.
+	_, err = client.DeleteAlbum(as(auth.RoleAdmin), &albumv1.DeleteAlbumRequest{Id: page.Albums[0].Id})
+	require.NoError(t, err)
+
+	page, err = client.ListAlbums(as(auth.RoleViewer), &albumv1.ListAlbumsRequest{
+		PageSize:  2,
+		PageToken: page.NextPageToken,
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Albums, 2)
+	assert.Equal(t, "Three", page.Albums[0].Title)
+	assert.Equal(t, "Four", page.Albums[1].Title)
+	assert.Empty(t, page.NextPageToken)
+
+	_, err = client.ListAlbums(as(auth.RoleViewer), &albumv1.ListAlbumsRequest{PageToken: "x"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAlbumService_Watch(t *testing.T) {
+	client := albumv1.NewAlbumServiceClient(dial(t))
+
+	ctx, cancel := context.WithCancel(as(auth.RoleViewer))
+	defer cancel()
+
+	stream, err := client.WatchAlbums(ctx, &albumv1.WatchAlbumsRequest{})
+	require.NoError(t, err)
+
+	// Заголовки приходят после оформления подписки на сервере.
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	_, err = client.CreateAlbum(as(auth.RoleEditor), &albumv1.CreateAlbumRequest{
+		Album: &albumv1.Album{Artist: "A", Title: "T"},
+	})
+	require.NoError(t, err)
+
+	e, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, albumv1.AlbumEvent_TYPE_CREATED, e.Type)
+	assert.Equal(t, "T", e.Album.Title)
+}
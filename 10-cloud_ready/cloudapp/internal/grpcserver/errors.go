@@ -0,0 +1,21 @@
+package grpcserver
+
+import (
+	"errors"
+
+	"go-masters/10-cloud_ready/cloudapp/internal/db"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus преобразует ошибку слоя БД в статус gRPC
+// по тем же правилам, что и HTTP обработчики.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return status.Error(codes.NotFound, "альбом не найден")
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,130 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	albumv1 "go-masters/10-cloud_ready/cloudapp/api/album/v1"
+	"go-masters/10-cloud_ready/cloudapp/internal/audit"
+	"go-masters/10-cloud_ready/cloudapp/internal/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// permissions - разрешения, необходимые для вызова методов.
+// Методы, отсутствующие в таблице (например, health), доступны всем.
+var permissions = map[string]auth.Permission{
+	albumv1.AlbumService_CreateAlbum_FullMethodName: auth.PermAlbumsCreate,
+	albumv1.AlbumService_GetAlbum_FullMethodName:    auth.PermAlbumsRead,
+	albumv1.AlbumService_ListAlbums_FullMethodName:  auth.PermAlbumsRead,
+	albumv1.AlbumService_UpdateAlbum_FullMethodName: auth.PermAlbumsUpdate,
+	albumv1.AlbumService_DeleteAlbum_FullMethodName: auth.PermAlbumsDelete,
+	albumv1.AlbumService_WatchAlbums_FullMethodName: auth.PermAlbumsRead,
+}
+
+// mutating - методы, изменяющие состояние.
+var mutating = map[string]bool{
+	albumv1.AlbumService_CreateAlbum_FullMethodName: true,
+	albumv1.AlbumService_UpdateAlbum_FullMethodName: true,
+	albumv1.AlbumService_DeleteAlbum_FullMethodName: true,
+}
+
+// guard - перехватчики для определения вызывающей стороны,
+// проверки прав и записи в журнал аудита.
+type guard struct {
+	policy auth.Policy
+	audit  *audit.Logger
+}
+
+func (g *guard) unary(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	ctx, entry, err := g.check(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := handler(ctx, req)
+	if mutating[info.FullMethod] {
+		entry.Decision = audit.DecisionAllowed
+		entry.Status = int(status.Code(err))
+		g.audit.Log(ctx, entry)
+	}
+	return resp, err
+}
+
+func (g *guard) stream(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, _, err := g.check(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &callerStream{ServerStream: ss, ctx: ctx})
+}
+
+// check определяет вызывающую сторону и проверяет права на вызов метода.
+func (g *guard) check(ctx context.Context, method string) (context.Context, audit.Entry, error) {
+	caller := callerFromMetadata(ctx)
+	ctx = auth.WithCaller(ctx, caller)
+
+	perm, ok := permissions[method]
+	entry := audit.Entry{
+		RequestID:  firstMetadata(ctx, "x-request-id"),
+		UserID:     caller.ID,
+		Role:       string(caller.Role),
+		Method:     "gRPC",
+		Path:       method,
+		Permission: string(perm),
+	}
+	if !ok || g.policy.Allowed(caller.Role, perm) {
+		return ctx, entry, nil
+	}
+
+	entry.Decision = audit.DecisionDenied
+	entry.Status = int(codes.PermissionDenied)
+	g.audit.Log(ctx, entry)
+
+	return ctx, entry, status.Error(codes.PermissionDenied, "недостаточно прав для выполнения операции")
+}
+
+// callerFromMetadata определяет вызывающую сторону по метаданным,
+// аналогично auth.IdentifyMiddleware для HTTP.
+func callerFromMetadata(ctx context.Context) auth.Caller {
+	c := auth.Anonymous
+	if id := firstMetadata(ctx, auth.HeaderUserID); id != "" {
+		c.ID = id
+		if role := auth.Role(firstMetadata(ctx, auth.HeaderRole)); role.Valid() {
+			c.Role = role
+		}
+	}
+	return c
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	vals := md.Get(strings.ToLower(key))
+	if len(vals) == 0 {
+		return ""
+	}
+	return vals[0]
+}
+
+// callerStream подменяет контекст потока контекстом с вызывающей стороной.
+type callerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *callerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,52 @@
+package grpcserver
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recoverUnary перехватывает панику обработчика и возвращает codes.Internal,
+// аналогично middleware.Recoverer для HTTP.
+func recoverUnary(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = panicStatus(info.FullMethod, p)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// recoverStream - recoverUnary для потоковых методов.
+func recoverStream(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = panicStatus(info.FullMethod, p)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+// panicStatus записывает панику в журнал и возвращает статус для клиента.
+func panicStatus(method string, p any) error {
+	log.Error().
+		Str("method", method).
+		Interface("panic", p).
+		Bytes("stack", debug.Stack()).
+		Msg("Паника в обработчике gRPC")
+	return status.Error(codes.Internal, "внутренняя ошибка сервера")
+}
@@ -0,0 +1,25 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecoverUnary(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/test/Panic"}
+	_, err := recoverUnary(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		panic("сбой")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	resp, err := recoverUnary(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
@@ -0,0 +1,77 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	albumv1 "go-masters/10-cloud_ready/cloudapp/api/album/v1"
+	"go-masters/10-cloud_ready/cloudapp/internal/audit"
+	"go-masters/10-cloud_ready/cloudapp/internal/auth"
+	"go-masters/10-cloud_ready/cloudapp/internal/config"
+	"go-masters/10-cloud_ready/cloudapp/internal/db"
+	"go-masters/10-cloud_ready/cloudapp/internal/events"
+	"go-masters/10-cloud_ready/cloudapp/internal/telemetry"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Server - gRPC сервер API альбомов.
+type Server struct {
+	addr   string
+	server *grpc.Server
+	health *health.Server
+}
+
+func New(cfg *config.Cfg, db db.DB, bus *events.Bus) *Server {
+	g := guard{
+		policy: auth.DefaultPolicy,
+		audit:  audit.New(log.Logger),
+	}
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			telemetry.UnaryTracingInterceptor,
+			recoverUnary,
+			g.unary,
+		),
+		grpc.ChainStreamInterceptor(
+			telemetry.StreamTracingInterceptor,
+			recoverStream,
+			g.stream,
+		),
+	)
+
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
+	albumv1.RegisterAlbumServiceServer(srv, &albumService{db: db, bus: bus})
+
+	return &Server{
+		addr:   fmt.Sprintf(":%v", cfg.GRPCPort),
+		server: srv,
+		health: hs,
+	}
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		log.Info().Msg("Остановка gRPC сервера")
+		s.health.Shutdown()
+		s.server.GracefulStop()
+	}()
+
+	log.Info().Str("addr", s.addr).Msg("Запуск gRPC сервера")
+	s.health.SetServingStatus(albumv1.AlbumService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	return s.server.Serve(lis)
+}
@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// MaxRequestBody - наибольший размер тела запроса. Тела запросов
+// спецификации - небольшие JSON объекты, поэтому запас достаточно велик.
+const MaxRequestBody = 64 << 10
+
+// ErrorWriter - функция записи ошибки в ответ.
+type ErrorWriter func(w http.ResponseWriter, status int, msg string)
+
+// ResponseErrorHandler вызывается, если ответ не соответствует спецификации.
+type ResponseErrorHandler func(r *http.Request, status int, err error)
+
+// ValidationMiddleware - middleware для проверки запросов и ответов
+// по спецификации. Некорректные запросы отклоняются с кодом 400,
+// тела больше MaxRequestBody - с кодом 413.
+// Несоответствие ответа спецификации не влияет на клиента и
+// передаётся в onResponseErr.
+func ValidationMiddleware(
+	spec *Spec,
+	writeError ErrorWriter,
+	onResponseErr ResponseErrorHandler,
+) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			op, _, ok := spec.Find(r.Method, r.URL.Path)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if op.RequestBody != nil {
+				body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxRequestBody))
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					writeError(w, http.StatusRequestEntityTooLarge, "слишком большое тело запроса")
+					return
+				}
+				if err != nil {
+					writeError(w, http.StatusBadRequest, err.Error())
+					return
+				}
+				if err := spec.ValidateRequest(op, r.Header.Get("Content-Type"), body); err != nil {
+					writeError(w, http.StatusBadRequest, err.Error())
+					return
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			var buf bytes.Buffer
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&buf)
+
+			next.ServeHTTP(ww, r)
+
+			err := spec.ValidateResponse(op, ww.Status(), ww.Header().Get("Content-Type"), buf.Bytes())
+			if err != nil {
+				onResponseErr(r, ww.Status(), err)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema - подмножество JSON Schema, используемое в спецификации.
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 Types              `json:"type"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Enum                 []any              `json:"enum"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinLength            *int               `json:"minLength"`
+}
+
+// Types - допустимые типы значения. В OpenAPI 3.1 поле type
+// может быть как строкой, так и массивом строк.
+type Types []string
+
+func (t *Types) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*t = Types{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+// validate проверяет значение v на соответствие схеме.
+func (s *Spec) validate(schema *Schema, v any, path string) error {
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		ref, ok := s.Components.Schemas[name]
+		if !ok {
+			return fmt.Errorf("%s: неизвестная ссылка %q", path, schema.Ref)
+		}
+		return s.validate(ref, v, path)
+	}
+
+	if len(schema.Type) > 0 && !slices.ContainsFunc(schema.Type, func(t string) bool { return isType(t, v) }) {
+		return fmt.Errorf("%s: ожидается тип %s", path, strings.Join(schema.Type, "|"))
+	}
+
+	if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, v) {
+		return fmt.Errorf("%s: значение %v не входит в перечисление", path, v)
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := val[name]; !ok {
+				return fmt.Errorf("%s: отсутствует обязательное поле %q", path, name)
+			}
+		}
+		for name, fv := range val {
+			ps, ok := schema.Properties[name]
+			if !ok {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					return fmt.Errorf("%s: неизвестное поле %q", path, name)
+				}
+				continue
+			}
+			if err := s.validate(ps, fv, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if schema.Items != nil {
+			for i, item := range val {
+				if err := s.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case float64:
+		if schema.Minimum != nil && val < *schema.Minimum {
+			return fmt.Errorf("%s: значение меньше %v", path, *schema.Minimum)
+		}
+		if schema.Maximum != nil && val > *schema.Maximum {
+			return fmt.Errorf("%s: значение больше %v", path, *schema.Maximum)
+		}
+	case string:
+		if schema.MinLength != nil && utf8.RuneCountInString(val) < *schema.MinLength {
+			return fmt.Errorf("%s: длина меньше %d", path, *schema.MinLength)
+		}
+	}
+
+	return nil
+}
+
+func isType(t string, v any) bool {
+	switch t {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	}
+	return false
+}
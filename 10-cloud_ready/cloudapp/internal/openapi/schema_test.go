@@ -0,0 +1,38 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_ValidateRequest(t *testing.T) {
+	spec, err := Load()
+	require.NoError(t, err)
+
+	op, tmpl, ok := spec.Find("POST", "/albums")
+	require.True(t, ok)
+	require.Equal(t, "/albums", tmpl)
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{name: "valid", body: `{"artist":"A","title":"T","year":2000}`},
+		{name: "empty body", body: ``, wantErr: true},
+		{name: "missing title", body: `{"artist":"A"}`, wantErr: true},
+		{name: "year is string", body: `{"artist":"A","title":"T","year":"2000"}`, wantErr: true},
+		{name: "fractional year", body: `{"artist":"A","title":"T","year":2000.5}`, wantErr: true},
+		{name: "unknown field", body: `{"artist":"A","title":"T","genre":"rock"}`, wantErr: true},
+		{name: "malformed json", body: `{"artist":`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := spec.ValidateRequest(op, "application/json", []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+//go:embed openapi.json
+var raw []byte
+
+// Spec - разобранная спецификация OpenAPI.
+type Spec struct {
+	OpenAPI    string                           `json:"openapi"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+// Components - переиспользуемые объекты спецификации.
+type Components struct {
+	Schemas   map[string]*Schema   `json:"schemas"`
+	Responses map[string]*Response `json:"responses"`
+}
+
+// Operation - операция (метод + путь).
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	RequestBody *RequestBody         `json:"requestBody"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// RequestBody - описание тела запроса.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response - описание ответа.
+type Response struct {
+	Ref     string               `json:"$ref"`
+	Content map[string]MediaType `json:"content"`
+}
+
+// MediaType - схема содержимого определённого типа.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Load разбирает встроенную спецификацию.
+func Load() (*Spec, error) {
+	var s Spec
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("ошибка разбора спецификации: %w", err)
+	}
+	return &s, nil
+}
+
+// Find возвращает операцию и шаблон пути, соответствующие запросу.
+// Как и в маршрутизаторе, статические сегменты пути приоритетнее
+// параметров: /albums/events соответствует /albums/events, а не /albums/{id}.
+func (s *Spec) Find(method, path string) (*Operation, string, bool) {
+	best, bestParams := "", -1
+	for tmpl := range s.Paths {
+		if !matchPath(tmpl, path) {
+			continue
+		}
+		params := strings.Count(tmpl, "{")
+		if bestParams < 0 || params < bestParams {
+			best, bestParams = tmpl, params
+		}
+	}
+	if bestParams < 0 {
+		return nil, "", false
+	}
+
+	op, ok := s.Paths[best][strings.ToLower(method)]
+	return op, best, ok
+}
+
+// ValidateRequest проверяет тело запроса на соответствие спецификации.
+func (s *Spec) ValidateRequest(op *Operation, contentType string, body []byte) error {
+	if op.RequestBody == nil {
+		return nil
+	}
+	if len(body) == 0 {
+		if op.RequestBody.Required {
+			return fmt.Errorf("тело запроса обязательно")
+		}
+		return nil
+	}
+
+	// Запрос без заголовка Content-Type считается JSON.
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	mt, ok := op.RequestBody.Content[mediaType(contentType)]
+	if !ok {
+		return fmt.Errorf("неподдерживаемый тип содержимого %q", contentType)
+	}
+	return s.validateBody(mt.Schema, body)
+}
+
+// ValidateResponse проверяет ответ на соответствие спецификации.
+func (s *Spec) ValidateResponse(op *Operation, status int, contentType string, body []byte) error {
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		return fmt.Errorf("код ответа %d не описан в спецификации", status)
+	}
+	if resp.Ref != "" {
+		resp, ok = s.Components.Responses[strings.TrimPrefix(resp.Ref, "#/components/responses/")]
+		if !ok {
+			return fmt.Errorf("неизвестная ссылка %q", resp.Ref)
+		}
+	}
+
+	if len(resp.Content) == 0 {
+		if len(body) > 0 {
+			return fmt.Errorf("ответ с кодом %d не должен содержать тело", status)
+		}
+		return nil
+	}
+
+	mt, ok := resp.Content[mediaType(contentType)]
+	if !ok {
+		return fmt.Errorf("тип содержимого %q не описан для кода %d", contentType, status)
+	}
+	if mediaType(contentType) != "application/json" {
+		return nil
+	}
+	return s.validateBody(mt.Schema, body)
+}
+
+func (s *Spec) validateBody(schema *Schema, body []byte) error {
+	if schema == nil {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return fmt.Errorf("некорректный JSON: %w", err)
+	}
+	return s.validate(schema, v, "$")
+}
+
+// matchPath сопоставляет путь запроса с шаблоном вида /albums/{id}.
+func matchPath(tmpl, path string) bool {
+	ts := strings.Split(strings.Trim(tmpl, "/"), "/")
+	ps := strings.Split(strings.Trim(path, "/"), "/")
+	if len(ts) != len(ps) {
+		return false
+	}
+	for i := range ts {
+		if strings.HasPrefix(ts[i], "{") && strings.HasSuffix(ts[i], "}") {
+			if ps[i] == "" {
+				return false
+			}
+			continue
+		}
+		if ts[i] != ps[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mt
+}
+
+// Operations возвращает все пары "метод путь", описанные в спецификации.
+func (s *Spec) Operations() []string {
+	var ops []string
+	for path, item := range s.Paths {
+		for method := range item {
+			ops = append(ops, strings.ToUpper(method)+" "+path)
+		}
+	}
+	return ops
+}
@@ -0,0 +1,37 @@
+package outbox
+
+import (
+	"fmt"
+	"time"
+
+	"go-masters/10-cloud_ready/cloudapp/internal/config"
+
+	"github.com/rs/zerolog/log"
+)
+
+// webhookTimeout - ограничение времени доставки одного события в webhook.
+const webhookTimeout = 10 * time.Second
+
+// SinksFromConfig создает приемники, перечисленные в конфигурации.
+func SinksFromConfig(cfg config.Outbox) ([]Sink, error) {
+	var sinks []Sink
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, NewLogSink(log.Logger))
+		case "file":
+			if cfg.FilePath == "" {
+				return nil, fmt.Errorf("для приемника file не задан outbox.file_path")
+			}
+			sinks = append(sinks, NewFileSink(cfg.FilePath))
+		case "webhook":
+			if cfg.WebhookURL == "" {
+				return nil, fmt.Errorf("для приемника webhook не задан outbox.webhook_url")
+			}
+			sinks = append(sinks, NewWebhookSink(cfg.WebhookURL, webhookTimeout))
+		default:
+			return nil, fmt.Errorf("неизвестный приемник outbox %q", name)
+		}
+	}
+	return sinks, nil
+}
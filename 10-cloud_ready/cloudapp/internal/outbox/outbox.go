@@ -0,0 +1,54 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"go-masters/10-cloud_ready/cloudapp/internal/events"
+)
+
+// Record - запись outbox, ожидающая доставки.
+type Record struct {
+	Event events.Event
+	// Номер текущей попытки доставки (начиная с 1).
+	Attempt int
+	// Приемники, которым событие уже доставлено в предыдущих попытках.
+	Delivered []string
+}
+
+// Store - хранилище outbox.
+type Store interface {
+	// Claim выбирает до limit записей, готовых к доставке, и арендует их
+	// на время lease, чтобы другие экземпляры relay их не получили.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Record, error)
+	// Complete отмечает событие как доставленное всем приемникам.
+	Complete(ctx context.Context, id int64) error
+	// Retry назначает повторную попытку доставки на время next,
+	// сохраняя список приемников, которым событие уже доставлено.
+	Retry(ctx context.Context, id int64, delivered []string, next time.Time, reason string) error
+	// Fail отмечает событие как недоставленное после исчерпания попыток.
+	// Такие записи больше не выбираются и остаются в outbox для разбора.
+	Fail(ctx context.Context, id int64, delivered []string, reason string) error
+}
+
+// Sink - приемник событий. Доставка выполняется по семантике
+// at-least-once, поэтому приемник должен быть готов к повторам
+// (идентификатор события стабилен между попытками).
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, e events.Event) error
+}
+
+// SinkFunc - адаптер функции к интерфейсу Sink.
+type SinkFunc struct {
+	SinkName string
+	Fn       func(context.Context, events.Event) error
+}
+
+func (s SinkFunc) Name() string {
+	return s.SinkName
+}
+
+func (s SinkFunc) Deliver(ctx context.Context, e events.Event) error {
+	return s.Fn(ctx, e)
+}
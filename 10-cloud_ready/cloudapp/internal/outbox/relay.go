@@ -0,0 +1,141 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Параметры relay по умолчанию.
+const (
+	DefaultPollInterval = time.Second
+	DefaultBatchSize    = 100
+	DefaultLease        = 30 * time.Second
+	DefaultMinBackoff   = time.Second
+	DefaultMaxBackoff   = 5 * time.Minute
+	DefaultMaxAttempts  = 20
+)
+
+// Relay - фоновый процесс доставки событий из outbox в приемники.
+type Relay struct {
+	store Store
+	sinks []Sink
+
+	PollInterval time.Duration
+	BatchSize    int
+	Lease        time.Duration
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+	// MaxAttempts - число попыток доставки, после которого событие
+	// отмечается как недоставленное (Store.Fail).
+	MaxAttempts int
+}
+
+func NewRelay(store Store, sinks ...Sink) *Relay {
+	return &Relay{
+		store:        store,
+		sinks:        sinks,
+		PollInterval: DefaultPollInterval,
+		BatchSize:    DefaultBatchSize,
+		Lease:        DefaultLease,
+		MinBackoff:   DefaultMinBackoff,
+		MaxBackoff:   DefaultMaxBackoff,
+		MaxAttempts:  DefaultMaxAttempts,
+	}
+}
+
+// Run опрашивает outbox до отмены контекста.
+func (r *Relay) Run(ctx context.Context) {
+	log.Info().Int("sinks", len(r.sinks)).Msg("Запуск outbox relay")
+
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := r.RunOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Error().Err(err).Msg("Ошибка обработки outbox")
+			}
+			// Если пакет заполнен целиком, сразу выбираем следующий.
+			if n < r.BatchSize || err != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Остановка outbox relay")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce выполняет одну итерацию доставки и возвращает
+// количество обработанных записей.
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	records, err := r.store.Claim(ctx, r.BatchSize, r.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка выборки outbox: %w", err)
+	}
+
+	var errs error
+	for _, rec := range records {
+		errs = errors.Join(errs, r.deliver(ctx, rec))
+	}
+	return len(records), errs
+}
+
+// deliver доставляет событие во все приемники, которым оно еще не доставлено.
+func (r *Relay) deliver(ctx context.Context, rec Record) error {
+	delivered := slices.Clone(rec.Delivered)
+
+	var errs error
+	for _, sink := range r.sinks {
+		if slices.Contains(delivered, sink.Name()) {
+			continue
+		}
+		if err := sink.Deliver(ctx, rec.Event); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+			continue
+		}
+		delivered = append(delivered, sink.Name())
+	}
+
+	if errs == nil {
+		return r.store.Complete(ctx, rec.Event.ID)
+	}
+
+	if rec.Attempt >= r.MaxAttempts {
+		log.Error().
+			Err(errs).
+			Int64("event_id", rec.Event.ID).
+			Int("attempt", rec.Attempt).
+			Msg("Не удалось доставить событие, попытки исчерпаны")
+		return r.store.Fail(ctx, rec.Event.ID, delivered, errs.Error())
+	}
+
+	next := time.Now().Add(r.backoff(rec.Attempt))
+	log.Warn().
+		Err(errs).
+		Int64("event_id", rec.Event.ID).
+		Int("attempt", rec.Attempt).
+		Time("next_attempt", next).
+		Msg("Не удалось доставить событие, повтор запланирован")
+
+	return r.store.Retry(ctx, rec.Event.ID, delivered, next, errs.Error())
+}
+
+// backoff - экспоненциальная задержка перед повторной попыткой.
+func (r *Relay) backoff(attempt int) time.Duration {
+	d := r.MinBackoff
+	for i := 1; i < attempt && d < r.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, r.MaxBackoff)
+}
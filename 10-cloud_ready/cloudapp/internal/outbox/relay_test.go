@@ -0,0 +1,198 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-masters/10-cloud_ready/cloudapp/internal/events"
+	"go-masters/10-cloud_ready/cloudapp/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memStore - хранилище outbox в памяти для тестов.
+type memStore struct {
+	mu      sync.Mutex
+	records map[int64]*memRecord
+}
+
+type memRecord struct {
+	rec    Record
+	next   time.Time
+	done   bool
+	failed bool
+}
+
+func newMemStore(evs ...events.Event) *memStore {
+	s := &memStore{records: make(map[int64]*memRecord)}
+	for _, e := range evs {
+		s.records[e.ID] = &memRecord{rec: Record{Event: e}}
+	}
+	return s
+}
+
+func (s *memStore) Claim(_ context.Context, limit int, lease time.Duration) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var res []Record
+	for id := int64(1); id <= int64(len(s.records)) && len(res) < limit; id++ {
+		r := s.records[id]
+		if r.done || r.failed || r.next.After(time.Now()) {
+			continue
+		}
+		r.rec.Attempt++
+		r.next = time.Now().Add(lease)
+		res = append(res, r.rec)
+	}
+	return res, nil
+}
+
+func (s *memStore) Complete(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[id].done = true
+	return nil
+}
+
+func (s *memStore) Retry(_ context.Context, id int64, delivered []string, next time.Time, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[id].rec.Delivered = delivered
+	s.records[id].next = next
+	return nil
+}
+
+func (s *memStore) Fail(_ context.Context, id int64, delivered []string, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[id].rec.Delivered = delivered
+	s.records[id].failed = true
+	return nil
+}
+
+// flakySink - приемник, отказывающий заданное число раз.
+type flakySink struct {
+	name     string
+	failures int
+	got      []int64
+}
+
+func (s *flakySink) Name() string {
+	return s.name
+}
+
+func (s *flakySink) Deliver(_ context.Context, e events.Event) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("недоступен")
+	}
+	s.got = append(s.got, e.ID)
+	return nil
+}
+
+func TestRelay_RetriesOnlyFailedSinks(t *testing.T) {
+	store := newMemStore(
+		events.Event{ID: 1, Type: events.Created, Album: models.Album{ID: "1"}},
+		events.Event{ID: 2, Type: events.Deleted, Album: models.Album{ID: "1"}},
+	)
+	stable := &flakySink{name: "stable"}
+	flaky := &flakySink{name: "flaky", failures: 1}
+
+	relay := NewRelay(store, stable, flaky)
+	relay.MinBackoff = 0
+
+	n, err := relay.RunOnce(context.Background())
+	assert.Equal(t, 2, n)
+	assert.NoError(t, err) // ошибка приемника приводит к повтору, а не к ошибке relay
+	assert.Equal(t, []int64{1, 2}, stable.got)
+	assert.Equal(t, []int64{2}, flaky.got)
+
+	n, err = relay.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	// Стабильный приемник не получает повтор события 1.
+	assert.Equal(t, []int64{1, 2}, stable.got)
+	assert.Equal(t, []int64{2, 1}, flaky.got)
+
+	n, err = relay.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestRelay_DeadLetter(t *testing.T) {
+	store := newMemStore(events.Event{ID: 1, Type: events.Created, Album: models.Album{ID: "1"}})
+	stable := &flakySink{name: "stable"}
+	broken := &flakySink{name: "broken", failures: 100}
+
+	relay := NewRelay(store, stable, broken)
+	relay.MinBackoff = 0
+	relay.MaxAttempts = 3
+
+	for range relay.MaxAttempts {
+		n, err := relay.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+	}
+	assert.True(t, store.records[1].failed)
+	assert.Equal(t, []string{"stable"}, store.records[1].rec.Delivered)
+	assert.Equal(t, []int64{1}, stable.got)
+
+	// Недоставленное событие больше не выбирается.
+	n, err := relay.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	relay := NewRelay(nil)
+	relay.MinBackoff = time.Second
+	relay.MaxBackoff = 10 * time.Second
+
+	assert.Equal(t, time.Second, relay.backoff(1))
+	assert.Equal(t, 2*time.Second, relay.backoff(2))
+	assert.Equal(t, 8*time.Second, relay.backoff(4))
+	assert.Equal(t, 10*time.Second, relay.backoff(10))
+}
+
+func TestWebhookSink(t *testing.T) {
+	var gotID string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = r.Header.Get("X-Event-Id")
+		if gotID == "2" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	sink := NewWebhookSink(ts.URL, time.Second)
+	assert.NoError(t, sink.Deliver(context.Background(), events.Event{ID: 1}))
+	assert.Equal(t, "1", gotID)
+	assert.Error(t, sink.Deliver(context.Background(), events.Event{ID: 2}))
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink := NewFileSink(path)
+
+	require.NoError(t, sink.Deliver(context.Background(), events.Event{ID: 1, Type: events.Created}))
+	require.NoError(t, sink.Deliver(context.Background(), events.Event{ID: 2, Type: events.Deleted}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[1], `"type":"deleted"`)
+}
@@ -0,0 +1,18 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// errorResponse - тело ответа с ошибкой.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeError записывает ошибку в ответ в формате JSON.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: msg})
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-masters/10-cloud_ready/cloudapp/internal/events"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// Размер буфера подписки одного клиента.
+	feedBuffer = 64
+	// Период отправки комментария для поддержания SSE соединения.
+	sseHeartbeat = 15 * time.Second
+	// Время на отправку одного сообщения WebSocket.
+	wsWriteTimeout = 10 * time.Second
+)
+
+// lastEventID возвращает идентификатор последнего полученного клиентом
+// события из заголовка Last-Event-ID или параметра last_event_id.
+// Если он не передан, возвращается -1 (только новые события).
+func lastEventID(r *http.Request) (int64, error) {
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		v = r.URL.Query().Get("last_event_id")
+	}
+	if v == "" {
+		return -1, nil
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
+
+// albumEventsHandler - поток изменений альбомов в формате Server-Sent Events.
+func (s *Server) albumEventsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса albumEvents")
+	span.AddEvent("Обработка запроса albumEvents")
+
+	lastID, err := lastEventID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "некорректный идентификатор события")
+		return
+	}
+
+	// Соединение долгоживущее, поэтому снимаем ограничение WriteTimeout.
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		writeError(w, http.StatusInternalServerError, "потоковая передача не поддерживается")
+		return
+	}
+
+	missed, ch, unsubscribe := s.bus.SubscribeFrom(lastID, feedBuffer)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for _, e := range missed {
+		if err := writeSSE(w, e); err != nil {
+			return
+		}
+	}
+	rc.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-ch:
+			// Подписка отменена шиной: клиент не успевал читать события
+			// и переподключится с Last-Event-ID.
+			if !ok {
+				return
+			}
+			if err := writeSSE(w, e); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
+
+// albumEventsWSHandler - поток изменений альбомов через WebSocket.
+// Каждое событие передается отдельным JSON сообщением.
+func (s *Server) albumEventsWSHandler(w http.ResponseWriter, r *http.Request) {
+	lastID, err := lastEventID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "некорректный идентификатор события")
+		return
+	}
+
+	// Перехваченное соединение сохраняет сроки ReadTimeout и WriteTimeout
+	// сервера; для долгоживущего соединения снимаем их, а запись каждого
+	// сообщения ограничиваем отдельно.
+	rc := http.NewResponseController(w)
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		writeError(w, http.StatusInternalServerError, "потоковая передача не поддерживается")
+		return
+	}
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		writeError(w, http.StatusInternalServerError, "потоковая передача не поддерживается")
+		return
+	}
+
+	ws := websocket.Server{
+		Handler: func(conn *websocket.Conn) {
+			defer conn.Close()
+
+			missed, ch, unsubscribe := s.bus.SubscribeFrom(lastID, feedBuffer)
+			defer unsubscribe()
+
+			for _, e := range missed {
+				if err := sendWS(conn, e); err != nil {
+					return
+				}
+			}
+
+			// Чтение нужно только для обнаружения закрытия соединения клиентом.
+			closed := make(chan struct{})
+			go func() {
+				defer close(closed)
+				var msg string
+				for websocket.Message.Receive(conn, &msg) == nil {
+				}
+			}()
+
+			for {
+				select {
+				case <-closed:
+					return
+				case e, ok := <-ch:
+					if !ok {
+						return
+					}
+					if err := sendWS(conn, e); err != nil {
+						return
+					}
+				}
+			}
+		},
+	}
+	ws.ServeHTTP(w, r)
+}
+
+func sendWS(conn *websocket.Conn, e events.Event) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+	return websocket.JSON.Send(conn, e)
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-masters/10-cloud_ready/cloudapp/internal/auth"
+	"go-masters/10-cloud_ready/cloudapp/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestAlbumEvents_SSE(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s.router)
+	defer ts.Close()
+
+	do(s, http.MethodPost, "/albums", `{"artist":"A","title":"One"}`, auth.RoleEditor)
+	do(s, http.MethodPost, "/albums", `{"artist":"A","title":"Two"}`, auth.RoleEditor)
+
+	// Клиент уже получил первое событие и возобновляет подписку.
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/albums/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "1")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	sc := bufio.NewScanner(resp.Body)
+	assert.Equal(t, []string{"id: 2", "event: created"}, readLines(sc, 2))
+	assert.Contains(t, readLines(sc, 1)[0], `"title":"Two"`)
+
+	do(s, http.MethodDelete, "/albums/1", "", auth.RoleAdmin)
+
+	readLines(sc, 1) // пустая строка - конец события
+	assert.Equal(t, []string{"id: 3", "event: deleted"}, readLines(sc, 2))
+}
+
+func TestAlbumEvents_WebSocket(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s.router)
+	defer ts.Close()
+
+	do(s, http.MethodPost, "/albums", `{"artist":"A","title":"One"}`, auth.RoleEditor)
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/albums/ws?last_event_id=0"
+	conn, err := websocket.Dial(url, "", ts.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var e events.Event
+	require.NoError(t, websocket.JSON.Receive(conn, &e))
+	assert.Equal(t, int64(1), e.ID)
+	assert.Equal(t, events.Created, e.Type)
+	assert.Equal(t, "One", e.Album.Title)
+}
+
+func TestAlbumEvents_WebSocketOutlivesTimeouts(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewUnstartedServer(s.router)
+	ts.Config.ReadTimeout = 100 * time.Millisecond
+	ts.Config.WriteTimeout = 100 * time.Millisecond
+	ts.Start()
+	defer ts.Close()
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/albums/ws"
+	conn, err := websocket.Dial(url, "", ts.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Событие после истечения сроков сервера доходит до клиента.
+	time.Sleep(3 * ts.Config.WriteTimeout)
+	do(s, http.MethodPost, "/albums", `{"artist":"A","title":"Late"}`, auth.RoleEditor)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var e events.Event
+	require.NoError(t, websocket.JSON.Receive(conn, &e))
+	assert.Equal(t, "Late", e.Album.Title)
+}
+
+func TestAlbumEvents_BadLastEventID(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := do(s, http.MethodGet, "/albums/events?last_event_id=abc", "", auth.RoleViewer)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func readLines(sc *bufio.Scanner, n int) []string {
+	var lines []string
+	for range n {
+		if !sc.Scan() {
+			break
+		}
+		lines = append(lines, sc.Text())
+	}
+	return lines
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"go-masters/10-cloud_ready/cloudapp/internal/auth"
+	"go-masters/10-cloud_ready/cloudapp/internal/openapi"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSpecRoutes проверяет, что набор маршрутов совпадает со спецификацией.
+func TestSpecRoutes(t *testing.T) {
+	s := newTestServer(t)
+
+	var routes []string
+	err := chi.Walk(s.router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		// Отладочные эндпоинты pprof намеренно не описываются.
+		if !strings.HasPrefix(route, "/debug/") {
+			routes = append(routes, method+" "+route)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	ops := s.spec.Operations()
+	sort.Strings(routes)
+	sort.Strings(ops)
+	assert.Equal(t, ops, routes)
+}
+
+// TestSpecResponses проверяет ответы обработчиков на соответствие спецификации.
+func TestSpecResponses(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		method string
+		target string
+		body   string
+		role   auth.Role
+		status int
+	}{
+		{http.MethodGet, "/health", "", "", http.StatusOK},
+		{http.MethodGet, "/metrics", "", "", http.StatusOK},
+		{http.MethodGet, "/openapi.json", "", "", http.StatusOK},
+		{http.MethodGet, "/docs", "", "", http.StatusOK},
+		{http.MethodGet, "/albums", "", auth.RoleViewer, http.StatusOK},
+		{http.MethodPost, "/albums", `{"artist":"A","title":"T","year":1999}`, auth.RoleViewer, http.StatusForbidden},
+		{http.MethodPost, "/albums", `{"artist":"A"}`, auth.RoleEditor, http.StatusBadRequest},
+		{http.MethodPost, "/albums", `{"artist":"` + strings.Repeat("A", openapi.MaxRequestBody) + `"}`, auth.RoleEditor, http.StatusRequestEntityTooLarge},
+		{http.MethodPost, "/albums", `{"artist":"A","title":"T","year":1999}`, auth.RoleEditor, http.StatusCreated},
+		{http.MethodGet, "/albums", "", auth.RoleViewer, http.StatusOK},
+		{http.MethodGet, "/albums/1", "", auth.RoleViewer, http.StatusOK},
+		{http.MethodGet, "/albums/42", "", auth.RoleViewer, http.StatusNotFound},
+		{http.MethodPut, "/albums/1", `{"artist":"B","title":"T"}`, auth.RoleEditor, http.StatusNoContent},
+		{http.MethodPut, "/albums/42", `{"artist":"B","title":"T"}`, auth.RoleEditor, http.StatusNotFound},
+		{http.MethodDelete, "/albums/1", "", auth.RoleAdmin, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			rec := do(s, tt.method, tt.target, tt.body, tt.role)
+			require.Equal(t, tt.status, rec.Code, rec.Body.String())
+
+			op, _, ok := s.spec.Find(tt.method, tt.target)
+			require.True(t, ok)
+			err := s.spec.ValidateResponse(op, rec.Code, rec.Header().Get("Content-Type"), rec.Body.Bytes())
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -3,16 +3,20 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/pprof"
 	"time"
 
+	"go-masters/10-cloud_ready/cloudapp/internal/audit"
+	"go-masters/10-cloud_ready/cloudapp/internal/auth"
 	"go-masters/10-cloud_ready/cloudapp/internal/config"
 	"go-masters/10-cloud_ready/cloudapp/internal/db"
-	"go-masters/10-cloud_ready/cloudapp/internal/db/postgres"
+	"go-masters/10-cloud_ready/cloudapp/internal/events"
 	"go-masters/10-cloud_ready/cloudapp/internal/metrics"
 	"go-masters/10-cloud_ready/cloudapp/internal/models"
+	"go-masters/10-cloud_ready/cloudapp/internal/openapi"
 	"go-masters/10-cloud_ready/cloudapp/internal/telemetry"
 
 	"github.com/go-chi/chi/v5"
@@ -29,16 +33,20 @@ type Server struct {
 	router *chi.Mux
 	server *http.Server
 	db     db.DB
+	bus    *events.Bus
+	policy auth.Policy
+	audit  *audit.Logger
+	spec   *openapi.Spec
 }
 
-func New(cfg *config.Cfg) (*Server, error) {
-	r := chi.NewRouter()
-
-	db, err := postgres.New(cfg.DBConnStr)
+func New(cfg *config.Cfg, db db.DB, bus *events.Bus) (*Server, error) {
+	spec, err := openapi.Load()
 	if err != nil {
-		return nil, fmt.Errorf("ошибка инициализации БД: %w", err)
+		return nil, err
 	}
 
+	r := chi.NewRouter()
+
 	s := Server{
 		cfg:    cfg,
 		router: r,
@@ -49,7 +57,11 @@ func New(cfg *config.Cfg) (*Server, error) {
 			WriteTimeout: 10 * time.Second,
 			IdleTimeout:  15 * time.Second,
 		},
-		db: db,
+		db:     db,
+		bus:    bus,
+		policy: auth.DefaultPolicy,
+		audit:  audit.New(log.Logger),
+		spec:   spec,
 	}
 
 	s.endpoints()
@@ -65,6 +77,7 @@ func (s *Server) endpoints() {
 		metrics.PrometheusMiddleware,         // Метрики Prometheus
 		RequestLoggerMiddleware(&log.Logger), // Логирование запросов
 		middleware.Recoverer,                 // Восстановление после паник
+		auth.IdentifyMiddleware,              // Определение вызывающей стороны
 	)
 
 	// Эндпоинты pprof
@@ -87,9 +100,25 @@ func (s *Server) endpoints() {
 	// Эндпоинт для Prometheus
 	s.router.Get("/metrics", promhttp.Handler().ServeHTTP)
 
+	// Спецификация OpenAPI и Swagger UI
+	s.router.Get("/openapi.json", openapi.SpecHandler)
+	s.router.Get("/docs", openapi.DocsHandler)
+
 	// Инициализация маршрутов
-	s.router.Post("/albums", s.addAlbumHandler)
-	s.router.Get("/albums", s.listAlbumsHandler)
+	s.guarded(auth.PermAlbumsCreate).Post("/albums", s.addAlbumHandler)
+	s.guarded(auth.PermAlbumsRead).Get("/albums", s.listAlbumsHandler)
+	s.guarded(auth.PermAlbumsRead).Get("/albums/{id}", s.getAlbumHandler)
+	s.router.With(s.require(auth.PermAlbumsRead)).Get("/albums/events", s.albumEventsHandler)
+	s.router.With(s.require(auth.PermAlbumsRead)).Get("/albums/ws", s.albumEventsWSHandler)
+	s.guarded(auth.PermAlbumsUpdate).Put("/albums/{id}", s.updateAlbumHandler)
+	s.guarded(auth.PermAlbumsDelete).Delete("/albums/{id}", s.deleteAlbumHandler)
+}
+
+// guarded возвращает маршрутизатор, проверяющий права вызывающей стороны
+// и соответствие запроса и ответа спецификации OpenAPI.
+func (s *Server) guarded(perm auth.Permission) chi.Router {
+	validate := openapi.ValidationMiddleware(s.spec, writeError, logSpecViolation)
+	return s.router.With(s.require(perm), validate)
 }
 
 func (s *Server) Start(ctx context.Context) error {
@@ -133,6 +162,7 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 	span := trace.SpanFromContext(ctx)
 	defer span.End()
 
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
 }
@@ -148,14 +178,14 @@ func (s *Server) addAlbumHandler(w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		span.SetStatus(codes.Error, "не удалось декодировать запрос")
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	err = s.db.AddAlbum(r.Context(), req)
+	_, err = s.db.AddAlbum(r.Context(), req)
 	if err != nil {
 		span.SetStatus(codes.Error, "не удалось добавить альбом в БД")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -173,14 +203,103 @@ func (s *Server) listAlbumsHandler(w http.ResponseWriter, r *http.Request) {
 	albums, err := s.db.ListAlbums(ctx)
 	if err != nil {
 		span.SetStatus(codes.Error, "не удалось получить альбомы")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if albums == nil {
+		albums = []models.Album{}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(albums)
 }
 
+func (s *Server) getAlbumHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса getAlbum")
+	span.AddEvent("Обработка запроса getAlbum")
+
+	album, err := s.db.GetAlbum(ctx, chi.URLParam(r, "id"))
+	if errors.Is(err, db.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "альбом не найден")
+		return
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, "не удалось получить альбом")
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(album)
+}
+
+func (s *Server) updateAlbumHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса updateAlbum")
+	span.AddEvent("Обработка запроса updateAlbum")
+
+	var req models.Album
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		span.SetStatus(codes.Error, "не удалось декодировать запрос")
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.ID = chi.URLParam(r, "id")
+
+	err = s.db.UpdateAlbum(ctx, req)
+	if errors.Is(err, db.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "альбом не найден")
+		return
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, "не удалось обновить альбом")
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteAlbumHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса deleteAlbum")
+	span.AddEvent("Обработка запроса deleteAlbum")
+
+	err := s.db.DeleteAlbum(ctx, chi.URLParam(r, "id"))
+	if errors.Is(err, db.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "альбом не найден")
+		return
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, "не удалось удалить альбом")
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// logSpecViolation логирует ответы, не соответствующие спецификации.
+func logSpecViolation(r *http.Request, status int, err error) {
+	log.Error().
+		Err(err).
+		Str("method", r.Method).
+		Str("path", r.URL.Path).
+		Int("status", status).
+		Msg("Ответ не соответствует спецификации OpenAPI")
+}
+
 // RequestLoggerMiddleware - middleware для логирования запросов
 func RequestLoggerMiddleware(logger *zerolog.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-masters/10-cloud_ready/cloudapp/internal/auth"
+	"go-masters/10-cloud_ready/cloudapp/internal/config"
+	"go-masters/10-cloud_ready/cloudapp/internal/db/memdb"
+	"go-masters/10-cloud_ready/cloudapp/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	bus := events.NewBus()
+	s, err := New(&config.Cfg{}, events.NewPublishingDB(memdb.New(), bus), bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func do(s *Server, method, target, body string, role auth.Role) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if role != "" {
+		req.Header.Set(auth.HeaderUserID, "user-1")
+		req.Header.Set(auth.HeaderRole, string(role))
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAlbumsAuthorization(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := do(s, http.MethodPost, "/albums", `{"artist":"A","title":"T","year":2000}`, "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.JSONEq(t, `{"error":"недостаточно прав для выполнения операции"}`, rec.Body.String())
+
+	rec = do(s, http.MethodPost, "/albums", `{"artist":"A","title":"T","year":2000}`, auth.RoleEditor)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = do(s, http.MethodGet, "/albums/1", "", auth.RoleViewer)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = do(s, http.MethodPut, "/albums/1", `{"artist":"B","title":"T","year":2001}`, auth.RoleViewer)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = do(s, http.MethodPut, "/albums/1", `{"artist":"B","title":"T","year":2001}`, auth.RoleEditor)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = do(s, http.MethodDelete, "/albums/1", "", auth.RoleEditor)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = do(s, http.MethodDelete, "/albums/1", "", auth.RoleAdmin)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = do(s, http.MethodGet, "/albums/1", "", auth.RoleAdmin)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Глобальная последовательность идентификаторов событий изменения альбомов.
+-- Общая для всех экземпляров сервиса, что позволяет клиенту возобновить
+-- подписку на любом из них.
+create sequence album_events_id_seq;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop sequence album_events_id_seq;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Transactional outbox: события изменений альбомов записываются в одной
+-- транзакции с самим изменением и доставляются фоновым relay-процессом.
+create table album_outbox (
+    id bigint primary key default nextval('album_events_id_seq'),
+    event_type text not null,
+    payload jsonb not null,
+    created_at timestamptz not null default now(),
+    -- Время следующей попытки доставки (также используется как аренда записи).
+    next_attempt_at timestamptz not null default now(),
+    attempts integer not null default 0,
+    -- Приемники, которым событие уже доставлено.
+    delivered_sinks text[] not null default '{}',
+    delivered_at timestamptz,
+    last_error text
+);
+
+create index album_outbox_pending_idx on album_outbox (next_attempt_at)
+    where delivered_at is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table album_outbox;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Время отметки о недоставке: события, для которых исчерпаны попытки,
+-- больше не выбираются relay и остаются в outbox для разбора.
+alter table album_outbox add column failed_at timestamptz;
+
+drop index album_outbox_pending_idx;
+create index album_outbox_pending_idx on album_outbox (next_attempt_at)
+    where delivered_at is null and failed_at is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index album_outbox_pending_idx;
+create index album_outbox_pending_idx on album_outbox (next_attempt_at)
+    where delivered_at is null;
+alter table album_outbox drop column failed_at;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Номер события назначается relay при выборке записи, а не при вставке:
+-- значения последовательности выдаются до фиксации транзакции, и событие
+-- с меньшим номером могло стать видимым позже большего. Тогда клиент,
+-- возобновивший подписку с большего номера, не получил бы меньший.
+-- Идентификатор записи outbox берется из отдельной последовательности.
+create sequence album_outbox_id_seq owned by album_outbox.id;
+select setval('album_outbox_id_seq', coalesce((select max(id) from album_outbox), 0) + 1, false);
+alter table album_outbox alter column id set default nextval('album_outbox_id_seq');
+
+alter table album_outbox add column event_id bigint unique;
+-- Уже выбранные записи сохраняют прежние номера событий.
+update album_outbox set event_id = id where attempts > 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table album_outbox drop column event_id;
+alter table album_outbox alter column id set default nextval('album_events_id_seq');
+drop sequence album_outbox_id_seq;
+-- +goose StatementEnd
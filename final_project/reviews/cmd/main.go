@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go-masters/final_project/reviews/internal/catalog"
+	"go-masters/final_project/reviews/internal/chat"
+	"go-masters/final_project/reviews/internal/config"
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/db/postgres"
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/language"
+	"go-masters/final_project/reviews/internal/llm"
+	"go-masters/final_project/reviews/internal/moderation"
+	"go-masters/final_project/reviews/internal/prompts"
+	"go-masters/final_project/reviews/internal/reclassify"
+	"go-masters/final_project/reviews/internal/sentiment"
+	"go-masters/final_project/reviews/internal/sentiment/lexicon"
+	"go-masters/final_project/reviews/internal/sentiment/ollama"
+	"go-masters/final_project/reviews/internal/server"
+	"go-masters/final_project/reviews/internal/similarity"
+	"go-masters/final_project/reviews/internal/trend"
+	"go-masters/final_project/reviews/internal/webhook"
+
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	// Создаем контекст для graceful shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Инициализируем конфигурацию
+	cfg, err := config.Load(".")
+	if err != nil {
+		log.Fatal().Err(err).Msg("Ошибка при загрузке конфигурации")
+	}
+
+	// Команда reviews prompt-eval не использует хранилище
+	if len(os.Args) > 1 && os.Args[1] == "prompt-eval" {
+		if err := promptEvalCmd(ctx, cfg, os.Args[2:]); err != nil {
+			log.Fatal().Err(err).Msg("Ошибка сравнения запросов")
+		}
+		return
+	}
+
+	// Инициализируем хранилище
+	store, err := newStore(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Ошибка инициализации БД")
+	}
+
+	// Клиент Ollama создается без подключения к серверу,
+	// поэтому его можно создавать и без классификатора ollama
+	lc, err := newLLM(cfg.LLM)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Ошибка инициализации клиента LLM")
+	}
+
+	classifier, err := newClassifier(cfg, lc)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Ошибка инициализации классификатора")
+	}
+	// Рейтинги категорий сбрасываются при сохранении результатов классификации.
+	categoryRatings := catalog.NewRatings(store, cfg.Catalog.RatingTTL)
+	runner := reclassify.NewRunner(categoryRatings.Reclassify(store), classifier)
+
+	// Команда reviews reclassify выполняется без запуска сервера
+	if len(os.Args) > 1 && os.Args[1] == "reclassify" {
+		if err := reclassifyCmd(ctx, runner, os.Args[2:]); err != nil {
+			log.Fatal().Err(err).Msg("Ошибка переклассификации")
+		}
+		return
+	}
+
+	// Запускаем фоновую классификацию отзывов
+	pool := jobs.NewPool(categoryRatings.Jobs(store), classifier)
+	pool.Workers = cfg.Sentiment.Workers
+	pool.MaxAttempts = cfg.Sentiment.MaxAttempts
+	// Таймаут LLM задается в самом классификаторе, запас оставлен
+	// на запасной словарный классификатор.
+	pool.Timeout = 2 * cfg.Sentiment.Timeout
+	go pool.Run(ctx)
+
+	// Запускаем индексацию отзывов для поиска похожих
+	if cfg.Similarity.Enabled {
+		indexer := similarity.NewIndexer(store, similarity.NewEmbedder(lc, cfg.Similarity.Model))
+		indexer.Threshold = cfg.Similarity.Threshold
+		go indexer.Run(ctx)
+	}
+
+	// Отправляем уведомления подписчикам
+	dispatcher := webhook.NewDispatcher(store)
+	dispatcher.Workers = cfg.Webhooks.Workers
+	dispatcher.Timeout = cfg.Webhooks.Timeout
+	dispatcher.MaxAttempts = cfg.Webhooks.MaxAttempts
+	dispatcher.MinBackoff = cfg.Webhooks.MinBackoff
+	dispatcher.MaxBackoff = cfg.Webhooks.MaxBackoff
+	go dispatcher.Run(ctx)
+
+	// Отслеживаем резкое падение рейтинга товаров
+	monitor := trend.NewMonitor(store)
+	monitor.Detector = trend.Detector{
+		Window:     cfg.Trend.Window,
+		Baseline:   cfg.Trend.Baseline,
+		Drop:       cfg.Trend.Drop,
+		MinReviews: cfg.Trend.MinReviews,
+	}
+	monitor.PollInterval = cfg.Trend.PollInterval
+	monitor.OnAnomaly(dispatcher.NotifyRatingDrop)
+	go monitor.Run(ctx)
+
+	// Продолжаем прерванные запуски переклассификации
+	go runner.ResumeAll(ctx)
+
+	chats := chat.New(store, lc, chat.Config{
+		Model:         cfg.LLM.Model,
+		SystemPrompt:  cfg.Chat.SystemPrompt,
+		ContextTokens: cfg.Chat.ContextTokens,
+		ReplyTokens:   cfg.Chat.ReplyTokens,
+	})
+
+	moderator, err := newModerator(cfg, lc)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Ошибка инициализации модерации")
+	}
+
+	// Инициализируем сервер
+	srv := server.New(cfg, store, runner, categoryRatings, dispatcher, lc, chats, moderator)
+
+	// Запускаем сервер в отдельной горутине
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			log.Error().Err(err).Msg("Ошибка при запуске сервера")
+			cancel()
+		}
+	}()
+
+	// Ожидаем сигналы ОС для graceful shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case <-quit:
+		log.Info().Msg("Получен сигнал на завершение работы")
+	case <-ctx.Done():
+	}
+	cancel()
+
+	log.Info().Msg("Сервер успешно остановлен")
+}
+
+// store - хранилище отзывов, очереди классификации, запусков
+// переклассификации, диалогов, векторов отзывов, истории рейтинга
+// и подписок на события.
+type store interface {
+	db.DB
+	jobs.Store
+	reclassify.Store
+	chat.Store
+	similarity.Store
+	trend.Store
+	webhook.Store
+}
+
+// newStore создает хранилище, выбранное в конфигурации.
+func newStore(cfg *config.Cfg) (store, error) {
+	if cfg.Storage == config.StorageMemory {
+		log.Warn().Msg("Используется хранилище в памяти, данные не сохраняются")
+		return memdb.New(), nil
+	}
+	return postgres.New(cfg.DBConnStr)
+}
+
+// newClassifier создает классификатор настроения, выбранный в конфигурации,
+// с отдельными классификаторами для языков из sentiment.languages.
+func newClassifier(cfg *config.Cfg, client *llm.Client) (sentiment.Classifier, error) {
+	def, err := newLanguageClassifier(cfg.Sentiment, client)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make(map[string]sentiment.Classifier, len(cfg.Sentiment.Languages))
+	for code := range cfg.Sentiment.Languages {
+		if !language.Valid(code) {
+			return nil, fmt.Errorf("sentiment.languages: некорректный код языка %q", code)
+		}
+		c, err := newLanguageClassifier(cfg.Sentiment.ForLanguage(code), client)
+		if err != nil {
+			return nil, fmt.Errorf("sentiment.languages.%s: %w", code, err)
+		}
+		routes[code] = c
+	}
+	return sentiment.ByLanguage(def, routes), nil
+}
+
+// newLanguageClassifier создает классификатор по настройкам cfg.
+func newLanguageClassifier(cfg config.Sentiment, client *llm.Client) (sentiment.Classifier, error) {
+	if cfg.Classifier == config.ClassifierLexicon {
+		return lexicon.New(), nil
+	}
+
+	registry, err := newPrompts(cfg)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := registry.Get(cfg.PromptName, cfg.PromptVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := ollama.New(ollama.Config{
+		Client:  client,
+		Model:   cfg.Model,
+		Prompt:  prompt,
+		Timeout: cfg.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Fallback {
+		return sentiment.WithFallback(c, lexicon.New()), nil
+	}
+	return c, nil
+}
+
+// newModerator создает проверку отзывов: встроенные проверки
+// и, если включено, проверку моделью LLM.
+func newModerator(cfg *config.Cfg, client *llm.Client) (*moderation.Moderator, error) {
+	if !cfg.Moderation.LLM {
+		return moderation.Default(), nil
+	}
+
+	registry, err := newPrompts(cfg.Sentiment)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := registry.Get(moderation.PromptName, cfg.Moderation.PromptVersion)
+	if err != nil {
+		return nil, err
+	}
+	model := cfg.Moderation.Model
+	if model == "" {
+		model = cfg.LLM.Model
+	}
+	return moderation.New(
+		moderation.NewPII(),
+		moderation.NewWordList(moderation.Profanity, moderation.Toxic),
+		moderation.NewLLM(client, model, prompt, cfg.Moderation.Timeout),
+	), nil
+}
+
+// newPrompts возвращает шаблоны запросов из каталога prompt_dir
+// или встроенные шаблоны.
+func newPrompts(cfg config.Sentiment) (*prompts.Registry, error) {
+	if cfg.PromptDir == "" {
+		return prompts.Default(), nil
+	}
+	return prompts.Load(os.DirFS(cfg.PromptDir))
+}
+
+// newLLM создает клиент Ollama.
+func newLLM(cfg config.LLM) (*llm.Client, error) {
+	return llm.New(llm.Config{
+		Endpoint:         cfg.URL,
+		Timeout:          cfg.Timeout,
+		MaxConcurrent:    cfg.MaxConcurrent,
+		BreakerThreshold: cfg.BreakerThreshold,
+		BreakerCooldown:  cfg.BreakerCooldown,
+	})
+}
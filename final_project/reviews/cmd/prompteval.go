@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go-masters/final_project/reviews/internal/config"
+	"go-masters/final_project/reviews/internal/prompteval"
+	"go-masters/final_project/reviews/internal/prompts"
+	"go-masters/final_project/reviews/internal/sentiment/ollama"
+)
+
+// promptEvalCmd выполняет команду reviews prompt-eval:
+//
+//	reviews prompt-eval -dataset file.jsonl -a 1 -b 2 [-name sentiment] [-model name] [-ollama url]
+//
+// Обе версии запроса классифицируют набор моделью Ollama без запасного
+// классификатора, чтобы его ответы не смешивались с ответами модели.
+func promptEvalCmd(ctx context.Context, cfg *config.Cfg, args []string) error {
+	fs := flag.NewFlagSet("prompt-eval", flag.ContinueOnError)
+	var dataset, name, a, b, model, endpoint string
+	fs.StringVar(&dataset, "dataset", "", "размеченный набор отзывов (JSON Lines: text, label)")
+	fs.StringVar(&name, "name", cfg.Sentiment.PromptName, "имя шаблона запроса")
+	fs.StringVar(&a, "a", cfg.Sentiment.PromptVersion, "версия A")
+	fs.StringVar(&b, "b", prompts.Latest, "версия B")
+	fs.StringVar(&model, "model", cfg.Sentiment.Model, "модель Ollama")
+	fs.StringVar(&endpoint, "ollama", cfg.LLM.URL, "адрес сервера Ollama")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if dataset == "" {
+		return errors.New("не указан набор -dataset")
+	}
+
+	f, err := os.Open(dataset)
+	if err != nil {
+		return err
+	}
+	data, err := prompteval.LoadDataset(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", dataset, err)
+	}
+
+	registry, err := newPrompts(cfg.Sentiment)
+	if err != nil {
+		return err
+	}
+	llmCfg := cfg.LLM
+	llmCfg.URL = endpoint
+	client, err := newLLM(llmCfg)
+	if err != nil {
+		return err
+	}
+	candidate := func(version string) (prompteval.Candidate, error) {
+		p, err := registry.Get(name, version)
+		if err != nil {
+			return prompteval.Candidate{}, err
+		}
+		c, err := ollama.New(ollama.Config{
+			Client:  client,
+			Model:   model,
+			Prompt:  p,
+			Timeout: cfg.Sentiment.Timeout,
+		})
+		return prompteval.Candidate{Name: p.Name + "/" + p.Version, Classifier: c}, err
+	}
+	ca, err := candidate(a)
+	if err != nil {
+		return err
+	}
+	cb, err := candidate(b)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "Сравнение %s и %s на %d отзывах, модель %s\n", ca.Name, cb.Name, len(data), model)
+	rep, err := prompteval.Compare(ctx, ca, cb, data)
+	if err != nil {
+		return err
+	}
+	return rep.Write(os.Stdout)
+}
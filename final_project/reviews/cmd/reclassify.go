@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go-masters/final_project/reviews/internal/reclassify"
+
+	"github.com/rs/zerolog/log"
+)
+
+// reclassifyCmd выполняет команду reviews reclassify:
+//
+//	reviews reclassify [-product id] [-from date] [-to date] [-model name] [-prompt-version v] [-language code]
+//	reviews reclassify -resume run-id
+//
+// Команда прерывается по Ctrl+C и продолжается с флагом -resume.
+func reclassifyCmd(ctx context.Context, runner *reclassify.Runner, args []string) error {
+	fs := flag.NewFlagSet("reclassify", flag.ContinueOnError)
+	var (
+		f                reclassify.Filter
+		from, to, resume string
+	)
+	fs.StringVar(&f.ProductID, "product", "", "идентификатор товара")
+	fs.StringVar(&from, "from", "", "начало интервала создания отзывов (2006-01-02 или RFC 3339)")
+	fs.StringVar(&to, "to", "", "конец интервала создания отзывов, не включая")
+	fs.StringVar(&f.Model, "model", "", "модель, которой были классифицированы отзывы")
+	fs.StringVar(&f.PromptVersion, "prompt-version", "", "версия запроса, с которой были классифицированы отзывы")
+	fs.StringVar(&f.Language, "language", "", "код языка отзывов, например en")
+	fs.StringVar(&resume, "resume", "", "продолжить прерванный запуск")
+	fs.IntVar(&runner.BatchSize, "batch", reclassify.DefaultBatchSize, "размер пакета")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var err error
+	if f.From, err = parseTime(from); err != nil {
+		return fmt.Errorf("-from: %w", err)
+	}
+	if f.To, err = parseTime(to); err != nil {
+		return fmt.Errorf("-to: %w", err)
+	}
+
+	var run reclassify.Run
+	if resume != "" {
+		run, err = runner.Resume(ctx, resume)
+	} else {
+		run, err = runner.Start(ctx, f)
+	}
+	if err != nil {
+		return err
+	}
+	log.Info().Str("run_id", run.ID).Msg("Запуск переклассификации")
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	run, err = runner.Process(ctx, run)
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintf(os.Stderr, "Прервано после %d отзывов, для продолжения: reviews reclassify -resume %s\n",
+			run.Processed, run.ID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Готово: обработано %d, ошибок %d\n", run.Processed, run.Failed)
+	return nil
+}
+
+// parseTime разбирает дату или время в формате RFC 3339.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
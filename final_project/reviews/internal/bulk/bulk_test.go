@@ -0,0 +1,179 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/moderation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAll читает все строки импорта.
+func readAll(t *testing.T, rd Reader) []Row {
+	t.Helper()
+
+	var rows []Row
+	for {
+		row, err := rd.Read()
+		if err == io.EOF {
+			return rows
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	f, err := Format("", "text/csv; charset=utf-8")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCSV, f)
+
+	f, err = Format(FormatNDJSON, "application/json")
+	require.NoError(t, err)
+	assert.Equal(t, FormatNDJSON, f)
+
+	_, err = Format("", "application/json")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	_, err = Format("xml", "")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestCSVReader(t *testing.T) {
+	data := "\ufeffAuthor,text,rating,created_at,extra\n" +
+		"Анна,\"Хороший чайник, \"\"быстро\"\" греет\",5,2026-01-02T03:04:05Z,x\n" +
+		"Олег,Плохо,пять,,\n" +
+		"Иван,Нормально,3,вчера,\n" +
+		"Петр,Короткая строка\n"
+	rd, err := NewReader(FormatCSV, strings.NewReader(data), "p1")
+	require.NoError(t, err)
+
+	rows := readAll(t, rd)
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, 2, rows[0].Line)
+	require.NoError(t, rows[0].Err)
+	assert.Equal(t, models.Review{
+		ProductID: "p1",
+		Author:    "Анна",
+		Text:      `Хороший чайник, "быстро" греет`,
+		Rating:    5,
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, rows[0].Review)
+
+	assert.ErrorContains(t, rows[1].Err, "оценка")
+	assert.ErrorContains(t, rows[2].Err, "время создания")
+	assert.Equal(t, 5, rows[3].Line)
+	assert.Error(t, rows[3].Err)
+
+	// Без товара в запросе столбец product_id обязателен
+	_, err = NewReader(FormatCSV, strings.NewReader("author,text,rating\n"), "")
+	assert.ErrorContains(t, err, "product_id")
+	_, err = NewReader(FormatCSV, strings.NewReader(""), "p1")
+	assert.Error(t, err)
+}
+
+func TestNDJSONReader(t *testing.T) {
+	data := `{"product_id":"p2","author":"Анна","text":"Хорошо","rating":5}` + "\n" +
+		"\n" +
+		`{"author":"Олег",` + "\n" +
+		`{"author":"Иван","text":"Плохо","rating":1,"id":"x"}` + "\n"
+	rd, err := NewReader(FormatNDJSON, strings.NewReader(data), "p1")
+	require.NoError(t, err)
+
+	rows := readAll(t, rd)
+	require.Len(t, rows, 3)
+	assert.Equal(t, models.Review{ProductID: "p2", Author: "Анна", Text: "Хорошо", Rating: 5}, rows[0].Review)
+	assert.Equal(t, 3, rows[1].Line)
+	assert.ErrorContains(t, rows[1].Err, "JSON")
+	assert.Equal(t, 4, rows[2].Line)
+	assert.Equal(t, "p1", rows[2].Review.ProductID)
+}
+
+func TestImporter(t *testing.T) {
+	m := memdb.New()
+	p, err := m.AddProduct(context.Background(), models.Product{Name: "Чайник"})
+	require.NoError(t, err)
+
+	data := "product_id,author,text,rating\n" +
+		p.ID + ",Анна,Хороший чайник,5\n" +
+		p.ID + ",Олег,,3\n" +
+		"unknown,Иван,Нормально,3\n" +
+		p.ID + ",Петр,Fucking чайник,1\n" +
+		p.ID + ",Мария,\"Звоните +7 912 345-67-89\",4\n" +
+		p.ID + ",Ольга,Отличный,5\n" +
+		p.ID + ",Нина,Неплохо,4\n"
+	rd, err := NewReader(FormatCSV, strings.NewReader(data), "")
+	require.NoError(t, err)
+
+	im := NewImporter(m, moderation.Default())
+	im.ChunkSize = 2
+	im.MaxErrors = 2
+	rep, err := im.Import(context.Background(), rd)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, rep.Imported)
+	assert.Equal(t, 1, rep.Pending)
+	assert.Equal(t, 3, rep.Failed)
+	assert.Equal(t, []RowError{
+		{Line: 3, Error: "не указан текст отзыва"},
+		{Line: 4, Error: "товар не найден"},
+	}, rep.Errors)
+	assert.True(t, rep.Truncated)
+
+	// Отзыв, ожидающий модератора, сохраняется, но не публикуется
+	reviews, err := m.ListReviews(context.Background(), p.ID, "")
+	require.NoError(t, err)
+	assert.Len(t, reviews, 3)
+	queue, err := m.ModerationQueue(context.Background(), 10)
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+	assert.Equal(t, "Мария", queue[0].Author)
+}
+
+func TestWriter(t *testing.T) {
+	m := memdb.New()
+	p, err := m.AddProduct(context.Background(), models.Product{Name: "Чайник"})
+	require.NoError(t, err)
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	_, err = m.ImportReviews(context.Background(), []models.Review{
+		{ProductID: p.ID, Author: "Анна", Text: "Хороший, \"новый\" чайник", Rating: 5, CreatedAt: created},
+		{ProductID: p.ID, Author: "Олег", Text: "Плохо", Rating: 1, CreatedAt: created.Add(-time.Hour)},
+	})
+	require.NoError(t, err)
+
+	for _, format := range []string{FormatCSV, FormatNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			// Выгрузка загружается обратно без изменений
+			var buf bytes.Buffer
+			w, err := NewWriter(format, &buf)
+			require.NoError(t, err)
+			require.NoError(t, m.ExportReviews(context.Background(), db.ReviewFilter{}, w.Write))
+			require.NoError(t, w.Flush())
+
+			rd, err := NewReader(format, &buf, "")
+			require.NoError(t, err)
+			rows := readAll(t, rd)
+			require.Len(t, rows, 2)
+			for _, row := range rows {
+				require.NoError(t, row.Err)
+			}
+			assert.Equal(t, "Олег", rows[0].Review.Author)
+			assert.Equal(t, models.Review{
+				ProductID: p.ID,
+				Author:    "Анна",
+				Text:      "Хороший, \"новый\" чайник",
+				Rating:    5,
+				CreatedAt: created,
+			}, rows[1].Review)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Package bulk импортирует и выгружает отзывы в форматах CSV и NDJSON.
+//
+// Данные читаются и записываются потоком: импорт сохраняет отзывы пакетами
+// по Importer.ChunkSize строк, выгрузка пишет отзывы по мере чтения страниц
+// из хранилища, поэтому объем памяти не зависит от размера файла.
+package bulk
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+)
+
+// Поддерживаемые форматы.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// ErrUnsupportedFormat - формат данных не поддерживается.
+var ErrUnsupportedFormat = errors.New("формат не поддерживается, ожидается csv или ndjson")
+
+// contentTypes - форматы по типу содержимого.
+var contentTypes = map[string]string{
+	"text/csv":             FormatCSV,
+	"application/csv":      FormatCSV,
+	"application/x-ndjson": FormatNDJSON,
+	"application/ndjson":   FormatNDJSON,
+	"application/jsonl":    FormatNDJSON,
+}
+
+// Format определяет формат по явному имени format или, если оно пусто,
+// по заголовку Content-Type.
+func Format(format, contentType string) (string, error) {
+	if format != "" {
+		if format != FormatCSV && format != FormatNDJSON {
+			return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+		}
+		return format, nil
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, contentType)
+	}
+	if f, ok := contentTypes[mt]; ok {
+		return f, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, mt)
+}
+
+// ContentType возвращает тип содержимого формата.
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
@@ -0,0 +1,201 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/language"
+	"go-masters/final_project/reviews/internal/metrics"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/moderation"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Значения Importer по умолчанию.
+const (
+	// DefaultChunkSize - число отзывов, сохраняемых одной транзакцией.
+	DefaultChunkSize = 1000
+	// DefaultMaxErrors - число ошибок строк, попадающих в отчет.
+	DefaultMaxErrors = 1000
+)
+
+// Store - хранилище импортируемых отзывов.
+type Store interface {
+	GetProduct(ctx context.Context, id string) (models.Product, error)
+	ImportReviews(context.Context, []models.Review) ([]models.Review, error)
+}
+
+// RowError - ошибка строки импорта.
+type RowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// Report - итог импорта.
+type Report struct {
+	// Число сохраненных отзывов, в том числе ожидающих модератора.
+	Imported int `json:"imported"`
+	// Число сохраненных отзывов, ожидающих модератора.
+	Pending int `json:"pending"`
+	// Число строк с ошибками.
+	Failed int        `json:"failed"`
+	Errors []RowError `json:"errors"`
+	// Ошибок больше, чем в Errors.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+func (rep *Report) fail(line int, err error) {
+	rep.Failed++
+	if len(rep.Errors) < cap(rep.Errors) {
+		rep.Errors = append(rep.Errors, RowError{Line: line, Error: err.Error()})
+	} else {
+		rep.Truncated = true
+	}
+}
+
+// Importer проверяет строки и сохраняет отзывы пакетами.
+type Importer struct {
+	store Store
+	// Модератор проверяет тексты пакета; nil - без проверки.
+	moderator *moderation.Moderator
+	// ChunkSize - число отзывов в пакете.
+	ChunkSize int
+	// MaxErrors - число ошибок строк в отчете; остальные только считаются.
+	MaxErrors int
+}
+
+// NewImporter создает Importer с размерами по умолчанию.
+func NewImporter(store Store, moderator *moderation.Moderator) *Importer {
+	return &Importer{
+		store:     store,
+		moderator: moderator,
+		ChunkSize: DefaultChunkSize,
+		MaxErrors: DefaultMaxErrors,
+	}
+}
+
+// Import читает строки rd и сохраняет корректные отзывы. Ошибки отдельных
+// строк попадают в отчет; ошибка чтения данных или хранилища прерывает
+// импорт, при этом уже сохраненные пакеты остаются и учтены в отчете.
+func (im *Importer) Import(ctx context.Context, rd Reader) (Report, error) {
+	rep := Report{Errors: make([]RowError, 0, im.MaxErrors)}
+	// Существование товаров; в файле обычно немного разных товаров.
+	products := make(map[string]bool)
+	chunk := make([]Row, 0, im.ChunkSize)
+
+	for {
+		row, err := rd.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return rep, fmt.Errorf("ошибка чтения данных: %w", err)
+		}
+		if row.Err == nil {
+			row.Err = im.validate(ctx, &row.Review, products)
+		}
+		if row.Err != nil {
+			if ctx.Err() != nil {
+				return rep, ctx.Err()
+			}
+			rep.fail(row.Line, row.Err)
+			continue
+		}
+
+		chunk = append(chunk, row)
+		if len(chunk) == im.ChunkSize {
+			if err := im.save(ctx, chunk, &rep); err != nil {
+				return rep, err
+			}
+			chunk = chunk[:0]
+		}
+	}
+	if len(chunk) > 0 {
+		if err := im.save(ctx, chunk, &rep); err != nil {
+			return rep, err
+		}
+	}
+	return rep, nil
+}
+
+// validate нормализует и проверяет отзыв строки.
+func (im *Importer) validate(ctx context.Context, r *models.Review, products map[string]bool) error {
+	r.Author = strings.TrimSpace(r.Author)
+	r.Text = strings.TrimSpace(r.Text)
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	if r.CreatedAt.After(time.Now()) {
+		return errors.New("время создания в будущем")
+	}
+	r.CreatedAt = r.CreatedAt.UTC()
+
+	if r.ProductID == "" {
+		return errors.New("не указан товар")
+	}
+	exists, ok := products[r.ProductID]
+	if !ok {
+		_, err := im.store.GetProduct(ctx, r.ProductID)
+		if err != nil && !errors.Is(err, db.ErrNotFound) {
+			return err
+		}
+		exists = err == nil
+		products[r.ProductID] = exists
+	}
+	if !exists {
+		return errors.New("товар не найден")
+	}
+	return nil
+}
+
+// save проверяет тексты пакета модератором и сохраняет допущенные отзывы.
+// Отклоненные отзывы не сохраняются, как и при создании отзыва через API.
+func (im *Importer) save(ctx context.Context, chunk []Row, rep *Report) error {
+	if im.moderator != nil {
+		texts := make([]string, len(chunk))
+		for i, row := range chunk {
+			texts[i] = row.Review.Text
+		}
+		verdicts := im.moderator.ModerateAll(ctx, texts)
+
+		kept := chunk[:0]
+		for i, v := range verdicts {
+			metrics.ObserveModeration(models.AutoModerator, v.Status)
+			if v.Status == models.ReviewRejected {
+				rep.fail(chunk[i].Line, fmt.Errorf("отзыв нарушает правила публикации: %s", strings.Join(v.Reasons, ", ")))
+				continue
+			}
+			row := chunk[i]
+			row.Review.Moderation = v.Moderation()
+			kept = append(kept, row)
+		}
+		chunk = kept
+		if len(chunk) == 0 {
+			return nil
+		}
+	}
+
+	reviews := make([]models.Review, len(chunk))
+	for i, row := range chunk {
+		reviews[i] = row.Review
+		reviews[i].Language = language.Detect(row.Review.Text)
+	}
+	saved, err := im.store.ImportReviews(ctx, reviews)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения отзывов: %w", err)
+	}
+	for _, r := range saved {
+		rep.Imported++
+		if r.Moderation.Status == models.ReviewPending {
+			rep.Pending++
+		}
+	}
+	log.Debug().Int("reviews", len(saved)).Msg("Пакет отзывов импортирован")
+	return nil
+}
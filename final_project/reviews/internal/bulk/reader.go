@@ -0,0 +1,189 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-masters/final_project/reviews/internal/models"
+)
+
+// maxLineLen - максимальная длина строки NDJSON.
+const maxLineLen = 1 << 20
+
+// Row - строка импортируемых данных.
+type Row struct {
+	// Номер строки входных данных, начиная с 1.
+	Line   int
+	Review models.Review
+	// Ошибка разбора строки; остальные строки при этом импортируются.
+	Err error
+}
+
+// Reader читает строки импорта. В конце данных возвращает io.EOF;
+// другие ошибки (например, чтения тела запроса) прерывают импорт.
+type Reader interface {
+	Read() (Row, error)
+}
+
+// NewReader создает Reader формата format. productID - товар строк,
+// в которых он не указан.
+func NewReader(format string, r io.Reader, productID string) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r, productID)
+	case FormatNDJSON:
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 0, 64*1024), maxLineLen)
+		return &ndjsonReader{sc: sc, productID: productID}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+}
+
+// Столбцы CSV. Лишние столбцы (например, из выгрузки) пропускаются.
+const (
+	colProductID = "product_id"
+	colAuthor    = "author"
+	colText      = "text"
+	colRating    = "rating"
+	colCreatedAt = "created_at"
+)
+
+// csvReader читает CSV с заголовком.
+type csvReader struct {
+	r         *csv.Reader
+	productID string
+	// Номера столбцов по имени.
+	cols map[string]int
+}
+
+func newCSVReader(r io.Reader, productID string) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("нет заголовка CSV")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения заголовка CSV: %w", err)
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		// Первый столбец может начинаться с BOM, который добавляет Excel.
+		name = strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")
+		cols[strings.ToLower(name)] = i
+	}
+	required := []string{colAuthor, colText, colRating}
+	if productID == "" {
+		required = append(required, colProductID)
+	}
+	for _, name := range required {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("нет столбца %s", name)
+		}
+	}
+	return &csvReader{r: cr, productID: productID, cols: cols}, nil
+}
+
+func (c *csvReader) Read() (Row, error) {
+	rec, err := c.r.Read()
+	if err == io.EOF {
+		return Row{}, io.EOF
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return Row{Line: parseErr.StartLine, Err: parseErr.Err}, nil
+	}
+	if err != nil {
+		return Row{}, err
+	}
+
+	field := func(name string) string {
+		i, ok := c.cols[name]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return rec[i]
+	}
+	line, _ := c.r.FieldPos(0)
+	row := Row{Line: line}
+	row.Review, row.Err = parseRow(field(colProductID), c.productID, field(colAuthor), field(colText), field(colRating), field(colCreatedAt))
+	return row, nil
+}
+
+// parseRow собирает отзыв из значений столбцов CSV.
+func parseRow(productID, defaultProductID, author, text, rating, createdAt string) (models.Review, error) {
+	r := models.Review{ProductID: strings.TrimSpace(productID), Author: author, Text: text}
+	if r.ProductID == "" {
+		r.ProductID = defaultProductID
+	}
+
+	var err error
+	if r.Rating, err = strconv.Atoi(strings.TrimSpace(rating)); err != nil {
+		return r, fmt.Errorf("некорректная оценка %q", rating)
+	}
+	if createdAt = strings.TrimSpace(createdAt); createdAt != "" {
+		if r.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+			return r, fmt.Errorf("некорректное время создания %q", createdAt)
+		}
+	}
+	return r, nil
+}
+
+// ndjsonReader читает по объекту JSON в строке. Пустые строки пропускаются.
+type ndjsonReader struct {
+	sc        *bufio.Scanner
+	productID string
+	line      int
+}
+
+// ndjsonRow - поля отзыва в строке NDJSON; остальные поля пропускаются.
+type ndjsonRow struct {
+	ProductID string    `json:"product_id"`
+	Author    string    `json:"author"`
+	Text      string    `json:"text"`
+	Rating    int       `json:"rating"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (n *ndjsonReader) Read() (Row, error) {
+	for n.sc.Scan() {
+		n.line++
+		b := n.sc.Bytes()
+		if len(bytes.TrimSpace(b)) == 0 {
+			continue
+		}
+
+		var v ndjsonRow
+		if err := json.Unmarshal(b, &v); err != nil {
+			return Row{Line: n.line, Err: fmt.Errorf("некорректный JSON: %w", err)}, nil
+		}
+		r := models.Review{
+			ProductID: v.ProductID,
+			Author:    v.Author,
+			Text:      v.Text,
+			Rating:    v.Rating,
+			CreatedAt: v.CreatedAt,
+		}
+		if r.ProductID == "" {
+			r.ProductID = n.productID
+		}
+		return Row{Line: n.line, Review: r}, nil
+	}
+	if err := n.sc.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return Row{}, fmt.Errorf("строка %d длиннее %d байт", n.line+1, maxLineLen)
+		}
+		return Row{}, err
+	}
+	return Row{}, io.EOF
+}
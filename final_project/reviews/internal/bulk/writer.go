@@ -0,0 +1,81 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"go-masters/final_project/reviews/internal/models"
+)
+
+// Writer записывает выгружаемые отзывы.
+type Writer interface {
+	Write(models.Review) error
+	// Flush записывает буферизованные данные в нижележащий io.Writer.
+	Flush() error
+}
+
+// NewWriter создает Writer формата format. Заголовок CSV записывается сразу.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: cw}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+}
+
+// csvHeader - столбцы выгрузки CSV. Выгрузку можно загрузить обратно:
+// импорт читает product_id, author, text, rating и created_at.
+var csvHeader = []string{
+	"id", colProductID, colAuthor, colText, colRating, "language", "status",
+	"sentiment_label", "sentiment_confidence", colCreatedAt, "updated_at",
+}
+
+type csvWriter struct {
+	w   *csv.Writer
+	rec [11]string
+}
+
+func (c *csvWriter) Write(r models.Review) error {
+	c.rec = [...]string{
+		r.ID,
+		r.ProductID,
+		r.Author,
+		r.Text,
+		strconv.Itoa(r.Rating),
+		r.Language,
+		r.Moderation.Status,
+		r.Sentiment.Label,
+		strconv.FormatFloat(r.Sentiment.Confidence, 'f', -1, 64),
+		r.CreatedAt.Format(time.RFC3339Nano),
+		r.UpdatedAt.Format(time.RFC3339Nano),
+	}
+	return c.w.Write(c.rec[:])
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ndjsonWriter пишет отзыв в формате JSON ответов API, по одному в строке.
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(r models.Review) error {
+	return n.enc.Encode(r)
+}
+
+func (*ndjsonWriter) Flush() error {
+	return nil
+}
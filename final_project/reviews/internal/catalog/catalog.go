@@ -0,0 +1,135 @@
+// Package catalog сводит рейтинги товаров в рейтинги категорий.
+//
+// Рейтинг категории вычисляется по агрегатам рейтинга (rating.Aggregate)
+// товаров категории и всех ее подкатегорий, как если бы все их отзывы
+// относились к одному товару. Сводка по всему каталогу вычисляется одним
+// проходом и кэшируется (Cache из 03-generics). Кэш сбрасывается при
+// изменении рейтингов товаров: хранилища очереди классификации и
+// переклассификации, обернутые Jobs и Reclassify, вызывают Invalidate
+// после сохранения результатов, а сервер - после изменения отзывов, товаров
+// и категорий. Изменения, сделанные другими экземплярами сервиса, видны
+// по истечении времени жизни кэша.
+package catalog
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	generics "go-masters/03-generics"
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/rating"
+)
+
+// DefaultTTL - время жизни сводки по умолчанию.
+const DefaultTTL = time.Minute
+
+// Store - хранилище категорий и агрегатов рейтинга.
+type Store interface {
+	ListCategories(context.Context) ([]models.Category, error)
+	CategoryAggregates(context.Context) (map[string]db.CategoryAggregate, error)
+}
+
+// Ratings вычисляет рейтинги категорий.
+type Ratings struct {
+	store Store
+	// Сводки по поколению кэша. Invalidate начинает новое поколение,
+	// поэтому сводка, вычисленная одновременно со сбросом, не будет прочитана.
+	cache *generics.Cache[uint64, map[string]models.CategoryRating]
+	gen   atomic.Uint64
+}
+
+// NewRatings создает Ratings со временем жизни сводки ttl.
+func NewRatings(store Store, ttl time.Duration) *Ratings {
+	return &Ratings{
+		store: store,
+		cache: generics.NewCache[uint64, map[string]models.CategoryRating](ttl),
+	}
+}
+
+// Get возвращает рейтинг категории с рейтингами подкатегорий.
+// Если категория не существует, возвращает db.ErrNotFound.
+func (r *Ratings) Get(ctx context.Context, categoryID string) (models.CategoryRating, error) {
+	gen := r.gen.Load()
+	all, ok := r.cache.Get(gen)
+	if !ok {
+		var err error
+		all, err = r.compute(ctx, time.Now())
+		if err != nil {
+			return models.CategoryRating{}, err
+		}
+		r.cache.Set(gen, all)
+	}
+
+	res, ok := all[categoryID]
+	if !ok {
+		return models.CategoryRating{}, db.ErrNotFound
+	}
+	return res, nil
+}
+
+// Invalidate сбрасывает кэш сводки.
+func (r *Ratings) Invalidate() {
+	r.cache.Delete(r.gen.Add(1) - 1)
+}
+
+// compute вычисляет рейтинги всех категорий на момент now.
+func (r *Ratings) compute(ctx context.Context, now time.Time) (map[string]models.CategoryRating, error) {
+	categories, err := r.store.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aggs, err := r.store.CategoryAggregates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Подкатегории по родителю в порядке названий.
+	children := make(map[string][]models.Category)
+	for _, c := range categories {
+		children[c.ParentID] = append(children[c.ParentID], c)
+	}
+	for _, cs := range children {
+		slices.SortStableFunc(cs, func(a, b models.Category) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+	}
+
+	res := make(map[string]models.CategoryRating, len(categories))
+	// rollup сводит поддерево категории c. Хранилище не допускает циклов,
+	// поэтому обход начинается с категорий верхнего уровня.
+	var rollup func(c models.Category) db.CategoryAggregate
+	rollup = func(c models.Category) db.CategoryAggregate {
+		total := aggs[c.ID]
+		var subs []models.CategoryRating
+		for _, child := range children[c.ID] {
+			a := rollup(child)
+			total.Products += a.Products
+			total.Rating = total.Rating.Add(a.Rating)
+			subs = append(subs, res[child.ID])
+		}
+		res[c.ID] = compute(c, total, subs, now)
+		return total
+	}
+	for _, c := range children[""] {
+		rollup(c)
+	}
+	return res, nil
+}
+
+// compute вычисляет рейтинг категории по сводному агрегату.
+func compute(c models.Category, a db.CategoryAggregate, subs []models.CategoryRating, now time.Time) models.CategoryRating {
+	pr := rating.Compute(c.ID, a.Rating, now)
+	return models.CategoryRating{
+		CategoryID:    c.ID,
+		Name:          c.Name,
+		Score:         pr.Score,
+		Reviews:       pr.Reviews,
+		Distribution:  pr.Distribution,
+		Products:      a.Products,
+		Subcategories: subs,
+	}
+}
@@ -0,0 +1,80 @@
+package catalog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/sentiment/lexicon"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRatings(t *testing.T) {
+	ctx := context.Background()
+	m := memdb.New()
+
+	addCategory := func(name, parentID string) models.Category {
+		c, err := m.AddCategory(ctx, models.Category{Name: name, ParentID: parentID})
+		require.NoError(t, err)
+		return c
+	}
+	addProduct := func(categoryID, text string) {
+		p, err := m.AddProduct(ctx, models.Product{Name: "Товар", CategoryID: categoryID})
+		require.NoError(t, err)
+		if text != "" {
+			_, err = m.AddReview(ctx, models.Review{ProductID: p.ID, Author: "Иван", Text: text, Rating: 3})
+			require.NoError(t, err)
+		}
+	}
+
+	appliances := addCategory("Техника", "")
+	kettles := addCategory("Чайники", appliances.ID)
+	irons := addCategory("Утюги", appliances.ID)
+	addProduct(kettles.ID, "Отличный чайник, рекомендую")
+	addProduct(kettles.ID, "")
+	addProduct(irons.ID, "Ужасный утюг, сломался")
+
+	r := NewRatings(m, time.Hour)
+	// Очередь классификации сбрасывает кэш после сохранения результатов.
+	pool := jobs.NewPool(r.Jobs(m), lexicon.New())
+
+	res, err := r.Get(ctx, appliances.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Products)
+	assert.Zero(t, res.Reviews)
+	assert.Equal(t, 3.0, res.Score)
+
+	n, err := pool.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	res, err = r.Get(ctx, appliances.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Reviews)
+	assert.Equal(t, models.Distribution{Positive: 1, Negative: 1}, res.Distribution)
+
+	// Подкатегории в порядке названий
+	require.Len(t, res.Subcategories, 2)
+	assert.Equal(t, "Утюги", res.Subcategories[0].Name)
+	assert.Equal(t, 1, res.Subcategories[0].Products)
+	assert.Equal(t, models.Distribution{Negative: 1}, res.Subcategories[0].Distribution)
+	assert.Equal(t, "Чайники", res.Subcategories[1].Name)
+	assert.Equal(t, 2, res.Subcategories[1].Products)
+	assert.Greater(t, res.Subcategories[1].Score, res.Score)
+	assert.Less(t, res.Subcategories[0].Score, res.Score)
+
+	// Без сброса кэш не видит новую категорию
+	empty := addCategory("Пылесосы", appliances.ID)
+	_, err = r.Get(ctx, empty.ID)
+	assert.ErrorIs(t, err, db.ErrNotFound)
+	r.Invalidate()
+	res, err = r.Get(ctx, empty.ID)
+	require.NoError(t, err)
+	assert.Zero(t, res.Products)
+}
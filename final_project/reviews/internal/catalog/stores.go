@@ -0,0 +1,47 @@
+package catalog
+
+import (
+	"context"
+	"time"
+
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/reclassify"
+)
+
+// Jobs оборачивает хранилище очереди классификации: сохранение
+// результата классификации сбрасывает кэш сводки.
+func (r *Ratings) Jobs(s jobs.Store) jobs.Store {
+	return jobStore{Store: s, ratings: r}
+}
+
+type jobStore struct {
+	jobs.Store
+	ratings *Ratings
+}
+
+func (s jobStore) CompleteJob(ctx context.Context, job jobs.Job, sent models.Sentiment) error {
+	defer s.ratings.Invalidate()
+	return s.Store.CompleteJob(ctx, job, sent)
+}
+
+func (s jobStore) FailJob(ctx context.Context, job jobs.Job, reason string) error {
+	defer s.ratings.Invalidate()
+	return s.Store.FailJob(ctx, job, reason)
+}
+
+// Reclassify оборачивает хранилище переклассификации: сохранение
+// пакета результатов сбрасывает кэш сводки.
+func (r *Ratings) Reclassify(s reclassify.Store) reclassify.Store {
+	return reclassifyStore{Store: s, ratings: r}
+}
+
+type reclassifyStore struct {
+	reclassify.Store
+	ratings *Ratings
+}
+
+func (s reclassifyStore) SaveBatch(ctx context.Context, run reclassify.Run, lease time.Duration, updates []reclassify.Update) error {
+	defer s.ratings.Invalidate()
+	return s.Store.SaveBatch(ctx, run, lease, updates)
+}
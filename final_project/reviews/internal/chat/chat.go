@@ -0,0 +1,90 @@
+// Package chat реализует диалоги с LLM, сохраняемые между запросами.
+//
+// Сессия хранит системный запрос и историю сообщений. При каждом новом
+// сообщении модели передается системный запрос и столько последних сообщений
+// истории, сколько помещается в контекстное окно по оценке числа токенов.
+// Вопрос и ответ сохраняются вместе после завершения генерации, поэтому
+// прерванная генерация не оставляет в истории вопрос без ответа.
+package chat
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// Роли сообщений, как в api.Message.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// MaxMessageLen - максимальная длина сообщения пользователя в символах.
+const MaxMessageLen = 8000
+
+var (
+	// ErrInvalidMessage - пустое или слишком длинное сообщение.
+	ErrInvalidMessage = errors.New("некорректное сообщение")
+	// ErrConflict - сессия изменилась во время генерации ответа.
+	ErrConflict = errors.New("сессия изменена другим запросом")
+)
+
+// Message - сообщение диалога.
+type Message struct {
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Session - диалог с моделью.
+type Session struct {
+	ID string `json:"id"`
+	// Модель, с которой начат диалог; продолжается с ней же.
+	Model     string    `json:"model"`
+	System    string    `json:"system,omitempty"`
+	Messages  []Message `json:"messages"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store - хранилище сессий.
+type Store interface {
+	// CreateSession сохраняет новую сессию без сообщений.
+	CreateSession(ctx context.Context, s Session) (Session, error)
+	GetSession(ctx context.Context, id string) (Session, error)
+	// AppendMessages добавляет сообщения в конец истории, если в ней
+	// по-прежнему ровно seen сообщений, иначе возвращает ErrConflict.
+	AppendMessages(ctx context.Context, id string, seen int, msgs ...Message) error
+}
+
+// messageOverhead - оценка служебных токенов на одно сообщение
+// (роль и разделители шаблона модели).
+const messageOverhead = 4
+
+// EstimateTokens оценивает число токенов сообщения. Точное число зависит
+// от токенизатора модели; для смеси русского и английского текста один
+// токен в среднем соответствует примерно трем символам.
+func EstimateTokens(content string) int {
+	return (utf8.RuneCountInString(content)+2)/3 + messageOverhead
+}
+
+// Trim возвращает последние сообщения истории, суммарная оценка токенов
+// которых не превышает budget. Контекст начинается с сообщения
+// пользователя: ответ без вопроса только сбивает модель.
+func Trim(history []Message, budget int) []Message {
+	start := len(history)
+	for start > 0 {
+		cost := EstimateTokens(history[start-1].Content)
+		if cost > budget {
+			break
+		}
+		budget -= cost
+		start--
+	}
+	for start < len(history) && history[start].Role != RoleUser {
+		start++
+	}
+	return history[start:]
+}
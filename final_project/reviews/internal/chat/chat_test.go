@@ -0,0 +1,46 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, messageOverhead, EstimateTokens(""))
+	assert.Equal(t, 1+messageOverhead, EstimateTokens("да"))
+	// Оценка считает символы, а не байты
+	assert.Equal(t, EstimateTokens("hello"), EstimateTokens("приве"))
+}
+
+func TestTrim(t *testing.T) {
+	msg := func(role string) Message {
+		// 30 символов - 10 токенов и служебные токены
+		return Message{Role: role, Content: strings.Repeat("a", 30)}
+	}
+	cost := EstimateTokens(strings.Repeat("a", 30))
+	history := []Message{msg(RoleUser), msg(RoleAssistant), msg(RoleUser), msg(RoleAssistant), msg(RoleUser)}
+
+	tests := []struct {
+		name   string
+		budget int
+		want   int
+	}{
+		{name: "вся история", budget: 5 * cost, want: 5},
+		{name: "без первого вопроса", budget: 4 * cost, want: 3},
+		{name: "ответ без вопроса", budget: 3*cost - 1, want: 1},
+		{name: "только вопрос", budget: cost, want: 1},
+		{name: "не помещается", budget: cost - 1, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Trim(history, tt.budget)
+			assert.Len(t, got, tt.want)
+			if len(got) > 0 {
+				assert.Equal(t, RoleUser, got[0].Role)
+				assert.Equal(t, history[len(history)-1], got[len(got)-1])
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-masters/final_project/reviews/internal/llm"
+
+	"github.com/ollama/ollama/api"
+	"github.com/rs/zerolog/log"
+)
+
+// Параметры по умолчанию.
+const (
+	DefaultContextTokens = 4096
+	DefaultReplyTokens   = 512
+)
+
+// Config - настройки диалогов.
+type Config struct {
+	// Модель новых сессий.
+	Model string
+	// Системный запрос сессий, для которых он не указан.
+	SystemPrompt string
+	// Размер контекстного окна модели в токенах (параметр num_ctx).
+	ContextTokens int
+	// Часть окна, оставляемая под ответ (параметр num_predict).
+	ReplyTokens int
+}
+
+// Reply - ответ модели на сообщение.
+type Reply struct {
+	Message Message `json:"message"`
+	// Число сообщений истории, переданных модели.
+	ContextMessages int `json:"context_messages"`
+	// Число токенов запроса и ответа по данным Ollama.
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+	DoneReason      string `json:"done_reason"`
+}
+
+// Service ведет диалоги с моделью.
+type Service struct {
+	store Store
+	llm   *llm.Client
+	cfg   Config
+}
+
+func New(store Store, client *llm.Client, cfg Config) *Service {
+	if cfg.ContextTokens <= 0 {
+		cfg.ContextTokens = DefaultContextTokens
+	}
+	if cfg.ReplyTokens <= 0 || cfg.ReplyTokens >= cfg.ContextTokens {
+		cfg.ReplyTokens = min(DefaultReplyTokens, cfg.ContextTokens/2)
+	}
+	return &Service{store: store, llm: client, cfg: cfg}
+}
+
+// Start создает сессию с системным запросом system
+// или системным запросом по умолчанию, если он пуст.
+func (s *Service) Start(ctx context.Context, system string) (Session, error) {
+	system = strings.TrimSpace(system)
+	if system == "" {
+		system = s.cfg.SystemPrompt
+	}
+	// Системный запрос передается в каждом вызове и должен оставлять
+	// место хотя бы для одного сообщения.
+	if EstimateTokens(system) > s.budget()/2 {
+		return Session{}, fmt.Errorf("%w: системный запрос слишком длинный", ErrInvalidMessage)
+	}
+	return s.store.CreateSession(ctx, Session{Model: s.cfg.Model, System: system})
+}
+
+// Get возвращает сессию с историей сообщений.
+func (s *Service) Get(ctx context.Context, id string) (Session, error) {
+	return s.store.GetSession(ctx, id)
+}
+
+// Send отправляет сообщение пользователя в сессию id и возвращает ответ
+// модели. fn вызывается для каждого фрагмента ответа по мере генерации;
+// ошибка fn прерывает генерацию. Вопрос и ответ сохраняются в истории
+// только после успешного завершения генерации.
+func (s *Service) Send(ctx context.Context, id, content string, fn func(chunk string) error) (Reply, error) {
+	if strings.TrimSpace(content) == "" {
+		return Reply{}, fmt.Errorf("%w: пустое сообщение", ErrInvalidMessage)
+	}
+	if utf8.RuneCountInString(content) > MaxMessageLen {
+		return Reply{}, fmt.Errorf("%w: сообщение длиннее %d символов", ErrInvalidMessage, MaxMessageLen)
+	}
+
+	sess, err := s.store.GetSession(ctx, id)
+	if err != nil {
+		return Reply{}, err
+	}
+
+	question := Message{Role: RoleUser, Content: content, CreatedAt: time.Now().UTC()}
+	history := Trim(append(sess.Messages, question), s.budget()-EstimateTokens(sess.System))
+	if len(history) == 0 {
+		return Reply{}, fmt.Errorf("%w: сообщение не помещается в контекст модели", ErrInvalidMessage)
+	}
+	if dropped := len(sess.Messages) + 1 - len(history); dropped > 0 {
+		log.Debug().Str("session", sess.ID).Int("dropped", dropped).Msg("История диалога сокращена")
+	}
+
+	req := &api.ChatRequest{
+		Model:    sess.Model,
+		Messages: make([]api.Message, 0, len(history)+1),
+		Options: map[string]any{
+			"num_ctx":     s.cfg.ContextTokens,
+			"num_predict": s.cfg.ReplyTokens,
+		},
+	}
+	if sess.System != "" {
+		req.Messages = append(req.Messages, api.Message{Role: RoleSystem, Content: sess.System})
+	}
+	for _, m := range history {
+		req.Messages = append(req.Messages, api.Message{Role: m.Role, Content: m.Content})
+	}
+
+	reply := Reply{ContextMessages: len(history)}
+	var answer strings.Builder
+	err = s.llm.Chat(ctx, req, func(resp api.ChatResponse) error {
+		if resp.Message.Content != "" {
+			answer.WriteString(resp.Message.Content)
+			if err := fn(resp.Message.Content); err != nil {
+				return err
+			}
+		}
+		if resp.Done {
+			reply.PromptEvalCount = resp.PromptEvalCount
+			reply.EvalCount = resp.EvalCount
+			reply.DoneReason = resp.DoneReason
+		}
+		return nil
+	})
+	if err != nil {
+		return Reply{}, err
+	}
+
+	reply.Message = Message{Role: RoleAssistant, Content: answer.String(), CreatedAt: time.Now().UTC()}
+	if err := s.store.AppendMessages(ctx, sess.ID, len(sess.Messages), question, reply.Message); err != nil {
+		return Reply{}, err
+	}
+	return reply, nil
+}
+
+// budget возвращает число токенов, доступных для запроса.
+func (s *Service) budget() int {
+	return s.cfg.ContextTokens - s.cfg.ReplyTokens
+}
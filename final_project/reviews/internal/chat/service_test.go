@@ -0,0 +1,152 @@
+package chat_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go-masters/final_project/reviews/internal/chat"
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/llm"
+	"go-masters/final_project/reviews/internal/llm/ollamatest"
+
+	"github.com/ollama/ollama/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newService(t *testing.T, fn ollamatest.ChatFunc, cfg chat.Config) (*chat.Service, *memdb.MemDB, *ollamatest.Server) {
+	t.Helper()
+
+	srv := ollamatest.NewServer(func(api.GenerateRequest) (string, error) {
+		return "", errors.New("unexpected generate")
+	})
+	srv.HandleChat(fn)
+	t.Cleanup(srv.Close)
+
+	client, err := llm.New(llm.Config{Endpoint: srv.URL})
+	require.NoError(t, err)
+
+	m := memdb.New()
+	cfg.Model = "test"
+	return chat.New(m, client, cfg), m, srv
+}
+
+// send отправляет сообщение и возвращает ответ, собранный из фрагментов.
+func send(t *testing.T, s *chat.Service, id, content string) (chat.Reply, string) {
+	t.Helper()
+
+	var streamed strings.Builder
+	reply, err := s.Send(context.Background(), id, content, func(chunk string) error {
+		streamed.WriteString(chunk)
+		return nil
+	})
+	require.NoError(t, err)
+	return reply, streamed.String()
+}
+
+func TestService_Send(t *testing.T) {
+	s, _, srv := newService(t, func(req api.ChatRequest) (string, error) {
+		return "ответ " + req.Messages[len(req.Messages)-1].Content, nil
+	}, chat.Config{SystemPrompt: "Отвечай кратко"})
+	ctx := context.Background()
+
+	sess, err := s.Start(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Отвечай кратко", sess.System)
+	assert.Equal(t, "test", sess.Model)
+
+	reply, streamed := send(t, s, sess.ID, "первый")
+	assert.Equal(t, "ответ первый", reply.Message.Content)
+	assert.Equal(t, reply.Message.Content, streamed)
+	assert.Equal(t, 1, reply.ContextMessages)
+	assert.Equal(t, 2, reply.EvalCount)
+
+	send(t, s, sess.ID, "второй")
+
+	// Второй запрос содержит системный запрос и всю историю диалога
+	reqs := srv.ChatRequests()
+	require.Len(t, reqs, 2)
+	var roles, contents []string
+	for _, m := range reqs[1].Messages {
+		roles = append(roles, m.Role)
+		contents = append(contents, m.Content)
+	}
+	assert.Equal(t, []string{"system", "user", "assistant", "user"}, roles)
+	assert.Equal(t, []string{"Отвечай кратко", "первый", "ответ первый", "второй"}, contents)
+
+	// Диалог продолжается по идентификатору сессии
+	got, err := s.Get(ctx, sess.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Messages, 4)
+	assert.Equal(t, chat.RoleAssistant, got.Messages[3].Role)
+	assert.Equal(t, "ответ второй", got.Messages[3].Content)
+}
+
+func TestService_TrimContext(t *testing.T) {
+	s, _, srv := newService(t, func(req api.ChatRequest) (string, error) {
+		return strings.Repeat("б", 30), nil
+	}, chat.Config{ContextTokens: 100, ReplyTokens: 40})
+	ctx := context.Background()
+
+	sess, err := s.Start(ctx, "")
+	require.NoError(t, err)
+
+	// Каждое сообщение - 14 токенов, в окно 60 токенов помещаются 4
+	for range 5 {
+		send(t, s, sess.ID, strings.Repeat("а", 30))
+	}
+
+	reqs := srv.ChatRequests()
+	last := reqs[len(reqs)-1]
+	assert.Len(t, last.Messages, 3)
+	assert.Equal(t, chat.RoleUser, last.Messages[0].Role)
+	assert.EqualValues(t, 100, last.Options["num_ctx"])
+	assert.EqualValues(t, 40, last.Options["num_predict"])
+
+	// История сохраняется полностью
+	got, err := s.Get(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.Len(t, got.Messages, 10)
+}
+
+func TestService_Errors(t *testing.T) {
+	s, m, _ := newService(t, func(req api.ChatRequest) (string, error) {
+		if req.Messages[len(req.Messages)-1].Content == "ошибка" {
+			return "", errors.New("model not found")
+		}
+		return "ок", nil
+	}, chat.Config{ContextTokens: 100, ReplyTokens: 40})
+	ctx := context.Background()
+
+	sess, err := s.Start(ctx, "")
+	require.NoError(t, err)
+
+	noop := func(string) error { return nil }
+
+	_, err = s.Send(ctx, "unknown", "привет", noop)
+	assert.ErrorIs(t, err, db.ErrNotFound)
+
+	_, err = s.Send(ctx, sess.ID, " ", noop)
+	assert.ErrorIs(t, err, chat.ErrInvalidMessage)
+
+	_, err = s.Send(ctx, sess.ID, strings.Repeat("а", 300), noop)
+	assert.ErrorIs(t, err, chat.ErrInvalidMessage)
+
+	// Вопрос без ответа не сохраняется
+	_, err = s.Send(ctx, sess.ID, "ошибка", noop)
+	assert.Error(t, err)
+
+	// Ответ на устаревшую историю не сохраняется
+	_, err = s.Send(ctx, sess.ID, "привет", func(string) error {
+		return m.AppendMessages(ctx, sess.ID, 0, chat.Message{Role: chat.RoleUser, Content: "параллельный"})
+	})
+	assert.ErrorIs(t, err, chat.ErrConflict)
+
+	got, err := s.Get(ctx, sess.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Messages, 1)
+	assert.Equal(t, "параллельный", got.Messages[0].Content)
+}
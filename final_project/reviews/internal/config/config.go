@@ -0,0 +1,280 @@
+package config
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Поддерживаемые классификаторы настроения.
+const (
+	ClassifierLexicon = "lexicon"
+	ClassifierOllama  = "ollama"
+)
+
+// Поддерживаемые хранилища.
+const (
+	StorageMemory   = "memory"
+	StoragePostgres = "postgres"
+)
+
+type Cfg struct {
+	Port string `mapstructure:"port"`
+	// Хранилище: memory или postgres.
+	Storage   string `mapstructure:"storage"`
+	DBConnStr string `mapstructure:"db_conn_str"`
+	// Адрес OTLP коллектора трейсов.
+	OTelEndpoint string `mapstructure:"otel_endpoint"`
+	// Токен административного API (/admin). Если не задан, API отключен.
+	AdminToken string `mapstructure:"admin_token"`
+	// Клиент Ollama.
+	LLM LLM `mapstructure:"llm"`
+	// Классификация настроения отзывов.
+	Sentiment Sentiment `mapstructure:"sentiment"`
+	// Диалоги с LLM.
+	Chat Chat `mapstructure:"chat"`
+	// Поиск похожих отзывов.
+	Similarity Similarity `mapstructure:"similarity"`
+	// Модерация отзывов.
+	Moderation Moderation `mapstructure:"moderation"`
+	// Каталог товаров.
+	Catalog Catalog `mapstructure:"catalog"`
+	// Голоса за полезность отзывов.
+	Votes Votes `mapstructure:"votes"`
+	// Отслеживание падения рейтинга товаров.
+	Trend Trend `mapstructure:"trend"`
+	// Уведомления о событиях через webhooks.
+	Webhooks Webhooks `mapstructure:"webhooks"`
+}
+
+// LLM - настройки клиента Ollama.
+type LLM struct {
+	URL     string        `mapstructure:"url"`
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Модель эндпоинта генерации /llm/generate.
+	Model string `mapstructure:"model"`
+	// Максимальное число одновременных вызовов.
+	MaxConcurrent int `mapstructure:"max_concurrent"`
+	// Число ошибок подряд, после которого вызовы отключаются на BreakerCooldown.
+	BreakerThreshold int           `mapstructure:"breaker_threshold"`
+	BreakerCooldown  time.Duration `mapstructure:"breaker_cooldown"`
+}
+
+// Sentiment - настройки классификатора настроения и очереди классификации.
+type Sentiment struct {
+	// Классификатор: lexicon или ollama.
+	Classifier string `mapstructure:"classifier"`
+	// Использовать словарный классификатор при ошибке LLM.
+	Fallback bool   `mapstructure:"fallback"`
+	Model    string `mapstructure:"model"`
+	// Имя и версия шаблона запроса (см. internal/prompts);
+	// версия latest означает последнюю версию.
+	PromptName    string `mapstructure:"prompt_name"`
+	PromptVersion string `mapstructure:"prompt_version"`
+	// Каталог с шаблонами <имя>/<версия>.tmpl вместо встроенных.
+	PromptDir string        `mapstructure:"prompt_dir"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+	// Число воркеров очереди классификации.
+	Workers int `mapstructure:"workers"`
+	// Число попыток, после которого задание попадает в dead-letter.
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// Классификаторы отзывов на отдельных языках по коду языка
+	// (ISO 639-1); отзывы на остальных языках классифицируются
+	// классификатором по умолчанию.
+	Languages map[string]SentimentLanguage `mapstructure:"languages"`
+}
+
+// SentimentLanguage - настройки классификации отзывов на одном языке.
+// Незаданные поля берутся из настроек Sentiment; если задано только
+// имя шаблона, используется его последняя версия.
+type SentimentLanguage struct {
+	Classifier    string `mapstructure:"classifier"`
+	Model         string `mapstructure:"model"`
+	PromptName    string `mapstructure:"prompt_name"`
+	PromptVersion string `mapstructure:"prompt_version"`
+}
+
+// ForLanguage возвращает настройки классификации отзывов на языке code.
+func (s Sentiment) ForLanguage(code string) Sentiment {
+	l, ok := s.Languages[code]
+	if !ok {
+		return s
+	}
+	if l.Classifier != "" {
+		s.Classifier = l.Classifier
+	}
+	if l.Model != "" {
+		s.Model = l.Model
+	}
+	if l.PromptName != "" {
+		s.PromptName = l.PromptName
+		s.PromptVersion = l.PromptVersion
+	} else if l.PromptVersion != "" {
+		s.PromptVersion = l.PromptVersion
+	}
+	return s
+}
+
+// Chat - настройки диалогов с LLM. Модель задается в LLM.Model.
+type Chat struct {
+	// Системный запрос сессий, для которых он не указан.
+	SystemPrompt string `mapstructure:"system_prompt"`
+	// Размер контекстного окна модели в токенах.
+	ContextTokens int `mapstructure:"context_tokens"`
+	// Часть окна, оставляемая под ответ.
+	ReplyTokens int `mapstructure:"reply_tokens"`
+}
+
+// Similarity - настройки индексации отзывов для поиска похожих.
+type Similarity struct {
+	// Вычислять векторы новых отзывов.
+	Enabled bool `mapstructure:"enabled"`
+	// Модель Ollama для вычисления векторов.
+	Model string `mapstructure:"model"`
+	// Сходство, начиная с которого отзыв считается копией.
+	Threshold float64 `mapstructure:"threshold"`
+}
+
+// Moderation - настройки модерации отзывов. Проверки персональных данных
+// и словаря выполняются всегда.
+type Moderation struct {
+	// Проверять отзывы моделью LLM после словарных проверок.
+	LLM bool `mapstructure:"llm"`
+	// Модель Ollama; по умолчанию LLM.Model.
+	Model string `mapstructure:"model"`
+	// Версия шаблона запроса moderation (см. internal/prompts).
+	PromptVersion string `mapstructure:"prompt_version"`
+	// Ограничение времени проверки моделью; по его истечении
+	// отзыв передается модератору.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// Catalog - настройки каталога товаров.
+type Catalog struct {
+	// Время жизни кэша рейтингов категорий. Кэш сбрасывается при изменении
+	// рейтингов этим экземпляром сервиса; изменения других экземпляров
+	// видны по истечении этого времени.
+	RatingTTL time.Duration `mapstructure:"rating_ttl"`
+}
+
+// Votes - настройки голосов за полезность отзывов.
+type Votes struct {
+	// Число голосов с одного клиента за RateWindow; 0 - без ограничения.
+	RateLimit  int           `mapstructure:"rate_limit"`
+	RateWindow time.Duration `mapstructure:"rate_window"`
+	// Адреса или подсети (CIDR) обратных прокси. Для запросов от них адрес
+	// клиента берется из X-Forwarded-For; без них - адрес соединения.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
+// Trend - настройки отслеживания резкого падения рейтинга товаров.
+type Trend struct {
+	// Окно наблюдения: средняя оценка отзывов за окно сравнивается
+	// со средней оценкой за предшествующий период Baseline.
+	Window   time.Duration `mapstructure:"window"`
+	Baseline time.Duration `mapstructure:"baseline"`
+	// Снижение средней оценки, начиная с которого падение считается резким.
+	Drop float64 `mapstructure:"drop"`
+	// Минимальное число отзывов в окне и в предшествующем периоде.
+	MinReviews int `mapstructure:"min_reviews"`
+	// Период проверки товаров с изменившимся рейтингом.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+}
+
+// Webhooks - настройки отправки уведомлений подписчикам.
+type Webhooks struct {
+	// Число одновременных запросов к подписчикам.
+	Workers int `mapstructure:"workers"`
+	// Ограничение времени одного запроса.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Число попыток доставки, после которого она считается неудачной.
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// Задержка перед повторной попыткой удваивается от MinBackoff
+	// до MaxBackoff.
+	MinBackoff time.Duration `mapstructure:"min_backoff"`
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+}
+
+var (
+	once     sync.Once
+	instance *Cfg
+)
+
+// Load загружает конфигурацию из файла и переменных окружения
+func Load(path string) (*Cfg, error) {
+	var err error
+	once.Do(func() {
+		// Устанавливаем имя конфигурационного файла (без расширения)
+		viper.SetConfigName("config")
+		viper.SetConfigType("yaml")
+
+		// Устанавливаем путь для поиска конфигурационного файла
+		viper.AddConfigPath(path)
+
+		// Чтение конфигурационного файла
+		if err = viper.ReadInConfig(); err != nil {
+			log.Printf("Error reading config file, %s", err)
+		}
+
+		// Автоматическое связывание переменных окружения с конфигом
+		viper.AutomaticEnv()
+
+		// Устанавливаем префикс для переменных окружения
+		viper.SetEnvPrefix("REVIEWS")
+
+		// Устанавливаем альтернативные имена для переменных окружения
+		viper.BindEnv("port", "REVIEWS_PORT")
+		viper.BindEnv("storage", "REVIEWS_STORAGE")
+		viper.BindEnv("db_conn_str", "REVIEWS_DB_CONN_STR")
+		viper.BindEnv("admin_token", "REVIEWS_ADMIN_TOKEN")
+
+		// Устанавливаем значения по умолчанию
+		viper.SetDefault("port", 8080)
+		viper.SetDefault("storage", StoragePostgres)
+		viper.SetDefault("otel_endpoint", "http://localhost:4318")
+		viper.SetDefault("llm.url", "http://localhost:11434")
+		viper.SetDefault("llm.timeout", 2*time.Minute)
+		viper.SetDefault("llm.model", "qwen2.5:1.5b")
+		viper.SetDefault("llm.max_concurrent", 4)
+		viper.SetDefault("llm.breaker_threshold", 5)
+		viper.SetDefault("llm.breaker_cooldown", 30*time.Second)
+		viper.SetDefault("sentiment.classifier", ClassifierLexicon)
+		viper.SetDefault("sentiment.fallback", true)
+		viper.SetDefault("sentiment.prompt_name", "sentiment")
+		viper.SetDefault("sentiment.prompt_version", "2")
+		viper.SetDefault("sentiment.timeout", 30*time.Second)
+		viper.SetDefault("sentiment.workers", 4)
+		viper.SetDefault("sentiment.max_attempts", 5)
+		viper.SetDefault("chat.system_prompt", "Ты помощник магазина. Отвечай кратко и по делу.")
+		viper.SetDefault("chat.context_tokens", 4096)
+		viper.SetDefault("chat.reply_tokens", 512)
+		viper.SetDefault("similarity.enabled", false)
+		viper.SetDefault("similarity.model", "nomic-embed-text")
+		viper.SetDefault("similarity.threshold", 0.95)
+		viper.SetDefault("moderation.llm", false)
+		viper.SetDefault("moderation.prompt_version", "latest")
+		viper.SetDefault("moderation.timeout", 5*time.Second)
+		viper.SetDefault("catalog.rating_ttl", time.Minute)
+		viper.SetDefault("votes.rate_limit", 20)
+		viper.SetDefault("votes.rate_window", time.Minute)
+		viper.SetDefault("trend.window", 7*24*time.Hour)
+		viper.SetDefault("trend.baseline", 28*24*time.Hour)
+		viper.SetDefault("trend.drop", 1.0)
+		viper.SetDefault("trend.min_reviews", 5)
+		viper.SetDefault("trend.poll_interval", time.Minute)
+		viper.SetDefault("webhooks.workers", 4)
+		viper.SetDefault("webhooks.timeout", 10*time.Second)
+		viper.SetDefault("webhooks.max_attempts", 8)
+		viper.SetDefault("webhooks.min_backoff", 10*time.Second)
+		viper.SetDefault("webhooks.max_backoff", time.Hour)
+
+		instance = &Cfg{}
+		if err = viper.Unmarshal(instance); err != nil {
+			log.Printf("Unable to decode into config struct, %v", err)
+		}
+	})
+
+	return instance, err
+}
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/rating"
+)
+
+var (
+	// ErrNotFound - запрошенная запись не найдена.
+	ErrNotFound = errors.New("not found")
+	// ErrNoEmbedding - для отзыва еще не вычислен вектор текста.
+	ErrNoEmbedding = errors.New("embedding not found")
+	// ErrConflict - запись в состоянии, не допускающем изменения.
+	ErrConflict = errors.New("conflict")
+)
+
+type DB interface {
+	// AddProduct сохраняет товар. Если категория товара не существует,
+	// возвращает ErrNotFound.
+	AddProduct(context.Context, models.Product) (models.Product, error)
+	GetProduct(ctx context.Context, id string) (models.Product, error)
+	ListProducts(context.Context) ([]models.Product, error)
+	// UpdateProduct изменяет название и категорию товара. Если товар или
+	// категория не существуют, возвращает ErrNotFound.
+	UpdateProduct(context.Context, models.Product) (models.Product, error)
+	// DeleteProduct удаляет товар. Товар с отзывами не удаляется
+	// (ErrConflict), чтобы не потерять отзывы вместе с ним.
+	DeleteProduct(ctx context.Context, id string) error
+
+	// AddCategory сохраняет категорию. Если родительская категория
+	// не существует, возвращает ErrNotFound.
+	AddCategory(context.Context, models.Category) (models.Category, error)
+	GetCategory(ctx context.Context, id string) (models.Category, error)
+	// ListCategories возвращает все категории в порядке названий.
+	ListCategories(context.Context) ([]models.Category, error)
+	// UpdateCategory изменяет название и родителя категории. Если родитель
+	// не существует, возвращает ErrNotFound, если он совпадает с категорией
+	// или ее подкатегорией - ErrConflict.
+	UpdateCategory(context.Context, models.Category) (models.Category, error)
+	// DeleteCategory удаляет категорию без подкатегорий и товаров;
+	// иначе возвращает ErrConflict.
+	DeleteCategory(ctx context.Context, id string) error
+	// CategoryAggregates возвращает агрегаты рейтинга товаров по
+	// категориям. Учитываются только товары, отнесенные непосредственно
+	// к категории; сведение по подкатегориям выполняет пакет catalog.
+	CategoryAggregates(context.Context) (map[string]CategoryAggregate, error)
+
+	// AddReview сохраняет отзыв с результатом модерации r.Moderation
+	// (без него отзыв публикуется). Классифицируются только опубликованные
+	// отзывы.
+	AddReview(context.Context, models.Review) (models.Review, error)
+	GetReview(ctx context.Context, productID, id string) (models.Review, error)
+	// ListReviews возвращает опубликованные отзывы товара. Непустой
+	// language оставляет только отзывы на этом языке.
+	ListReviews(ctx context.Context, productID, language string) ([]models.Review, error)
+	// UpdateReview изменяет отзыв, если его текст все еще равен seenText -
+	// тексту, прочитанному вызывающим, иначе возвращает ErrConflict. Если
+	// изменился текст, сохраняется новый результат модерации r.Moderation
+	// (без него отзыв ждет решения модератора), иначе прежний.
+	UpdateReview(ctx context.Context, r models.Review, seenText string) (models.Review, error)
+	DeleteReview(ctx context.Context, productID, id string) error
+
+	// Vote сохраняет голос за полезность опубликованного отзыва товара
+	// productID, заменяя прежний голос того же пользователя, и возвращает
+	// отзыв с новыми счетчиками. Если за отзыв уже голосовал другой
+	// пользователь с того же клиента или тот же пользователь с другого
+	// клиента, возвращает ErrConflict.
+	Vote(ctx context.Context, productID string, v models.Vote) (models.Review, error)
+	// Unvote удаляет голос пользователя за отзыв, если он есть. Голос,
+	// поданный с другого клиента, не удаляется: возвращается ErrNotFound.
+	Unvote(ctx context.Context, productID, reviewID, voter, client string) (models.Review, error)
+
+	// ProductRating возвращает агрегат классифицированных отзывов товара.
+	ProductRating(ctx context.Context, productID string) (rating.Aggregate, error)
+	// AspectRatings возвращает агрегаты классифицированных отзывов товара
+	// по аспектам.
+	AspectRatings(ctx context.Context, productID string) (map[string]rating.Aggregate, error)
+	// LanguageRatings возвращает агрегаты классифицированных отзывов товара
+	// по языкам отзывов.
+	LanguageRatings(ctx context.Context, productID string) (map[string]rating.Aggregate, error)
+	// RatingHistory возвращает агрегаты классифицированных отзывов товара
+	// по дням создания отзывов, начало которых в интервале [from, to),
+	// в порядке дней.
+	// Дни без классифицированных отзывов пропускаются.
+	RatingHistory(ctx context.Context, productID string, from, to time.Time) ([]DailyRating, error)
+
+	// ModerationQueue возвращает до limit отзывов, ожидающих модератора,
+	// в порядке создания.
+	ModerationQueue(ctx context.Context, limit int) ([]models.Review, error)
+	// Moderate сохраняет решение модератора по отзыву из очереди.
+	// Если отзыв не ожидает решения, возвращает ErrConflict.
+	Moderate(ctx context.Context, reviewID string, m models.Moderation) (models.Review, error)
+
+	// ImportReviews сохраняет отзывы одной транзакцией с результатами
+	// модерации и временем создания из r (пустое время - текущее).
+	// Возвращает сохраненные отзывы в том же порядке. Если товар отзыва
+	// не существует, возвращает ErrNotFound и не сохраняет ни одного отзыва.
+	ImportReviews(context.Context, []models.Review) ([]models.Review, error)
+	// ExportReviews вызывает fn для отзывов, удовлетворяющих фильтру,
+	// в порядке создания. Отзывы читаются из хранилища частями, поэтому
+	// fn может записывать их в ответ по мере чтения. Ошибка fn прерывает выгрузку.
+	ExportReviews(ctx context.Context, f ReviewFilter, fn func(models.Review) error) error
+
+	// SimilarReviews возвращает до limit опубликованных отзывов, наиболее
+	// похожих на отзыв reviewID, в порядке убывания сходства.
+	SimilarReviews(ctx context.Context, reviewID string, limit int) ([]models.SimilarReview, error)
+	// SearchReviews возвращает до q.Limit опубликованных отзывов, текст
+	// которых соответствует запросу, в порядке убывания релевантности,
+	// с подсветкой найденных слов.
+	SearchReviews(ctx context.Context, q SearchQuery) ([]models.SearchResult, error)
+}
+
+// SearchQuery - условия полнотекстового поиска отзывов. Пустые ProductID
+// и Language не ограничивают выборку.
+type SearchQuery struct {
+	// Текст запроса.
+	Text      string
+	ProductID string
+	// Код языка отзыва.
+	Language string
+	Limit    int
+}
+
+// ReviewFilter - условия выборки отзывов для выгрузки. Пустые поля
+// не ограничивают выборку.
+type ReviewFilter struct {
+	ProductID string
+	// Интервал времени создания [From, To).
+	From, To time.Time
+	// Статус публикации.
+	Status string
+	// Код языка отзыва.
+	Language string
+}
+
+// Match сообщает, удовлетворяет ли отзыв фильтру.
+func (f ReviewFilter) Match(r models.Review) bool {
+	return (f.ProductID == "" || r.ProductID == f.ProductID) &&
+		(f.From.IsZero() || !r.CreatedAt.Before(f.From)) &&
+		(f.To.IsZero() || r.CreatedAt.Before(f.To)) &&
+		(f.Status == "" || r.Moderation.Status == f.Status) &&
+		(f.Language == "" || r.Language == f.Language)
+}
+
+// CategoryAggregate - агрегат рейтинга товаров категории.
+type CategoryAggregate struct {
+	// Число товаров.
+	Products int
+	Rating   rating.Aggregate
+}
+
+// DailyRating - агрегат классифицированных отзывов товара,
+// оставленных за день.
+type DailyRating struct {
+	// Начало дня (UTC).
+	Day    time.Time
+	Rating rating.Aggregate
+}
+
+// Day возвращает начало дня (UTC), к агрегату которого относится
+// отзыв, созданный в момент t.
+func Day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
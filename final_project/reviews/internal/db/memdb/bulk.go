@@ -0,0 +1,66 @@
+package memdb
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func (m *MemDB) ImportReviews(_ context.Context, reviews []models.Review) ([]models.Review, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, r := range reviews {
+		if m.productIndex(r.ProductID) < 0 {
+			return nil, db.ErrNotFound
+		}
+	}
+
+	now := time.Now().UTC()
+	res := make([]models.Review, len(reviews))
+	for i, r := range reviews {
+		r.ID = uuid.NewString()
+		r.Moderation = published(r.Moderation)
+		r.Sentiment = models.Sentiment{Status: models.SentimentPending}
+		if r.CreatedAt.IsZero() {
+			r.CreatedAt = now
+		}
+		r.UpdatedAt = now
+		m.reviews = append(m.reviews, r)
+		m.index.Add(r.ID, r.Text)
+		if r.Moderation.Status == models.ReviewPublished {
+			m.enqueue(r.ID)
+		}
+		res[i] = r
+	}
+	return res, nil
+}
+
+// ExportReviews выбирает отзывы под блокировкой и вызывает fn без нее,
+// чтобы медленный клиент не блокировал запись.
+func (m *MemDB) ExportReviews(_ context.Context, f db.ReviewFilter, fn func(models.Review) error) error {
+	m.mu.RLock()
+	var res []models.Review
+	for _, r := range m.reviews {
+		if f.Match(r) {
+			res = append(res, r)
+		}
+	}
+	m.mu.RUnlock()
+
+	// Импортированные отзывы добавляются со своим временем создания.
+	slices.SortStableFunc(res, func(a, b models.Review) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	for _, r := range res {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package memdb
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func (m *MemDB) UpdateProduct(_ context.Context, p models.Product) (models.Product, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.productIndex(p.ID)
+	if i < 0 || p.CategoryID != "" && m.categoryIndex(p.CategoryID) < 0 {
+		return models.Product{}, db.ErrNotFound
+	}
+	m.products[i].Name = p.Name
+	m.products[i].CategoryID = p.CategoryID
+	return m.products[i], nil
+}
+
+func (m *MemDB) DeleteProduct(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.productIndex(id)
+	if i < 0 {
+		return db.ErrNotFound
+	}
+	if slices.ContainsFunc(m.reviews, func(r models.Review) bool { return r.ProductID == id }) {
+		return db.ErrConflict
+	}
+	m.products = slices.Delete(m.products, i, i+1)
+	delete(m.ratings, id)
+	delete(m.aspects, id)
+	delete(m.languages, id)
+	return nil
+}
+
+func (m *MemDB) AddCategory(_ context.Context, c models.Category) (models.Category, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if c.ParentID != "" && m.categoryIndex(c.ParentID) < 0 {
+		return models.Category{}, db.ErrNotFound
+	}
+	c.ID = uuid.NewString()
+	c.CreatedAt = time.Now().UTC()
+	m.categories = append(m.categories, c)
+	return c, nil
+}
+
+func (m *MemDB) GetCategory(_ context.Context, id string) (models.Category, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := m.categoryIndex(id)
+	if i < 0 {
+		return models.Category{}, db.ErrNotFound
+	}
+	return m.categories[i], nil
+}
+
+func (m *MemDB) ListCategories(_ context.Context) ([]models.Category, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	res := append([]models.Category{}, m.categories...)
+	slices.SortStableFunc(res, func(a, b models.Category) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return res, nil
+}
+
+func (m *MemDB) UpdateCategory(_ context.Context, c models.Category) (models.Category, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.categoryIndex(c.ID)
+	if i < 0 {
+		return models.Category{}, db.ErrNotFound
+	}
+	// Родитель не может быть самой категорией или ее подкатегорией.
+	for id := c.ParentID; id != ""; {
+		if id == c.ID {
+			return models.Category{}, db.ErrConflict
+		}
+		j := m.categoryIndex(id)
+		if j < 0 {
+			return models.Category{}, db.ErrNotFound
+		}
+		id = m.categories[j].ParentID
+	}
+	m.categories[i].Name = c.Name
+	m.categories[i].ParentID = c.ParentID
+	return m.categories[i], nil
+}
+
+func (m *MemDB) DeleteCategory(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.categoryIndex(id)
+	if i < 0 {
+		return db.ErrNotFound
+	}
+	if slices.ContainsFunc(m.categories, func(c models.Category) bool { return c.ParentID == id }) ||
+		slices.ContainsFunc(m.products, func(p models.Product) bool { return p.CategoryID == id }) {
+		return db.ErrConflict
+	}
+	m.categories = slices.Delete(m.categories, i, i+1)
+	return nil
+}
+
+func (m *MemDB) CategoryAggregates(_ context.Context) (map[string]db.CategoryAggregate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	res := make(map[string]db.CategoryAggregate)
+	for _, p := range m.products {
+		if p.CategoryID == "" {
+			continue
+		}
+		a := res[p.CategoryID]
+		a.Products++
+		a.Rating = a.Rating.Add(m.ratings[p.ID])
+		res[p.CategoryID] = a
+	}
+	return res, nil
+}
+
+func (m *MemDB) categoryIndex(id string) int {
+	return slices.IndexFunc(m.categories, func(c models.Category) bool {
+		return c.ID == id
+	})
+}
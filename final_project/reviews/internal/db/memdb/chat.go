@@ -0,0 +1,54 @@
+package memdb
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go-masters/final_project/reviews/internal/chat"
+	"go-masters/final_project/reviews/internal/db"
+
+	"github.com/google/uuid"
+)
+
+func (m *MemDB) CreateSession(_ context.Context, s chat.Session) (chat.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().UTC()
+	s.ID = uuid.NewString()
+	s.Messages = []chat.Message{}
+	s.CreatedAt = now
+	s.UpdatedAt = now
+	m.sessions[s.ID] = &s
+	return s, nil
+}
+
+func (m *MemDB) GetSession(_ context.Context, id string) (chat.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.sessions[id]
+	if !ok {
+		return chat.Session{}, db.ErrNotFound
+	}
+	res := *s
+	res.Messages = slices.Clone(s.Messages)
+	return res, nil
+}
+
+func (m *MemDB) AppendMessages(_ context.Context, id string, seen int, msgs ...chat.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	if !ok {
+		return db.ErrNotFound
+	}
+	if len(s.Messages) != seen {
+		return chat.ErrConflict
+	}
+	s.Messages = append(s.Messages, msgs...)
+	s.UpdatedAt = time.Now().UTC()
+	return nil
+}
@@ -0,0 +1,432 @@
+package memdb
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go-masters/final_project/reviews/internal/chat"
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/rating"
+	"go-masters/final_project/reviews/internal/reclassify"
+	"go-masters/final_project/reviews/internal/search"
+	"go-masters/final_project/reviews/internal/similarity"
+	"go-masters/final_project/reviews/internal/webhook"
+
+	"github.com/google/uuid"
+)
+
+// MemDB - хранилище в памяти для разработки и тестов.
+// Записи хранятся в порядке добавления.
+type MemDB struct {
+	mu         sync.RWMutex
+	categories []models.Category
+	products   []models.Product
+	reviews    []models.Review
+	// Задания классификации по идентификатору отзыва.
+	jobs map[string]*job
+	// Агрегаты рейтинга по идентификатору товара.
+	ratings map[string]rating.Aggregate
+	// Дневные агрегаты рейтинга по идентификатору товара и дню.
+	daily map[string]map[time.Time]rating.Aggregate
+	// Время последнего изменения дневных агрегатов товара.
+	dailyChanged map[string]time.Time
+	// Агрегаты рейтинга по идентификатору товара и аспекту или языку.
+	aspects   map[string]map[string]rating.Aggregate
+	languages map[string]map[string]rating.Aggregate
+	// Запуски повторной классификации и токены их захвата.
+	runs      []reclassify.Run
+	runTokens map[string]string
+	// Сессии диалогов с LLM.
+	sessions map[string]*chat.Session
+	// Векторы текстов по идентификатору отзыва.
+	embeddings map[string]similarity.Embedding
+	// Голоса за полезность по идентификатору отзыва и пользователю.
+	votes map[string]map[string]vote
+	// Подписки на события и доставки в порядке создания.
+	webhooks   []webhook.Webhook
+	deliveries []webhook.Delivery
+	// Полнотекстовый индекс текстов отзывов.
+	index *search.Index
+}
+
+// job - задание классификации отзыва.
+type job struct {
+	state     string
+	attempts  int
+	runAt     time.Time
+	token     string
+	lastError string
+}
+
+func New() *MemDB {
+	return &MemDB{
+		jobs:         make(map[string]*job),
+		ratings:      make(map[string]rating.Aggregate),
+		daily:        make(map[string]map[time.Time]rating.Aggregate),
+		dailyChanged: make(map[string]time.Time),
+		aspects:      make(map[string]map[string]rating.Aggregate),
+		languages:    make(map[string]map[string]rating.Aggregate),
+		runTokens:    make(map[string]string),
+		sessions:     make(map[string]*chat.Session),
+		embeddings:   make(map[string]similarity.Embedding),
+		votes:        make(map[string]map[string]vote),
+		index:        search.NewIndex(),
+	}
+}
+
+func (m *MemDB) AddProduct(_ context.Context, p models.Product) (models.Product, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if p.CategoryID != "" && m.categoryIndex(p.CategoryID) < 0 {
+		return models.Product{}, db.ErrNotFound
+	}
+	p.ID = uuid.NewString()
+	p.CreatedAt = time.Now().UTC()
+	m.products = append(m.products, p)
+	return p, nil
+}
+
+func (m *MemDB) GetProduct(_ context.Context, id string) (models.Product, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := m.productIndex(id)
+	if i < 0 {
+		return models.Product{}, db.ErrNotFound
+	}
+	return m.products[i], nil
+}
+
+func (m *MemDB) ListProducts(_ context.Context) ([]models.Product, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]models.Product{}, m.products...), nil
+}
+
+func (m *MemDB) AddReview(_ context.Context, r models.Review) (models.Review, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.productIndex(r.ProductID) < 0 {
+		return models.Review{}, db.ErrNotFound
+	}
+
+	r.ID = uuid.NewString()
+	r.Moderation = published(r.Moderation)
+	r.Sentiment = models.Sentiment{Status: models.SentimentPending}
+	r.CreatedAt = time.Now().UTC()
+	r.UpdatedAt = r.CreatedAt
+	m.reviews = append(m.reviews, r)
+	m.index.Add(r.ID, r.Text)
+	if r.Moderation.Status == models.ReviewPublished {
+		m.enqueue(r.ID)
+	}
+	return r, nil
+}
+
+func (m *MemDB) GetReview(_ context.Context, productID, id string) (models.Review, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := m.reviewIndex(productID, id)
+	if i < 0 {
+		return models.Review{}, db.ErrNotFound
+	}
+	return m.reviews[i], nil
+}
+
+func (m *MemDB) ListReviews(_ context.Context, productID, language string) ([]models.Review, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.productIndex(productID) < 0 {
+		return nil, db.ErrNotFound
+	}
+
+	res := []models.Review{}
+	for _, r := range m.reviews {
+		if r.ProductID == productID && r.Moderation.Status == models.ReviewPublished &&
+			(language == "" || r.Language == language) {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+func (m *MemDB) UpdateReview(_ context.Context, r models.Review, seenText string) (models.Review, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.reviewIndex(r.ProductID, r.ID)
+	if i < 0 {
+		return models.Review{}, db.ErrNotFound
+	}
+
+	old := &m.reviews[i]
+	if old.Text != seenText {
+		return models.Review{}, db.ErrConflict
+	}
+	if old.Text != r.Text {
+		old.Moderation = unmoderated(r.Moderation)
+		// Вклад отзыва вычитается из агрегата прежнего языка.
+		m.setSentiment(old, models.Sentiment{Status: models.SentimentPending})
+		old.Language = r.Language
+		if old.Moderation.Status == models.ReviewPublished {
+			m.enqueue(old.ID)
+		} else {
+			delete(m.jobs, old.ID)
+		}
+		m.resetEmbedding(old.ID)
+		m.index.Add(old.ID, r.Text)
+	}
+	old.Author = r.Author
+	old.Text = r.Text
+	old.Rating = r.Rating
+	old.UpdatedAt = time.Now().UTC()
+	return *old, nil
+}
+
+func (m *MemDB) DeleteReview(_ context.Context, productID, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.reviewIndex(productID, id)
+	if i < 0 {
+		return db.ErrNotFound
+	}
+	m.setSentiment(&m.reviews[i], models.Sentiment{})
+	m.resetEmbedding(id)
+	m.reviews = slices.Delete(m.reviews, i, i+1)
+	m.index.Remove(id)
+	delete(m.jobs, id)
+	delete(m.votes, id)
+	return nil
+}
+
+func (m *MemDB) ModerationQueue(_ context.Context, limit int) ([]models.Review, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	res := []models.Review{}
+	for _, r := range m.reviews {
+		if len(res) == limit {
+			break
+		}
+		if r.Moderation.Status == models.ReviewPending {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+func (m *MemDB) Moderate(_ context.Context, reviewID string, mod models.Moderation) (models.Review, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.reviewIndexByID(reviewID)
+	if i < 0 {
+		return models.Review{}, db.ErrNotFound
+	}
+	r := &m.reviews[i]
+	if r.Moderation.Status != models.ReviewPending {
+		return models.Review{}, db.ErrConflict
+	}
+	r.Moderation = mod
+	if mod.Status == models.ReviewPublished {
+		m.enqueue(r.ID)
+	}
+	return *r, nil
+}
+
+// published возвращает результат модерации или статус "опубликован",
+// если модерация не выполнялась.
+func published(mod models.Moderation) models.Moderation {
+	if mod.Status == "" {
+		return models.Moderation{Status: models.ReviewPublished}
+	}
+	return mod
+}
+
+// unmoderated возвращает результат модерации измененного отзыва:
+// без результата отзыв ждет решения модератора.
+func unmoderated(mod models.Moderation) models.Moderation {
+	if mod.Status == "" {
+		return models.Moderation{Status: models.ReviewPending}
+	}
+	return mod
+}
+
+// enqueue ставит отзыв в очередь классификации, заменяя прежнее задание.
+func (m *MemDB) enqueue(reviewID string) {
+	m.jobs[reviewID] = &job{state: jobs.StateQueued, runAt: time.Now()}
+}
+
+func (m *MemDB) ClaimJobs(_ context.Context, limit int, lease time.Duration) ([]jobs.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var res []jobs.Job
+	for _, r := range m.reviews {
+		if len(res) == limit {
+			break
+		}
+		j := m.jobs[r.ID]
+		if j == nil || j.state != jobs.StateQueued || j.runAt.After(now) {
+			continue
+		}
+		j.attempts++
+		j.runAt = now.Add(lease)
+		j.token = uuid.NewString()
+		res = append(res, jobs.Job{ReviewID: r.ID, Text: r.Text, Language: r.Language, Attempt: j.attempts, Token: j.token})
+	}
+	return res, nil
+}
+
+func (m *MemDB) CompleteJob(_ context.Context, jb jobs.Job, s models.Sentiment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.claimed(jb) == nil {
+		return nil
+	}
+	if i := m.reviewIndexByID(jb.ReviewID); i >= 0 {
+		m.setSentiment(&m.reviews[i], s)
+		m.publish(webhook.ReviewClassified(m.reviews[i]))
+	}
+	delete(m.jobs, jb.ReviewID)
+	return nil
+}
+
+func (m *MemDB) RetryJob(_ context.Context, jb jobs.Job, at time.Time, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if j := m.claimed(jb); j != nil {
+		j.runAt = at
+		j.lastError = reason
+	}
+	return nil
+}
+
+func (m *MemDB) FailJob(_ context.Context, jb jobs.Job, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j := m.claimed(jb)
+	if j == nil {
+		return nil
+	}
+	j.state = jobs.StateDead
+	j.lastError = reason
+	if i := m.reviewIndexByID(jb.ReviewID); i >= 0 {
+		m.setSentiment(&m.reviews[i], models.Sentiment{Status: models.SentimentFailed})
+	}
+	return nil
+}
+
+func (m *MemDB) ProductRating(_ context.Context, productID string) (rating.Aggregate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.productIndex(productID) < 0 {
+		return rating.Aggregate{}, db.ErrNotFound
+	}
+	return m.ratings[productID], nil
+}
+
+// AspectRatings возвращает непустые агрегаты аспектов товара.
+func (m *MemDB) AspectRatings(_ context.Context, productID string) (map[string]rating.Aggregate, error) {
+	return m.groupRatings(m.aspects, productID)
+}
+
+// LanguageRatings возвращает непустые агрегаты языков отзывов товара.
+func (m *MemDB) LanguageRatings(_ context.Context, productID string) (map[string]rating.Aggregate, error) {
+	return m.groupRatings(m.languages, productID)
+}
+
+// groupRatings возвращает непустые агрегаты групп отзывов товара.
+func (m *MemDB) groupRatings(groups map[string]map[string]rating.Aggregate, productID string) (map[string]rating.Aggregate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.productIndex(productID) < 0 {
+		return nil, db.ErrNotFound
+	}
+	res := make(map[string]rating.Aggregate)
+	for key, a := range groups[productID] {
+		if a.Count() > 0 {
+			res[key] = a
+		}
+	}
+	return res, nil
+}
+
+// setSentiment меняет настроение отзыва и обновляет агрегаты рейтинга товара.
+func (m *MemDB) setSentiment(r *models.Review, s models.Sentiment) {
+	delta := rating.Of(s, r.CreatedAt).Sub(rating.Of(r.Sentiment, r.CreatedAt))
+	aspects := rating.DiffGroups(rating.OfAspects(r.Sentiment, r.CreatedAt), rating.OfAspects(s, r.CreatedAt))
+	r.Sentiment = s
+	m.aspects[r.ProductID] = addGroups(m.aspects[r.ProductID], aspects)
+	if delta.IsZero() {
+		return
+	}
+	m.ratings[r.ProductID] = m.ratings[r.ProductID].Add(delta)
+	m.languages[r.ProductID] = addGroups(m.languages[r.ProductID], map[string]rating.Aggregate{r.Language: delta})
+
+	days := m.daily[r.ProductID]
+	if days == nil {
+		days = make(map[time.Time]rating.Aggregate)
+		m.daily[r.ProductID] = days
+	}
+	day := db.Day(r.CreatedAt)
+	days[day] = days[day].Add(delta)
+	m.dailyChanged[r.ProductID] = time.Now()
+}
+
+// addGroups прибавляет изменения к агрегатам групп и возвращает их.
+func addGroups(groups, delta map[string]rating.Aggregate) map[string]rating.Aggregate {
+	if len(delta) == 0 {
+		return groups
+	}
+	if groups == nil {
+		groups = make(map[string]rating.Aggregate, len(delta))
+	}
+	for key, d := range delta {
+		groups[key] = groups[key].Add(d)
+	}
+	return groups
+}
+
+// claimed возвращает задание, если оно все еще принадлежит захвату jb.
+func (m *MemDB) claimed(jb jobs.Job) *job {
+	j := m.jobs[jb.ReviewID]
+	if j == nil || j.token != jb.Token {
+		return nil
+	}
+	return j
+}
+
+func (m *MemDB) productIndex(id string) int {
+	return slices.IndexFunc(m.products, func(p models.Product) bool {
+		return p.ID == id
+	})
+}
+
+func (m *MemDB) reviewIndexByID(id string) int {
+	return slices.IndexFunc(m.reviews, func(r models.Review) bool {
+		return r.ID == id
+	})
+}
+
+func (m *MemDB) reviewIndex(productID, id string) int {
+	return slices.IndexFunc(m.reviews, func(r models.Review) bool {
+		return r.ID == id && r.ProductID == productID
+	})
+}
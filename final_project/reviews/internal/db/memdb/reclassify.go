@@ -0,0 +1,158 @@
+package memdb
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/reclassify"
+
+	"github.com/google/uuid"
+)
+
+func (m *MemDB) CreateRun(_ context.Context, f reclassify.Filter) (reclassify.Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().UTC()
+	run := reclassify.Run{
+		ID:        uuid.NewString(),
+		Filter:    f,
+		Status:    reclassify.StatusRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.runs = append(m.runs, run)
+	return run, nil
+}
+
+func (m *MemDB) GetRun(_ context.Context, id string) (reclassify.Run, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := m.runIndex(id)
+	if i < 0 {
+		return reclassify.Run{}, db.ErrNotFound
+	}
+	return m.runs[i], nil
+}
+
+func (m *MemDB) ListRuns(_ context.Context, status string) ([]reclassify.Run, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	res := []reclassify.Run{}
+	for _, run := range m.runs {
+		if run.Status == status {
+			res = append(res, run)
+		}
+	}
+	return res, nil
+}
+
+func (m *MemDB) NextReviews(_ context.Context, f reclassify.Filter, after reclassify.Cursor, limit int) ([]models.Review, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var res []models.Review
+	for _, r := range m.reviews {
+		if f.Match(r) && after.After(r) {
+			res = append(res, r)
+		}
+	}
+	slices.SortFunc(res, func(a, b models.Review) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return res[:min(limit, len(res))], nil
+}
+
+func (m *MemDB) ClaimRun(_ context.Context, id string, lease time.Duration) (reclassify.Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.runIndex(id)
+	if i < 0 {
+		return reclassify.Run{}, db.ErrNotFound
+	}
+	now := time.Now()
+	run := &m.runs[i]
+	if run.Status != reclassify.StatusRunning || run.Leased(now) {
+		return reclassify.Run{}, reclassify.ErrConflict
+	}
+	run.LeasedUntil = now.Add(lease).UTC()
+	m.runTokens[id] = uuid.NewString()
+
+	res := *run
+	res.Token = m.runTokens[id]
+	return res, nil
+}
+
+func (m *MemDB) ReleaseRun(_ context.Context, run reclassify.Run) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.runIndex(run.ID)
+	if i < 0 || m.runTokens[run.ID] != run.Token {
+		return nil
+	}
+	m.runs[i].LeasedUntil = time.Time{}
+	delete(m.runTokens, run.ID)
+	return nil
+}
+
+func (m *MemDB) SaveBatch(_ context.Context, run reclassify.Run, lease time.Duration, updates []reclassify.Update) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.runIndex(run.ID)
+	if i < 0 {
+		return db.ErrNotFound
+	}
+	if run.Token == "" || m.runTokens[run.ID] != run.Token {
+		return reclassify.ErrConflict
+	}
+
+	for _, u := range updates {
+		j := m.reviewIndexByID(u.Review.ID)
+		// Отзыв удален или изменен после чтения.
+		if j < 0 || !m.reviews[j].UpdatedAt.Equal(u.Review.UpdatedAt) || !m.reviews[j].Sentiment.Equal(u.Review.Sentiment) {
+			continue
+		}
+		m.setSentiment(&m.reviews[j], u.Sentiment)
+		if jb := m.jobs[u.Review.ID]; jb != nil && jb.state == jobs.StateDead {
+			delete(m.jobs, u.Review.ID)
+		}
+	}
+
+	stored := &m.runs[i]
+	stored.Cursor = run.Cursor
+	stored.Processed = run.Processed
+	stored.Failed = run.Failed
+	stored.LeasedUntil = time.Now().Add(lease).UTC()
+	stored.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (m *MemDB) SetRunStatus(_ context.Context, id, status, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.runIndex(id)
+	if i < 0 {
+		return db.ErrNotFound
+	}
+	m.runs[i].Status = status
+	m.runs[i].Error = reason
+	m.runs[i].UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (m *MemDB) runIndex(id string) int {
+	return slices.IndexFunc(m.runs, func(r reclassify.Run) bool {
+		return r.ID == id
+	})
+}
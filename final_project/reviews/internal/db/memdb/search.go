@@ -0,0 +1,41 @@
+package memdb
+
+import (
+	"context"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/search"
+)
+
+// SearchReviews ищет отзывы по инвертированному индексу: находятся
+// отзывы, содержащие все слова запроса. Синтаксис запросов Postgres
+// (кавычки, or, минус) не поддерживается.
+func (m *MemDB) SearchReviews(_ context.Context, q db.SearchQuery) ([]models.SearchResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	terms := search.Terms(q.Text)
+	res := []models.SearchResult{}
+	for _, h := range m.index.Search(terms) {
+		if len(res) == q.Limit {
+			break
+		}
+		i := m.reviewIndexByID(h.ID)
+		if i < 0 {
+			continue
+		}
+		r := m.reviews[i]
+		if r.Moderation.Status != models.ReviewPublished ||
+			(q.ProductID != "" && r.ProductID != q.ProductID) ||
+			(q.Language != "" && r.Language != q.Language) {
+			continue
+		}
+		res = append(res, models.SearchResult{
+			Review:    r,
+			Rank:      h.Score,
+			Highlight: search.Highlight(r.Text, terms),
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,106 @@
+package memdb
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/similarity"
+)
+
+func (m *MemDB) PendingEmbeddings(_ context.Context, model string, limit int) ([]models.Review, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var res []models.Review
+	for _, r := range m.reviews {
+		if len(res) == limit {
+			break
+		}
+		if e, ok := m.embeddings[r.ID]; !ok || e.Model != model {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+func (m *MemDB) NearestReviews(_ context.Context, e similarity.Embedding, limit int) ([]models.SimilarReview, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.nearest(e, limit, isPublished), nil
+}
+
+func (m *MemDB) SaveEmbedding(_ context.Context, r models.Review, e similarity.Embedding, dup *models.Duplicate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.reviewIndexByID(r.ID)
+	if i < 0 || m.reviews[i].Text != r.Text {
+		return nil
+	}
+	m.embeddings[r.ID] = e
+	if dup != nil {
+		d := *dup
+		dup = &d
+	}
+	m.reviews[i].Duplicate = dup
+	return nil
+}
+
+func (m *MemDB) SimilarReviews(_ context.Context, reviewID string, limit int) ([]models.SimilarReview, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.reviewIndexByID(reviewID) < 0 {
+		return nil, db.ErrNotFound
+	}
+	e, ok := m.embeddings[reviewID]
+	if !ok {
+		return nil, db.ErrNoEmbedding
+	}
+	return m.nearest(e, limit, isPublished), nil
+}
+
+// isPublished сообщает, опубликован ли отзыв.
+func isPublished(r models.Review) bool {
+	return r.Moderation.Status == models.ReviewPublished
+}
+
+// nearest перебирает все векторы модели e.Model и возвращает до limit
+// ближайших отзывов, для которых keep возвращает true (nil - все отзывы).
+func (m *MemDB) nearest(e similarity.Embedding, limit int, keep func(models.Review) bool) []models.SimilarReview {
+	res := []models.SimilarReview{}
+	for _, r := range m.reviews {
+		other, ok := m.embeddings[r.ID]
+		if !ok || r.ID == e.ReviewID || other.Model != e.Model {
+			continue
+		}
+		if keep != nil && !keep(r) {
+			continue
+		}
+		sim, err := similarity.Cosine(e.Vector, other.Vector)
+		if err != nil {
+			continue
+		}
+		res = append(res, models.SimilarReview{Review: r, Similarity: sim})
+	}
+	slices.SortStableFunc(res, func(a, b models.SimilarReview) int {
+		return cmp.Compare(b.Similarity, a.Similarity)
+	})
+	return res[:min(limit, len(res))]
+}
+
+// resetEmbedding удаляет вектор отзыва id и отметки о копиях этого отзыва:
+// после изменения текста сходство нужно вычислить заново.
+func (m *MemDB) resetEmbedding(id string) {
+	for i := range m.reviews {
+		r := &m.reviews[i]
+		if r.ID == id || (r.Duplicate != nil && r.Duplicate.ReviewID == id) {
+			r.Duplicate = nil
+			delete(m.embeddings, r.ID)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package memdb
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+)
+
+func (m *MemDB) RatingHistory(_ context.Context, productID string, from, to time.Time) ([]db.DailyRating, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.productIndex(productID) < 0 {
+		return nil, db.ErrNotFound
+	}
+	res := []db.DailyRating{}
+	for day, a := range m.daily[productID] {
+		if day.Before(from) || !day.Before(to) || a.Count() == 0 {
+			continue
+		}
+		res = append(res, db.DailyRating{Day: day, Rating: a})
+	}
+	slices.SortFunc(res, func(a, b db.DailyRating) int {
+		return a.Day.Compare(b.Day)
+	})
+	return res, nil
+}
+
+func (m *MemDB) ChangedRatings(_ context.Context, since time.Time) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	res := []string{}
+	for productID, changed := range m.dailyChanged {
+		if !changed.Before(since) {
+			res = append(res, productID)
+		}
+	}
+	slices.Sort(res)
+	return res, nil
+}
@@ -0,0 +1,67 @@
+package memdb
+
+import (
+	"context"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+)
+
+// vote - голос пользователя за полезность отзыва.
+type vote struct {
+	client  string
+	helpful bool
+}
+
+func (m *MemDB) Vote(_ context.Context, productID string, v models.Vote) (models.Review, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.reviewIndex(productID, v.ReviewID)
+	if i < 0 || m.reviews[i].Moderation.Status != models.ReviewPublished {
+		return models.Review{}, db.ErrNotFound
+	}
+
+	votes := m.votes[v.ReviewID]
+	for voter, old := range votes {
+		// С клиента голосует один пользователь, пользователь - с одного клиента.
+		if voter != v.Voter && old.client == v.Client || voter == v.Voter && old.client != v.Client {
+			return models.Review{}, db.ErrConflict
+		}
+	}
+	if votes == nil {
+		votes = make(map[string]vote)
+		m.votes[v.ReviewID] = votes
+	}
+	votes[v.Voter] = vote{client: v.Client, helpful: v.Helpful}
+	m.countVotes(&m.reviews[i])
+	return m.reviews[i], nil
+}
+
+func (m *MemDB) Unvote(_ context.Context, productID, reviewID, voter, client string) (models.Review, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.reviewIndex(productID, reviewID)
+	if i < 0 {
+		return models.Review{}, db.ErrNotFound
+	}
+	if v, ok := m.votes[reviewID][voter]; ok && v.client != client {
+		return models.Review{}, db.ErrNotFound
+	}
+	delete(m.votes[reviewID], voter)
+	m.countVotes(&m.reviews[i])
+	return m.reviews[i], nil
+}
+
+// countVotes пересчитывает голоса за отзыв.
+func (m *MemDB) countVotes(r *models.Review) {
+	r.Helpful = models.Helpfulness{}
+	for _, v := range m.votes[r.ID] {
+		if v.helpful {
+			r.Helpful.Up++
+		} else {
+			r.Helpful.Down++
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package memdb
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/webhook"
+
+	"github.com/google/uuid"
+)
+
+func (m *MemDB) AddWebhook(_ context.Context, w webhook.Webhook) (webhook.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w.ID = uuid.NewString()
+	w.CreatedAt = time.Now().UTC()
+	w.UpdatedAt = w.CreatedAt
+	w = cloneWebhook(w)
+	m.webhooks = append(m.webhooks, w)
+	return cloneWebhook(w), nil
+}
+
+func (m *MemDB) GetWebhook(_ context.Context, id string) (webhook.Webhook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := m.webhookIndex(id)
+	if i < 0 {
+		return webhook.Webhook{}, db.ErrNotFound
+	}
+	return cloneWebhook(m.webhooks[i]), nil
+}
+
+func (m *MemDB) ListWebhooks(context.Context) ([]webhook.Webhook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	res := make([]webhook.Webhook, len(m.webhooks))
+	for i, w := range m.webhooks {
+		res[i] = cloneWebhook(w)
+	}
+	return res, nil
+}
+
+func (m *MemDB) UpdateWebhook(_ context.Context, w webhook.Webhook) (webhook.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.webhookIndex(w.ID)
+	if i < 0 {
+		return webhook.Webhook{}, db.ErrNotFound
+	}
+	w.CreatedAt = m.webhooks[i].CreatedAt
+	w.UpdatedAt = time.Now().UTC()
+	m.webhooks[i] = cloneWebhook(w)
+	return cloneWebhook(w), nil
+}
+
+func (m *MemDB) DeleteWebhook(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.webhookIndex(id)
+	if i < 0 {
+		return db.ErrNotFound
+	}
+	m.webhooks = slices.Delete(m.webhooks, i, i+1)
+	m.deliveries = slices.DeleteFunc(m.deliveries, func(d webhook.Delivery) bool {
+		return d.WebhookID == id
+	})
+	return nil
+}
+
+func (m *MemDB) Publish(_ context.Context, e webhook.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.publish(e)
+	return nil
+}
+
+func (m *MemDB) ClaimDeliveries(_ context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	token := uuid.NewString()
+	var res []webhook.Delivery
+	for i := range m.deliveries {
+		if len(res) == limit {
+			break
+		}
+		d := &m.deliveries[i]
+		if d.Status != webhook.StatusPending || d.NextAttemptAt.After(now) {
+			continue
+		}
+		d.Attempts++
+		d.NextAttemptAt = now.Add(lease)
+		d.Token = token
+		d.UpdatedAt = now.UTC()
+		res = append(res, *d)
+	}
+	return res, nil
+}
+
+func (m *MemDB) SaveDelivery(_ context.Context, d webhook.Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.deliveryIndex(d.WebhookID, d.ID)
+	if i < 0 || m.deliveries[i].Token != d.Token {
+		return db.ErrNotFound
+	}
+	old := &m.deliveries[i]
+	old.Status = d.Status
+	old.NextAttemptAt = d.NextAttemptAt
+	old.ResponseStatus = d.ResponseStatus
+	old.Error = d.Error
+	old.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (m *MemDB) ListDeliveries(_ context.Context, webhookID string, limit int) ([]webhook.Delivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	res := []webhook.Delivery{}
+	for i := len(m.deliveries) - 1; i >= 0 && len(res) < limit; i-- {
+		if m.deliveries[i].WebhookID == webhookID {
+			res = append(res, m.deliveries[i])
+		}
+	}
+	return res, nil
+}
+
+func (m *MemDB) ReplayDelivery(_ context.Context, webhookID, deliveryID string) (webhook.Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.deliveryIndex(webhookID, deliveryID)
+	if i < 0 {
+		return webhook.Delivery{}, db.ErrNotFound
+	}
+	old := m.deliveries[i]
+	d := m.addDelivery(old.WebhookID, webhook.Event{ID: old.EventID, Type: old.Event, Payload: old.Payload})
+	return d, nil
+}
+
+// publish сохраняет доставки события подходящим подпискам.
+func (m *MemDB) publish(e webhook.Event) {
+	for _, w := range m.webhooks {
+		if w.Match(e) {
+			m.addDelivery(w.ID, e)
+		}
+	}
+}
+
+func (m *MemDB) addDelivery(webhookID string, e webhook.Event) webhook.Delivery {
+	now := time.Now().UTC()
+	d := webhook.Delivery{
+		ID:            uuid.NewString(),
+		WebhookID:     webhookID,
+		EventID:       e.ID,
+		Event:         e.Type,
+		Payload:       e.Payload,
+		Status:        webhook.StatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	m.deliveries = append(m.deliveries, d)
+	return d
+}
+
+func (m *MemDB) webhookIndex(id string) int {
+	return slices.IndexFunc(m.webhooks, func(w webhook.Webhook) bool {
+		return w.ID == id
+	})
+}
+
+func (m *MemDB) deliveryIndex(webhookID, id string) int {
+	return slices.IndexFunc(m.deliveries, func(d webhook.Delivery) bool {
+		return d.ID == id && d.WebhookID == webhookID
+	})
+}
+
+// cloneWebhook копирует фильтры подписки, чтобы хранимая запись
+// не менялась через возвращенную.
+func cloneWebhook(w webhook.Webhook) webhook.Webhook {
+	w.Events = slices.Clone(w.Events)
+	w.ProductIDs = slices.Clone(w.ProductIDs)
+	w.Labels = slices.Clone(w.Labels)
+	return w
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// exportPageSize - число отзывов, читаемых выгрузкой за один запрос.
+const exportPageSize = 1000
+
+// ImportReviews копирует отзывы в таблицу протоколом COPY, а задания
+// классификации опубликованных отзывов - вторым COPY в той же транзакции.
+func (pg *Postgres) ImportReviews(ctx context.Context, reviews []models.Review) ([]models.Review, error) {
+	now := time.Now().UTC()
+	res := make([]models.Review, len(reviews))
+	rows := make([][]any, len(reviews))
+	var queued [][]any
+	for i, r := range reviews {
+		if uuid.Validate(r.ProductID) != nil {
+			return nil, db.ErrNotFound
+		}
+		r.ID = uuid.NewString()
+		r.Moderation = published(r.Moderation)
+		r.Sentiment = models.Sentiment{Status: models.SentimentPending}
+		if r.CreatedAt.IsZero() {
+			r.CreatedAt = now
+		}
+		r.UpdatedAt = now
+		res[i] = r
+
+		rows[i] = []any{
+			r.ID, r.ProductID, r.Author, r.Text, r.Rating, r.Language,
+			r.Moderation.Status, reasons(r.Moderation), r.Moderation.Moderator, r.Moderation.ModeratedAt,
+			r.CreatedAt, r.UpdatedAt,
+		}
+		if r.Moderation.Status == models.ReviewPublished {
+			queued = append(queued, []any{r.ID})
+		}
+	}
+
+	err := pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		_, err := tx.CopyFrom(
+			ctx,
+			pgx.Identifier{"reviews"},
+			[]string{
+				"id", "product_id", "author", "text", "rating", "language",
+				"status", "moderation_reasons", "moderated_by", "moderated_at",
+				"created_at", "updated_at",
+			},
+			pgx.CopyFromRows(rows),
+		)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CopyFrom(ctx, pgx.Identifier{"classification_jobs"}, []string{"review_id"}, pgx.CopyFromRows(queued))
+		return err
+	})
+
+	// Отзыв к несуществующему товару.
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return nil, db.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ExportReviews читает отзывы страницами по ключу (created_at, id), а не
+// смещением, поэтому каждая страница выбирается по индексу за одно время.
+// Страница считывается целиком до вызова fn, чтобы не держать соединение
+// из пула, пока клиент принимает данные.
+func (pg *Postgres) ExportReviews(ctx context.Context, f db.ReviewFilter, fn func(models.Review) error) error {
+	if f.ProductID != "" && uuid.Validate(f.ProductID) != nil {
+		return nil
+	}
+
+	var (
+		from, to  *time.Time
+		lastAt    *time.Time
+		lastID    *string
+		productID *string
+		status    *string
+		language  *string
+	)
+	if !f.From.IsZero() {
+		from = &f.From
+	}
+	if !f.To.IsZero() {
+		to = &f.To
+	}
+	if f.ProductID != "" {
+		productID = &f.ProductID
+	}
+	if f.Status != "" {
+		status = &f.Status
+	}
+	if f.Language != "" {
+		language = &f.Language
+	}
+
+	for {
+		rows, err := pg.pool.Query(
+			ctx,
+			`SELECT `+reviewColumns+` FROM reviews
+			WHERE ($1::uuid IS NULL OR product_id = $1)
+				AND ($2::timestamptz IS NULL OR created_at >= $2)
+				AND ($3::timestamptz IS NULL OR created_at < $3)
+				AND ($4::text IS NULL OR status = $4)
+				AND ($5::timestamptz IS NULL OR (created_at, id) > ($5, $6::uuid))
+				AND ($8::text IS NULL OR language = $8)
+			ORDER BY created_at, id
+			LIMIT $7`,
+			productID,
+			from,
+			to,
+			status,
+			lastAt,
+			lastID,
+			exportPageSize,
+			language,
+		)
+		if err != nil {
+			return err
+		}
+		page, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Review, error) {
+			return scanReview(row)
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, r := range page {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+		if len(page) < exportPageSize {
+			return nil
+		}
+		last := page[len(page)-1]
+		lastAt, lastID = &last.CreatedAt, &last.ID
+	}
+}
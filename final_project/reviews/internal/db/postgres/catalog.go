@@ -0,0 +1,251 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func (pg *Postgres) UpdateProduct(ctx context.Context, p models.Product) (models.Product, error) {
+	if uuid.Validate(p.ID) != nil || p.CategoryID != "" && uuid.Validate(p.CategoryID) != nil {
+		return models.Product{}, db.ErrNotFound
+	}
+
+	p, err := scanProduct(pg.pool.QueryRow(
+		ctx,
+		"UPDATE products SET name = $2, category_id = $3 WHERE id = $1 RETURNING "+productColumns,
+		p.ID,
+		p.Name,
+		nullString(p.CategoryID),
+	))
+
+	// Несуществующая категория.
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return models.Product{}, db.ErrNotFound
+	}
+	return p, err
+}
+
+// DeleteProduct блокирует товар до проверки отзывов: добавление отзыва
+// проверяет внешний ключ и ждет завершения удаления, поэтому каскадное
+// удаление не затронет отзыв, добавленный после проверки.
+func (pg *Postgres) DeleteProduct(ctx context.Context, id string) error {
+	if uuid.Validate(id) != nil {
+		return db.ErrNotFound
+	}
+
+	return pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		var hasReviews bool
+		err := tx.QueryRow(
+			ctx,
+			`SELECT EXISTS (SELECT 1 FROM reviews WHERE product_id = p.id)
+			FROM products p WHERE p.id = $1 FOR UPDATE`,
+			id,
+		).Scan(&hasReviews)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if hasReviews {
+			return db.ErrConflict
+		}
+		_, err = tx.Exec(ctx, "DELETE FROM products WHERE id = $1", id)
+		return err
+	})
+}
+
+// categoryColumns - столбцы категории для scanCategory.
+const categoryColumns = "id, name, parent_id, created_at"
+
+func scanCategory(row pgx.Row) (models.Category, error) {
+	var (
+		c        models.Category
+		parentID *string
+	)
+	err := row.Scan(&c.ID, &c.Name, &parentID, &c.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Category{}, db.ErrNotFound
+	}
+	if parentID != nil {
+		c.ParentID = *parentID
+	}
+	return c, err
+}
+
+func (pg *Postgres) AddCategory(ctx context.Context, c models.Category) (models.Category, error) {
+	if c.ParentID != "" && uuid.Validate(c.ParentID) != nil {
+		return models.Category{}, db.ErrNotFound
+	}
+
+	c, err := scanCategory(pg.pool.QueryRow(
+		ctx,
+		"INSERT INTO categories (id, name, parent_id) VALUES ($1, $2, $3) RETURNING "+categoryColumns,
+		uuid.NewString(),
+		c.Name,
+		nullString(c.ParentID),
+	))
+
+	// Несуществующая родительская категория.
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return models.Category{}, db.ErrNotFound
+	}
+	return c, err
+}
+
+func (pg *Postgres) GetCategory(ctx context.Context, id string) (models.Category, error) {
+	if uuid.Validate(id) != nil {
+		return models.Category{}, db.ErrNotFound
+	}
+
+	return scanCategory(pg.pool.QueryRow(
+		ctx,
+		"SELECT "+categoryColumns+" FROM categories WHERE id = $1",
+		id,
+	))
+}
+
+func (pg *Postgres) ListCategories(ctx context.Context) ([]models.Category, error) {
+	rows, err := pg.pool.Query(ctx, "SELECT "+categoryColumns+" FROM categories ORDER BY name, created_at")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []models.Category{}
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// UpdateCategory проверяет, что новый родитель не входит в поддерево
+// категории. Переносы категорий выполняются по одному (блокировка таблицы),
+// иначе два параллельных переноса могли бы образовать цикл.
+func (pg *Postgres) UpdateCategory(ctx context.Context, c models.Category) (models.Category, error) {
+	if uuid.Validate(c.ID) != nil || c.ParentID != "" && uuid.Validate(c.ParentID) != nil {
+		return models.Category{}, db.ErrNotFound
+	}
+
+	var res models.Category
+	err := pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+			return err
+		}
+
+		if c.ParentID != "" {
+			var found, cycle bool
+			err := tx.QueryRow(
+				ctx,
+				`WITH RECURSIVE ancestors AS (
+					SELECT id, parent_id FROM categories WHERE id = $1
+					UNION ALL
+					SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+				)
+				SELECT count(*) > 0, coalesce(bool_or(id = $2), false) FROM ancestors`,
+				c.ParentID,
+				c.ID,
+			).Scan(&found, &cycle)
+			if err != nil {
+				return err
+			}
+			if !found {
+				return db.ErrNotFound
+			}
+			if cycle {
+				return db.ErrConflict
+			}
+		}
+
+		var err error
+		res, err = scanCategory(tx.QueryRow(
+			ctx,
+			"UPDATE categories SET name = $2, parent_id = $3 WHERE id = $1 RETURNING "+categoryColumns,
+			c.ID,
+			c.Name,
+			nullString(c.ParentID),
+		))
+		return err
+	})
+	return res, err
+}
+
+// DeleteCategory блокирует категорию до проверки: добавление подкатегории
+// или товара проверяет внешний ключ и ждет завершения удаления.
+func (pg *Postgres) DeleteCategory(ctx context.Context, id string) error {
+	if uuid.Validate(id) != nil {
+		return db.ErrNotFound
+	}
+
+	return pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		var used bool
+		err := tx.QueryRow(
+			ctx,
+			`SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = c.id)
+				OR EXISTS (SELECT 1 FROM products WHERE category_id = c.id)
+			FROM categories c WHERE c.id = $1 FOR UPDATE`,
+			id,
+		).Scan(&used)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if used {
+			return db.ErrConflict
+		}
+		_, err = tx.Exec(ctx, "DELETE FROM categories WHERE id = $1", id)
+		return err
+	})
+}
+
+func (pg *Postgres) CategoryAggregates(ctx context.Context) (map[string]db.CategoryAggregate, error) {
+	rows, err := pg.pool.Query(ctx, `
+		SELECT p.category_id, count(*),
+			coalesce(sum(r.positive), 0), coalesce(sum(r.neutral), 0), coalesce(sum(r.negative), 0),
+			coalesce(sum(r.weight_sum), 0), coalesce(sum(r.score_sum), 0)
+		FROM products p
+		LEFT JOIN product_ratings r ON r.product_id = p.id
+		WHERE p.category_id IS NOT NULL
+		GROUP BY p.category_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make(map[string]db.CategoryAggregate)
+	for rows.Next() {
+		var (
+			id string
+			a  db.CategoryAggregate
+		)
+		err := rows.Scan(
+			&id,
+			&a.Products,
+			&a.Rating.Positive,
+			&a.Rating.Neutral,
+			&a.Rating.Negative,
+			&a.Rating.WeightSum,
+			&a.Rating.ScoreSum,
+		)
+		if err != nil {
+			return nil, err
+		}
+		res[id] = a
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"go-masters/final_project/reviews/internal/chat"
+	"go-masters/final_project/reviews/internal/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func (pg *Postgres) CreateSession(ctx context.Context, s chat.Session) (chat.Session, error) {
+	s.ID = uuid.NewString()
+	s.Messages = []chat.Message{}
+	err := pg.pool.QueryRow(
+		ctx,
+		"INSERT INTO chat_sessions (id, model, system) VALUES ($1, $2, $3) RETURNING created_at, updated_at",
+		s.ID,
+		s.Model,
+		s.System,
+	).Scan(&s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+func (pg *Postgres) GetSession(ctx context.Context, id string) (chat.Session, error) {
+	if uuid.Validate(id) != nil {
+		return chat.Session{}, db.ErrNotFound
+	}
+
+	var s chat.Session
+	err := pg.pool.QueryRow(
+		ctx,
+		"SELECT id, model, system, created_at, updated_at FROM chat_sessions WHERE id = $1",
+		id,
+	).Scan(&s.ID, &s.Model, &s.System, &s.CreatedAt, &s.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return chat.Session{}, db.ErrNotFound
+	}
+	if err != nil {
+		return chat.Session{}, err
+	}
+
+	rows, err := pg.pool.Query(
+		ctx,
+		"SELECT role, content, created_at FROM chat_messages WHERE session_id = $1 ORDER BY seq",
+		id,
+	)
+	if err != nil {
+		return chat.Session{}, err
+	}
+	defer rows.Close()
+
+	s.Messages = []chat.Message{}
+	for rows.Next() {
+		var m chat.Message
+		if err := rows.Scan(&m.Role, &m.Content, &m.CreatedAt); err != nil {
+			return chat.Session{}, err
+		}
+		s.Messages = append(s.Messages, m)
+	}
+	return s, rows.Err()
+}
+
+func (pg *Postgres) AppendMessages(ctx context.Context, id string, seen int, msgs ...chat.Message) error {
+	if uuid.Validate(id) != nil {
+		return db.ErrNotFound
+	}
+
+	return pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		// Счетчик сообщений меняется только если история не изменилась
+		// с момента чтения; строка сессии блокируется до конца транзакции.
+		tag, err := tx.Exec(
+			ctx,
+			`UPDATE chat_sessions SET message_count = message_count + $3, updated_at = now()
+			WHERE id = $1 AND message_count = $2`,
+			id,
+			seen,
+			len(msgs),
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			var exists bool
+			err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM chat_sessions WHERE id = $1)", id).Scan(&exists)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return db.ErrNotFound
+			}
+			return chat.ErrConflict
+		}
+
+		batch := &pgx.Batch{}
+		for i, m := range msgs {
+			batch.Queue(
+				"INSERT INTO chat_messages (session_id, seq, role, content, created_at) VALUES ($1, $2, $3, $4, $5)",
+				id,
+				seen+i,
+				m.Role,
+				m.Content,
+				m.CreatedAt,
+			)
+		}
+		return tx.SendBatch(ctx, batch).Close()
+	})
+}
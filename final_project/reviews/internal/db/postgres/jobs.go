@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/webhook"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// enqueue ставит отзыв в очередь классификации в рамках транзакции.
+// Прежнее задание отзыва, в том числе в dead-letter, сбрасывается.
+func enqueue(ctx context.Context, tx pgx.Tx, reviewID string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO classification_jobs (review_id) VALUES ($1)
+		ON CONFLICT (review_id) DO UPDATE
+		SET state = $2, attempts = 0, run_at = now(), token = NULL,
+			last_error = NULL, updated_at = now()`,
+		reviewID,
+		jobs.StateQueued,
+	)
+	return err
+}
+
+// ClaimJobs реализует jobs.Store. Задания арендуются сдвигом run_at,
+// а SKIP LOCKED позволяет нескольким экземплярам сервиса работать параллельно.
+// Все задания пакета получают общий токен захвата.
+func (pg *Postgres) ClaimJobs(ctx context.Context, limit int, lease time.Duration) ([]jobs.Job, error) {
+	rows, err := pg.pool.Query(ctx, `
+		UPDATE classification_jobs j
+		SET attempts = j.attempts + 1,
+			run_at = now() + $2::interval,
+			token = $3,
+			updated_at = now()
+		FROM reviews r
+		WHERE r.id = j.review_id AND j.review_id IN (
+			SELECT review_id FROM classification_jobs
+			WHERE state = $4 AND run_at <= now()
+			ORDER BY run_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING j.review_id, r.text, r.language, j.attempts, j.token`,
+		limit,
+		lease,
+		uuid.NewString(),
+		jobs.StateQueued,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (jobs.Job, error) {
+		var j jobs.Job
+		err := row.Scan(&j.ReviewID, &j.Text, &j.Language, &j.Attempt, &j.Token)
+		return j, err
+	})
+}
+
+// CompleteJob реализует jobs.Store.
+func (pg *Postgres) CompleteJob(ctx context.Context, job jobs.Job, s models.Sentiment) error {
+	return pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(
+			ctx,
+			"DELETE FROM classification_jobs WHERE review_id = $1 AND token = $2",
+			job.ReviewID,
+			job.Token,
+		)
+		// Задание захвачено заново или отзыв удален - результат устарел.
+		if err != nil || tag.RowsAffected() == 0 {
+			return err
+		}
+		if err := setSentiment(ctx, tx, job.ReviewID, s); err != nil {
+			return err
+		}
+		// Уведомления сохраняются в той же транзакции, что и результат.
+		r, err := lockReview(ctx, tx, job.ReviewID)
+		if err != nil {
+			return err
+		}
+		return publish(ctx, tx, webhook.ReviewClassified(r))
+	})
+}
+
+// RetryJob реализует jobs.Store.
+func (pg *Postgres) RetryJob(ctx context.Context, job jobs.Job, at time.Time, reason string) error {
+	_, err := pg.pool.Exec(ctx, `
+		UPDATE classification_jobs
+		SET run_at = $3, last_error = $4, updated_at = now()
+		WHERE review_id = $1 AND token = $2`,
+		job.ReviewID,
+		job.Token,
+		at,
+		reason,
+	)
+	return err
+}
+
+// FailJob реализует jobs.Store.
+func (pg *Postgres) FailJob(ctx context.Context, job jobs.Job, reason string) error {
+	return pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			UPDATE classification_jobs
+			SET state = $3, last_error = $4, updated_at = now()
+			WHERE review_id = $1 AND token = $2`,
+			job.ReviewID,
+			job.Token,
+			jobs.StateDead,
+			reason,
+		)
+		if err != nil || tag.RowsAffected() == 0 {
+			return err
+		}
+		return setSentiment(ctx, tx, job.ReviewID, models.Sentiment{Status: models.SentimentFailed})
+	})
+}
+
+// setSentiment сохраняет настроение отзыва и обновляет рейтинг товара.
+func setSentiment(ctx context.Context, tx pgx.Tx, reviewID string, s models.Sentiment) error {
+	r, err := lockReview(ctx, tx, reviewID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE reviews
+		SET sentiment_status = $2, sentiment_label = $3, sentiment_confidence = $4,
+			sentiment_model = $5, sentiment_prompt_version = $6
+		WHERE id = $1`,
+		reviewID,
+		s.Status,
+		s.Label,
+		s.Confidence,
+		s.Model,
+		s.PromptVersion,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM review_aspects WHERE review_id = $1", reviewID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO review_aspects (review_id, aspect, label, confidence)
+		SELECT $1, a.aspect, a.label, a.confidence
+		FROM jsonb_to_recordset($2::jsonb) AS a(aspect text, label text, confidence float8)`,
+		reviewID,
+		aspectsArg(s),
+	)
+	if err != nil {
+		return err
+	}
+	updated := r
+	updated.Sentiment = s
+	return updateRating(ctx, tx, r, updated)
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func (pg *Postgres) ModerationQueue(ctx context.Context, limit int) ([]models.Review, error) {
+	rows, err := pg.pool.Query(
+		ctx,
+		"SELECT "+reviewColumns+" FROM reviews WHERE status = $1 ORDER BY created_at LIMIT $2",
+		models.ReviewPending,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []models.Review{}
+	for rows.Next() {
+		r, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, rows.Err()
+}
+
+func (pg *Postgres) Moderate(ctx context.Context, reviewID string, m models.Moderation) (models.Review, error) {
+	if uuid.Validate(reviewID) != nil {
+		return models.Review{}, db.ErrNotFound
+	}
+
+	var res models.Review
+	err := pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		r, err := lockReview(ctx, tx, reviewID)
+		if err != nil {
+			return err
+		}
+		if r.Moderation.Status != models.ReviewPending {
+			return db.ErrConflict
+		}
+
+		res, err = scanReview(tx.QueryRow(
+			ctx,
+			`UPDATE reviews
+			SET status = $2, moderation_reasons = $3, moderated_by = $4, moderated_at = $5
+			WHERE id = $1
+			RETURNING `+reviewColumns,
+			reviewID,
+			m.Status,
+			reasons(m),
+			m.Moderator,
+			m.ModeratedAt,
+		))
+		if err != nil || m.Status != models.ReviewPublished {
+			return err
+		}
+		return enqueue(ctx, tx, reviewID)
+	})
+	return res, err
+}
+
+// published возвращает результат модерации или статус "опубликован",
+// если модерация не выполнялась.
+func published(m models.Moderation) models.Moderation {
+	if m.Status == "" {
+		return models.Moderation{Status: models.ReviewPublished}
+	}
+	return m
+}
+
+// unmoderated возвращает результат модерации измененного отзыва:
+// без результата отзыв ждет решения модератора.
+func unmoderated(m models.Moderation) models.Moderation {
+	if m.Status == "" {
+		return models.Moderation{Status: models.ReviewPending}
+	}
+	return m
+}
+
+// reasons возвращает причины модерации для столбца text[] NOT NULL.
+func reasons(m models.Moderation) []string {
+	if m.Reasons == nil {
+		return []string{}
+	}
+	return m.Reasons
+}
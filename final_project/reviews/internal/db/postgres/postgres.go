@@ -0,0 +1,369 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+)
+
+// Коды ошибок PostgreSQL.
+const (
+	// Нарушение внешнего ключа.
+	foreignKeyViolation = "23503"
+	// Нарушение уникальности.
+	uniqueViolation = "23505"
+)
+
+type Postgres struct {
+	pool *pgxpool.Pool
+}
+
+func New(connstr string) (*Postgres, error) {
+	pool, err := pgxpool.New(context.Background(), connstr)
+	if err != nil {
+		return nil, err
+	}
+
+	pg := Postgres{pool: pool}
+
+	err = pg.applyMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pg, nil
+}
+
+func (pg *Postgres) applyMigrations() error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	parent := filepath.Dir(wd)
+	migrationsFS := os.DirFS(parent)
+	goose.SetBaseFS(migrationsFS)
+
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+
+	db := stdlib.OpenDBFromPool(pg.pool)
+	if err := goose.Up(db, "migrations"); err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+func (pg *Postgres) AddProduct(ctx context.Context, p models.Product) (models.Product, error) {
+	if p.CategoryID != "" && uuid.Validate(p.CategoryID) != nil {
+		return models.Product{}, db.ErrNotFound
+	}
+
+	p, err := scanProduct(pg.pool.QueryRow(
+		ctx,
+		"INSERT INTO products (id, name, category_id) VALUES ($1, $2, $3) RETURNING "+productColumns,
+		uuid.NewString(),
+		p.Name,
+		nullString(p.CategoryID),
+	))
+
+	// Товар в несуществующей категории.
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return models.Product{}, db.ErrNotFound
+	}
+	return p, err
+}
+
+func (pg *Postgres) GetProduct(ctx context.Context, id string) (models.Product, error) {
+	if uuid.Validate(id) != nil {
+		return models.Product{}, db.ErrNotFound
+	}
+
+	return scanProduct(pg.pool.QueryRow(
+		ctx,
+		"SELECT "+productColumns+" FROM products WHERE id = $1",
+		id,
+	))
+}
+
+func (pg *Postgres) ListProducts(ctx context.Context) ([]models.Product, error) {
+	rows, err := pg.pool.Query(ctx, "SELECT "+productColumns+" FROM products ORDER BY created_at")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []models.Product{}
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+
+	return products, rows.Err()
+}
+
+// productColumns - столбцы товара для scanProduct.
+const productColumns = "id, name, category_id, created_at"
+
+func scanProduct(row pgx.Row) (models.Product, error) {
+	var (
+		p          models.Product
+		categoryID *string
+	)
+	err := row.Scan(&p.ID, &p.Name, &categoryID, &p.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Product{}, db.ErrNotFound
+	}
+	if categoryID != nil {
+		p.CategoryID = *categoryID
+	}
+	return p, err
+}
+
+// reviewColumns - столбцы отзыва для scanReview. Аспекты выбираются
+// коррелированным подзапросом; в review_aspects нет столбца id, поэтому
+// id в нем относится к отзыву внешнего запроса.
+const reviewColumns = `id, product_id, author, text, rating, language,
+	status, moderation_reasons, moderated_by, moderated_at,
+	sentiment_status, sentiment_label, sentiment_confidence,
+	sentiment_model, sentiment_prompt_version,
+	(SELECT jsonb_agg(jsonb_build_object(
+		'aspect', aspect, 'label', label, 'confidence', confidence) ORDER BY aspect)
+	FROM review_aspects WHERE review_id = id),
+	duplicate_of, duplicate_similarity,
+	helpful_up, helpful_down,
+	created_at, updated_at`
+
+func scanReview(row pgx.Row) (models.Review, error) {
+	var (
+		r      models.Review
+		dupOf  *string
+		dupSim float64
+	)
+	err := row.Scan(
+		&r.ID,
+		&r.ProductID,
+		&r.Author,
+		&r.Text,
+		&r.Rating,
+		&r.Language,
+		&r.Moderation.Status,
+		&r.Moderation.Reasons,
+		&r.Moderation.Moderator,
+		&r.Moderation.ModeratedAt,
+		&r.Sentiment.Status,
+		&r.Sentiment.Label,
+		&r.Sentiment.Confidence,
+		&r.Sentiment.Model,
+		&r.Sentiment.PromptVersion,
+		&r.Sentiment.Aspects,
+		&dupOf,
+		&dupSim,
+		&r.Helpful.Up,
+		&r.Helpful.Down,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Review{}, db.ErrNotFound
+	}
+	if dupOf != nil {
+		r.Duplicate = &models.Duplicate{ReviewID: *dupOf, Similarity: dupSim}
+	}
+	return r, err
+}
+
+func (pg *Postgres) AddReview(ctx context.Context, r models.Review) (models.Review, error) {
+	if uuid.Validate(r.ProductID) != nil {
+		return models.Review{}, db.ErrNotFound
+	}
+
+	mod := published(r.Moderation)
+	err := pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		var err error
+		r, err = scanReview(tx.QueryRow(
+			ctx,
+			`INSERT INTO reviews (id, product_id, author, text, rating, language,
+				status, moderation_reasons, moderated_by, moderated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING `+reviewColumns,
+			uuid.NewString(),
+			r.ProductID,
+			r.Author,
+			r.Text,
+			r.Rating,
+			r.Language,
+			mod.Status,
+			reasons(mod),
+			mod.Moderator,
+			mod.ModeratedAt,
+		))
+		if err != nil || r.Moderation.Status != models.ReviewPublished {
+			return err
+		}
+		return enqueue(ctx, tx, r.ID)
+	})
+
+	// Отзыв к несуществующему товару.
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return models.Review{}, db.ErrNotFound
+	}
+	if err != nil {
+		return models.Review{}, err
+	}
+	return r, nil
+}
+
+func (pg *Postgres) GetReview(ctx context.Context, productID, id string) (models.Review, error) {
+	if uuid.Validate(productID) != nil || uuid.Validate(id) != nil {
+		return models.Review{}, db.ErrNotFound
+	}
+
+	return scanReview(pg.pool.QueryRow(
+		ctx,
+		"SELECT "+reviewColumns+" FROM reviews WHERE product_id = $1 AND id = $2",
+		productID,
+		id,
+	))
+}
+
+func (pg *Postgres) ListReviews(ctx context.Context, productID, language string) ([]models.Review, error) {
+	if _, err := pg.GetProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	rows, err := pg.pool.Query(
+		ctx,
+		`SELECT `+reviewColumns+` FROM reviews
+		WHERE product_id = $1 AND status = $2 AND ($3 = '' OR language = $3)
+		ORDER BY created_at`,
+		productID,
+		models.ReviewPublished,
+		language,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []models.Review{}
+	for rows.Next() {
+		r, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r)
+	}
+
+	return reviews, rows.Err()
+}
+
+func (pg *Postgres) UpdateReview(ctx context.Context, r models.Review, seenText string) (models.Review, error) {
+	if uuid.Validate(r.ProductID) != nil || uuid.Validate(r.ID) != nil {
+		return models.Review{}, db.ErrNotFound
+	}
+
+	var res models.Review
+	err := pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		old, err := lockReview(ctx, tx, r.ID)
+		if err != nil {
+			return err
+		}
+		if old.ProductID != r.ProductID {
+			return db.ErrNotFound
+		}
+		if old.Text != seenText {
+			return db.ErrConflict
+		}
+
+		// Измененный текст требует повторной классификации и индексации.
+		changed := old.Text != r.Text
+		if changed {
+			if err := resetEmbedding(ctx, tx, r.ID); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, "DELETE FROM review_aspects WHERE review_id = $1", r.ID); err != nil {
+				return err
+			}
+		}
+		mod := unmoderated(r.Moderation)
+		res, err = scanReview(tx.QueryRow(
+			ctx,
+			`UPDATE reviews SET author = $3, text = $4, rating = $5, updated_at = now(),
+				sentiment_status = CASE WHEN $6 THEN $7 ELSE sentiment_status END,
+				sentiment_label = CASE WHEN $6 THEN '' ELSE sentiment_label END,
+				sentiment_confidence = CASE WHEN $6 THEN 0 ELSE sentiment_confidence END,
+				sentiment_model = CASE WHEN $6 THEN '' ELSE sentiment_model END,
+				sentiment_prompt_version = CASE WHEN $6 THEN '' ELSE sentiment_prompt_version END,
+				status = CASE WHEN $6 THEN $8 ELSE status END,
+				moderation_reasons = CASE WHEN $6 THEN $9 ELSE moderation_reasons END,
+				moderated_by = CASE WHEN $6 THEN $10 ELSE moderated_by END,
+				moderated_at = CASE WHEN $6 THEN $11 ELSE moderated_at END,
+				language = CASE WHEN $6 THEN $12 ELSE language END
+			WHERE product_id = $1 AND id = $2
+			RETURNING `+reviewColumns,
+			r.ProductID,
+			r.ID,
+			r.Author,
+			r.Text,
+			r.Rating,
+			changed,
+			models.SentimentPending,
+			mod.Status,
+			reasons(mod),
+			mod.Moderator,
+			mod.ModeratedAt,
+			r.Language,
+		))
+		if err != nil || !changed {
+			return err
+		}
+		if err := updateRating(ctx, tx, old, res); err != nil {
+			return err
+		}
+		if res.Moderation.Status != models.ReviewPublished {
+			_, err := tx.Exec(ctx, "DELETE FROM classification_jobs WHERE review_id = $1", r.ID)
+			return err
+		}
+		return enqueue(ctx, tx, r.ID)
+	})
+	return res, err
+}
+
+func (pg *Postgres) DeleteReview(ctx context.Context, productID, id string) error {
+	if uuid.Validate(productID) != nil || uuid.Validate(id) != nil {
+		return db.ErrNotFound
+	}
+
+	return pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		if err := resetEmbedding(ctx, tx, id); err != nil {
+			return err
+		}
+		r, err := scanReview(tx.QueryRow(
+			ctx,
+			"DELETE FROM reviews WHERE product_id = $1 AND id = $2 RETURNING "+reviewColumns,
+			productID,
+			id,
+		))
+		if err != nil {
+			return err
+		}
+		return updateRating(ctx, tx, r, models.Review{})
+	})
+}
@@ -0,0 +1,222 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/rating"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ratingSet прибавляет вставляемое изменение к существующему агрегату pr.
+// Как и в rating.Aggregate.Add, суммы весов агрегата без отзывов обнуляются.
+const ratingSet = `
+	SET positive = pr.positive + excluded.positive,
+		neutral = pr.neutral + excluded.neutral,
+		negative = pr.negative + excluded.negative,
+		weight_sum = CASE WHEN ` + ratingEmpty + ` THEN 0 ELSE pr.weight_sum + excluded.weight_sum END,
+		score_sum = CASE WHEN ` + ratingEmpty + ` THEN 0 ELSE pr.score_sum + excluded.score_sum END,
+		updated_at = now()`
+
+// ratingEmpty - условие, что после изменения в агрегате не остается отзывов.
+const ratingEmpty = `pr.positive + excluded.positive + pr.neutral + excluded.neutral
+		+ pr.negative + excluded.negative = 0`
+
+// ratingConflict обновляет агрегат товара в product_ratings.
+const ratingConflict = `
+	ON CONFLICT (product_id) DO UPDATE` + ratingSet
+
+// dailyConflict обновляет дневной агрегат товара в product_rating_daily.
+const dailyConflict = `
+	ON CONFLICT (product_id, day) DO UPDATE` + ratingSet
+
+// aspectConflict обновляет агрегат аспекта товара в product_aspect_ratings.
+const aspectConflict = `
+	ON CONFLICT (product_id, aspect) DO UPDATE` + ratingSet
+
+// languageConflict обновляет агрегат языка товара в product_language_ratings.
+const languageConflict = `
+	ON CONFLICT (product_id, language) DO UPDATE` + ratingSet
+
+// groupColumns описывает записи параметра jsonb из groupsArg
+// для jsonb_to_recordset.
+const groupColumns = ` AS g(key text, positive integer, neutral integer, negative integer,
+		weight_sum float8, score_sum float8)`
+
+// groupDelta - изменение агрегата группы отзывов товара.
+type groupDelta struct {
+	Key       string  `json:"key"`
+	Positive  int     `json:"positive"`
+	Neutral   int     `json:"neutral"`
+	Negative  int     `json:"negative"`
+	WeightSum float64 `json:"weight_sum"`
+	ScoreSum  float64 `json:"score_sum"`
+}
+
+// groupsArg возвращает изменения агрегатов групп для параметра jsonb (не nil).
+func groupsArg(groups map[string]rating.Aggregate) []groupDelta {
+	res := make([]groupDelta, 0, len(groups))
+	for key, a := range groups {
+		res = append(res, groupDelta{
+			Key:       key,
+			Positive:  a.Positive,
+			Neutral:   a.Neutral,
+			Negative:  a.Negative,
+			WeightSum: a.WeightSum,
+			ScoreSum:  a.ScoreSum,
+		})
+	}
+	return res
+}
+
+// aspectsDelta возвращает изменения агрегатов аспектов товара
+// при смене настроения отзыва from -> to.
+func aspectsDelta(from, to models.Sentiment, createdAt time.Time) map[string]rating.Aggregate {
+	return rating.DiffGroups(rating.OfAspects(from, createdAt), rating.OfAspects(to, createdAt))
+}
+
+// languagesDelta возвращает изменения агрегатов языков товара при смене
+// отзыва from -> to: вместе с текстом отзыва может измениться его язык.
+func languagesDelta(from, to models.Review) map[string]rating.Aggregate {
+	return rating.DiffGroups(
+		map[string]rating.Aggregate{from.Language: rating.Of(from.Sentiment, from.CreatedAt)},
+		map[string]rating.Aggregate{to.Language: rating.Of(to.Sentiment, from.CreatedAt)},
+	)
+}
+
+// updateRating применяет к агрегатам рейтинга товара, дневному агрегату
+// и агрегатам аспектов и языков изменение отзыва from -> to в рамках
+// транзакции. Товар и время создания отзыва берутся из from.
+func updateRating(ctx context.Context, tx pgx.Tx, from, to models.Review) error {
+	d := rating.Of(to.Sentiment, from.CreatedAt).Sub(rating.Of(from.Sentiment, from.CreatedAt))
+	aspects := aspectsDelta(from.Sentiment, to.Sentiment, from.CreatedAt)
+	languages := languagesDelta(from, to)
+	if d.IsZero() && len(aspects) == 0 && len(languages) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		WITH daily AS (
+			INSERT INTO product_rating_daily AS pr
+				(product_id, day, positive, neutral, negative, weight_sum, score_sum)
+			VALUES ($1, $7, $2, $3, $4, $5, $6)`+dailyConflict+`
+		), aspects AS (
+			INSERT INTO product_aspect_ratings AS pr
+				(product_id, aspect, positive, neutral, negative, weight_sum, score_sum)
+			SELECT $1, g.key, g.positive, g.neutral, g.negative, g.weight_sum, g.score_sum
+			FROM jsonb_to_recordset($8::jsonb)`+groupColumns+aspectConflict+`
+		), languages AS (
+			INSERT INTO product_language_ratings AS pr
+				(product_id, language, positive, neutral, negative, weight_sum, score_sum)
+			SELECT $1, g.key, g.positive, g.neutral, g.negative, g.weight_sum, g.score_sum
+			FROM jsonb_to_recordset($9::jsonb)`+groupColumns+languageConflict+`
+		)
+		INSERT INTO product_ratings AS pr
+			(product_id, positive, neutral, negative, weight_sum, score_sum)
+		VALUES ($1, $2, $3, $4, $5, $6)`+ratingConflict,
+		from.ProductID,
+		d.Positive,
+		d.Neutral,
+		d.Negative,
+		d.WeightSum,
+		d.ScoreSum,
+		db.Day(from.CreatedAt),
+		groupsArg(aspects),
+		groupsArg(languages),
+	)
+	return err
+}
+
+func (pg *Postgres) ProductRating(ctx context.Context, productID string) (rating.Aggregate, error) {
+	if _, err := pg.GetProduct(ctx, productID); err != nil {
+		return rating.Aggregate{}, err
+	}
+
+	var a rating.Aggregate
+	err := pg.pool.QueryRow(
+		ctx,
+		`SELECT positive, neutral, negative, weight_sum, score_sum
+		FROM product_ratings WHERE product_id = $1`,
+		productID,
+	).Scan(&a.Positive, &a.Neutral, &a.Negative, &a.WeightSum, &a.ScoreSum)
+	// Товар без классифицированных отзывов.
+	if errors.Is(err, pgx.ErrNoRows) {
+		return rating.Aggregate{}, nil
+	}
+	return a, err
+}
+
+func (pg *Postgres) AspectRatings(ctx context.Context, productID string) (map[string]rating.Aggregate, error) {
+	if _, err := pg.GetProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	return pg.groupRatings(ctx, "product_aspect_ratings", "aspect", productID)
+}
+
+func (pg *Postgres) LanguageRatings(ctx context.Context, productID string) (map[string]rating.Aggregate, error) {
+	if _, err := pg.GetProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	return pg.groupRatings(ctx, "product_language_ratings", "language", productID)
+}
+
+// groupRatings возвращает непустые агрегаты групп отзывов товара
+// из таблицы агрегатов table с ключом группы в столбце key.
+func (pg *Postgres) groupRatings(ctx context.Context, table, key, productID string) (map[string]rating.Aggregate, error) {
+	// Группы, вклады всех отзывов в которые вычтены, не возвращаются.
+	rows, err := pg.pool.Query(
+		ctx,
+		`SELECT `+key+`, positive, neutral, negative, weight_sum, score_sum
+		FROM `+table+`
+		WHERE product_id = $1 AND positive + neutral + negative > 0`,
+		productID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make(map[string]rating.Aggregate)
+	for rows.Next() {
+		var (
+			group string
+			a     rating.Aggregate
+		)
+		if err := rows.Scan(&group, &a.Positive, &a.Neutral, &a.Negative, &a.WeightSum, &a.ScoreSum); err != nil {
+			return nil, err
+		}
+		res[group] = a
+	}
+	return res, rows.Err()
+}
+
+// aspectsArg возвращает аспекты настроения для параметра jsonb;
+// пустой массив вместо null, который jsonb_to_recordset не принимает.
+func aspectsArg(s models.Sentiment) []models.AspectSentiment {
+	if s.Aspects == nil {
+		return []models.AspectSentiment{}
+	}
+	return s.Aspects
+}
+
+// aspectNames возвращает имена аспектов настроения (не nil).
+func aspectNames(s models.Sentiment) []string {
+	names := make([]string, 0, len(s.Aspects))
+	for _, a := range s.Aspects {
+		names = append(names, a.Aspect)
+	}
+	return names
+}
+
+// lockReview блокирует отзыв до конца транзакции и возвращает его.
+func lockReview(ctx context.Context, tx pgx.Tx, reviewID string) (models.Review, error) {
+	return scanReview(tx.QueryRow(
+		ctx,
+		"SELECT "+reviewColumns+" FROM reviews WHERE id = $1 FOR UPDATE",
+		reviewID,
+	))
+}
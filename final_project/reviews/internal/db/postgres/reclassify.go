@@ -0,0 +1,301 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/rating"
+	"go-masters/final_project/reviews/internal/reclassify"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const runColumns = `id, filter, cursor_created_at, cursor_id, processed, failed,
+	status, error, created_at, updated_at, leased_until`
+
+func scanRun(row pgx.Row) (reclassify.Run, error) {
+	var (
+		run      reclassify.Run
+		cursorAt *time.Time
+		cursorID *string
+		leased   *time.Time
+	)
+	err := row.Scan(
+		&run.ID,
+		&run.Filter,
+		&cursorAt,
+		&cursorID,
+		&run.Processed,
+		&run.Failed,
+		&run.Status,
+		&run.Error,
+		&run.CreatedAt,
+		&run.UpdatedAt,
+		&leased,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return reclassify.Run{}, db.ErrNotFound
+	}
+	if leased != nil {
+		run.LeasedUntil = *leased
+	}
+	if cursorAt != nil && cursorID != nil {
+		run.Cursor = reclassify.Cursor{CreatedAt: *cursorAt, ReviewID: *cursorID}
+	}
+	return run, err
+}
+
+func (pg *Postgres) CreateRun(ctx context.Context, f reclassify.Filter) (reclassify.Run, error) {
+	return scanRun(pg.pool.QueryRow(
+		ctx,
+		"INSERT INTO reclassify_runs (id, filter, status) VALUES ($1, $2, $3) RETURNING "+runColumns,
+		uuid.NewString(),
+		f,
+		reclassify.StatusRunning,
+	))
+}
+
+func (pg *Postgres) GetRun(ctx context.Context, id string) (reclassify.Run, error) {
+	if uuid.Validate(id) != nil {
+		return reclassify.Run{}, db.ErrNotFound
+	}
+	return scanRun(pg.pool.QueryRow(ctx, "SELECT "+runColumns+" FROM reclassify_runs WHERE id = $1", id))
+}
+
+func (pg *Postgres) ListRuns(ctx context.Context, status string) ([]reclassify.Run, error) {
+	rows, err := pg.pool.Query(
+		ctx,
+		"SELECT "+runColumns+" FROM reclassify_runs WHERE status = $1 ORDER BY created_at",
+		status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []reclassify.Run{}
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// ClaimRun реализует reclassify.Store. Запуск захватывается, если он
+// не арендован или аренда истекла.
+func (pg *Postgres) ClaimRun(ctx context.Context, id string, lease time.Duration) (reclassify.Run, error) {
+	if uuid.Validate(id) != nil {
+		return reclassify.Run{}, db.ErrNotFound
+	}
+	token := uuid.NewString()
+	run, err := scanRun(pg.pool.QueryRow(
+		ctx,
+		`UPDATE reclassify_runs
+		SET lease_token = $2, leased_until = now() + $3::interval
+		WHERE id = $1 AND status = $4 AND (leased_until IS NULL OR leased_until <= now())
+		RETURNING `+runColumns,
+		id,
+		token,
+		lease,
+		reclassify.StatusRunning,
+	))
+	if errors.Is(err, db.ErrNotFound) {
+		// Запуск есть, но завершен или выполняется другим экземпляром.
+		if _, err := pg.GetRun(ctx, id); err != nil {
+			return reclassify.Run{}, err
+		}
+		return reclassify.Run{}, reclassify.ErrConflict
+	}
+	run.Token = token
+	return run, err
+}
+
+func (pg *Postgres) ReleaseRun(ctx context.Context, run reclassify.Run) error {
+	if uuid.Validate(run.Token) != nil {
+		return nil
+	}
+	_, err := pg.pool.Exec(
+		ctx,
+		`UPDATE reclassify_runs SET lease_token = NULL, leased_until = NULL
+		WHERE id = $1 AND lease_token = $2`,
+		run.ID,
+		run.Token,
+	)
+	return err
+}
+
+func (pg *Postgres) NextReviews(ctx context.Context, f reclassify.Filter, after reclassify.Cursor, limit int) ([]models.Review, error) {
+	rows, err := pg.pool.Query(
+		ctx,
+		`SELECT `+reviewColumns+` FROM reviews
+		WHERE sentiment_status <> $1
+			AND ($2 = '' OR product_id::text = $2)
+			AND ($3::timestamptz IS NULL OR created_at >= $3)
+			AND ($4::timestamptz IS NULL OR created_at < $4)
+			AND ($5 = '' OR sentiment_model = $5)
+			AND ($6 = '' OR sentiment_prompt_version = $6)
+			AND ($7::timestamptz IS NULL OR (created_at, id) > ($7, $8::uuid))
+			AND ($10 = '' OR language = $10)
+		ORDER BY created_at, id
+		LIMIT $9`,
+		models.SentimentPending,
+		f.ProductID,
+		nullTime(f.From),
+		nullTime(f.To),
+		f.Model,
+		f.PromptVersion,
+		nullTime(after.CreatedAt),
+		nullString(after.ReviewID),
+		limit,
+		f.Language,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []models.Review
+	for rows.Next() {
+		r, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, rows.Err()
+}
+
+// SaveBatch реализует reclassify.Store. Обновления отправляются одним пакетом
+// pgx.Batch в рамках транзакции вместе с курсором запуска.
+func (pg *Postgres) SaveBatch(ctx context.Context, run reclassify.Run, lease time.Duration, updates []reclassify.Update) error {
+	if uuid.Validate(run.Token) != nil {
+		return reclassify.ErrConflict
+	}
+	return pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		// Продление аренды блокирует запуск до конца транзакции,
+		// поэтому захватить его заново до сохранения пакета нельзя.
+		tag, err := tx.Exec(
+			ctx,
+			`UPDATE reclassify_runs SET leased_until = now() + $3::interval
+			WHERE id = $1 AND lease_token = $2`,
+			run.ID,
+			run.Token,
+			lease,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return reclassify.ErrConflict
+		}
+
+		batch := &pgx.Batch{}
+
+		for _, u := range updates {
+			old, s := u.Review.Sentiment, u.Sentiment
+			d := rating.Of(s, u.Review.CreatedAt).Sub(rating.Of(old, u.Review.CreatedAt))
+			// Отзыв обновляется, только если не изменился после чтения;
+			// вместе с ним заменяются аспекты, обновляются агрегаты рейтинга товара,
+			// его аспектов и языков и удаляется задание из dead-letter. CTE одного запроса
+			// не должны менять одну строку дважды, поэтому удаляются
+			// только аспекты, которых нет в новом результате.
+			batch.Queue(`
+				WITH upd AS (
+					UPDATE reviews
+					SET sentiment_status = $2, sentiment_label = $3, sentiment_confidence = $4,
+						sentiment_model = $5, sentiment_prompt_version = $6
+					WHERE id = $1 AND updated_at = $7
+						AND sentiment_status = $8 AND sentiment_label = $9
+						AND sentiment_confidence = $10 AND sentiment_model = $11
+						AND sentiment_prompt_version = $12
+					RETURNING id, product_id, language
+				), dead AS (
+					DELETE FROM classification_jobs j USING upd
+					WHERE j.review_id = upd.id AND j.state = $13
+				), stale_aspects AS (
+					DELETE FROM review_aspects a USING upd
+					WHERE a.review_id = upd.id AND a.aspect <> ALL($19::text[])
+				), new_aspects AS (
+					INSERT INTO review_aspects (review_id, aspect, label, confidence)
+					SELECT upd.id, a.aspect, a.label, a.confidence
+					FROM upd, jsonb_to_recordset($20::jsonb) AS a(aspect text, label text, confidence float8)
+					ON CONFLICT (review_id, aspect) DO UPDATE
+					SET label = excluded.label, confidence = excluded.confidence
+				), daily AS (
+					INSERT INTO product_rating_daily AS pr
+						(product_id, day, positive, neutral, negative, weight_sum, score_sum)
+					SELECT product_id, $21::date, $14, $15, $16, $17, $18 FROM upd`+dailyConflict+`
+				), language_ratings AS (
+					INSERT INTO product_language_ratings AS pr
+						(product_id, language, positive, neutral, negative, weight_sum, score_sum)
+					SELECT product_id, language, $14, $15, $16, $17, $18 FROM upd`+languageConflict+`
+				), aspect_ratings AS (
+					INSERT INTO product_aspect_ratings AS pr
+						(product_id, aspect, positive, neutral, negative, weight_sum, score_sum)
+					SELECT upd.product_id, g.key, g.positive, g.neutral, g.negative, g.weight_sum, g.score_sum
+					FROM upd, jsonb_to_recordset($22::jsonb)`+groupColumns+aspectConflict+`
+				)
+				INSERT INTO product_ratings AS pr
+					(product_id, positive, neutral, negative, weight_sum, score_sum)
+				SELECT product_id, $14, $15, $16, $17, $18 FROM upd`+ratingConflict,
+				u.Review.ID,
+				s.Status, s.Label, s.Confidence, s.Model, s.PromptVersion,
+				u.Review.UpdatedAt,
+				old.Status, old.Label, old.Confidence, old.Model, old.PromptVersion,
+				jobs.StateDead,
+				d.Positive, d.Neutral, d.Negative, d.WeightSum, d.ScoreSum,
+				aspectNames(s), aspectsArg(s),
+				db.Day(u.Review.CreatedAt),
+				groupsArg(aspectsDelta(old, s, u.Review.CreatedAt)),
+			)
+		}
+
+		batch.Queue(`
+			UPDATE reclassify_runs
+			SET cursor_created_at = $2, cursor_id = $3, processed = $4, failed = $5, updated_at = now()
+			WHERE id = $1`,
+			run.ID,
+			nullTime(run.Cursor.CreatedAt),
+			nullString(run.Cursor.ReviewID),
+			run.Processed,
+			run.Failed,
+		)
+
+		return tx.SendBatch(ctx, batch).Close()
+	})
+}
+
+func (pg *Postgres) SetRunStatus(ctx context.Context, id, status, reason string) error {
+	_, err := pg.pool.Exec(
+		ctx,
+		"UPDATE reclassify_runs SET status = $2, error = $3, updated_at = now() WHERE id = $1",
+		id,
+		status,
+		reason,
+	)
+	return err
+}
+
+// nullTime возвращает nil для нулевого времени.
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// nullString возвращает nil для пустой строки.
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/search"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// headlineOptions - параметры ts_headline, согласованные с search.Highlight.
+const headlineOptions = `StartSel=` + search.StartSel + `, StopSel=` + search.StopSel +
+	`, MaxFragments=3, MaxWords=20, MinWords=5, FragmentDelimiter="` + search.FragmentDelimiter + `"`
+
+// SearchReviews ищет отзывы по колонке search. Запрос разбирается
+// websearch_to_tsquery: поддерживаются фразы в кавычках, or и исключение
+// слов минусом.
+func (pg *Postgres) SearchReviews(ctx context.Context, q db.SearchQuery) ([]models.SearchResult, error) {
+	if q.ProductID != "" && uuid.Validate(q.ProductID) != nil {
+		return []models.SearchResult{}, nil
+	}
+	rows, err := pg.pool.Query(
+		ctx,
+		`WITH q AS (
+			SELECT websearch_to_tsquery('russian', $1) || websearch_to_tsquery('english', $1) AS query
+		)
+		SELECT `+reviewColumns+`,
+			ts_rank(search, q.query)::float8 AS rank,
+			ts_headline('russian', text, q.query, $6)
+		FROM reviews, q
+		WHERE search @@ q.query
+			AND status = $2
+			AND ($3::uuid IS NULL OR product_id = $3)
+			AND ($4::text IS NULL OR language = $4)
+		ORDER BY rank DESC, created_at DESC
+		LIMIT $5`,
+		q.Text,
+		models.ReviewPublished,
+		nullString(q.ProductID),
+		nullString(q.Language),
+		q.Limit,
+		headlineOptions,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.SearchResult{}
+	for rows.Next() {
+		var s models.SearchResult
+		r, err := scanReview(searchRow{Row: rows, rank: &s.Rank, highlight: &s.Highlight})
+		if err != nil {
+			return nil, err
+		}
+		s.Review = r
+		// ts_headline не экранирует текст отзыва.
+		s.Highlight = search.EscapeHeadline(s.Highlight)
+		res = append(res, s)
+	}
+	return res, rows.Err()
+}
+
+// searchRow дочитывает релевантность и подсветку, следующие за колонками
+// отзыва.
+type searchRow struct {
+	pgx.Row
+	rank      *float64
+	highlight *string
+}
+
+func (r searchRow) Scan(dest ...any) error {
+	return r.Row.Scan(append(dest, r.rank, r.highlight)...)
+}
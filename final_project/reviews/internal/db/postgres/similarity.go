@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/similarity"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func (pg *Postgres) PendingEmbeddings(ctx context.Context, model string, limit int) ([]models.Review, error) {
+	rows, err := pg.pool.Query(
+		ctx,
+		`SELECT `+reviewColumns+` FROM reviews r
+		WHERE NOT EXISTS (
+			SELECT 1 FROM review_embeddings e WHERE e.review_id = r.id AND e.model = $1
+		)
+		ORDER BY created_at, id
+		LIMIT $2`,
+		model,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []models.Review{}
+	for rows.Next() {
+		r, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, rows.Err()
+}
+
+func (pg *Postgres) NearestReviews(ctx context.Context, e similarity.Embedding, limit int) ([]models.SimilarReview, error) {
+	// Оператор <=> - косинусное расстояние pgvector.
+	rows, err := pg.pool.Query(
+		ctx,
+		`SELECT `+reviewColumns+`, n.similarity FROM reviews
+		JOIN (
+			SELECT e.review_id, 1 - (e.embedding <=> $1::vector) AS similarity
+			FROM review_embeddings e
+			JOIN reviews p ON p.id = e.review_id AND p.status = $5
+			WHERE e.model = $2 AND e.review_id <> $3
+			ORDER BY e.embedding <=> $1::vector
+			LIMIT $4
+		) n ON n.review_id = reviews.id
+		ORDER BY n.similarity DESC`,
+		vectorLiteral(e.Vector),
+		e.Model,
+		e.ReviewID,
+		limit,
+		models.ReviewPublished,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanSimilar(rows)
+}
+
+func (pg *Postgres) SaveEmbedding(ctx context.Context, r models.Review, e similarity.Embedding, dup *models.Duplicate) error {
+	var (
+		dupOf  *string
+		dupSim float64
+	)
+	if dup != nil {
+		dupOf, dupSim = &dup.ReviewID, dup.Similarity
+	}
+
+	return pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		// Вектор сохраняется, только если текст не изменился с момента чтения.
+		tag, err := tx.Exec(
+			ctx,
+			`UPDATE reviews SET duplicate_of = $3, duplicate_similarity = $4
+			WHERE id = $1 AND text = $2`,
+			r.ID,
+			r.Text,
+			dupOf,
+			dupSim,
+		)
+		if err != nil || tag.RowsAffected() == 0 {
+			return err
+		}
+		_, err = tx.Exec(
+			ctx,
+			`INSERT INTO review_embeddings (review_id, model, embedding) VALUES ($1, $2, $3::vector)
+			ON CONFLICT (review_id) DO UPDATE
+			SET model = excluded.model, embedding = excluded.embedding, indexed_at = now()`,
+			r.ID,
+			e.Model,
+			vectorLiteral(e.Vector),
+		)
+		return err
+	})
+}
+
+func (pg *Postgres) SimilarReviews(ctx context.Context, reviewID string, limit int) ([]models.SimilarReview, error) {
+	if uuid.Validate(reviewID) != nil {
+		return nil, db.ErrNotFound
+	}
+
+	var reviewExists, embeddingExists bool
+	err := pg.pool.QueryRow(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM reviews WHERE id = $1),
+			EXISTS (SELECT 1 FROM review_embeddings WHERE review_id = $1)`,
+		reviewID,
+	).Scan(&reviewExists, &embeddingExists)
+	switch {
+	case err != nil:
+		return nil, err
+	case !reviewExists:
+		return nil, db.ErrNotFound
+	case !embeddingExists:
+		return nil, db.ErrNoEmbedding
+	}
+
+	rows, err := pg.pool.Query(
+		ctx,
+		`SELECT `+reviewColumns+`, n.similarity FROM reviews
+		JOIN (
+			SELECT e.review_id, 1 - (e.embedding <=> q.embedding) AS similarity
+			FROM review_embeddings e
+			JOIN review_embeddings q ON q.model = e.model
+			JOIN reviews p ON p.id = e.review_id AND p.status = $3
+			WHERE q.review_id = $1 AND e.review_id <> q.review_id
+			ORDER BY e.embedding <=> q.embedding
+			LIMIT $2
+		) n ON n.review_id = reviews.id
+		ORDER BY n.similarity DESC`,
+		reviewID,
+		limit,
+		models.ReviewPublished,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanSimilar(rows)
+}
+
+// similarRow дочитывает сходство, следующее за колонками отзыва.
+type similarRow struct {
+	pgx.Row
+	similarity *float64
+}
+
+func (r similarRow) Scan(dest ...any) error {
+	return r.Row.Scan(append(dest, r.similarity)...)
+}
+
+func scanSimilar(rows pgx.Rows) ([]models.SimilarReview, error) {
+	defer rows.Close()
+
+	res := []models.SimilarReview{}
+	for rows.Next() {
+		var s models.SimilarReview
+		r, err := scanReview(similarRow{Row: rows, similarity: &s.Similarity})
+		if err != nil {
+			return nil, err
+		}
+		s.Review = r
+		res = append(res, s)
+	}
+	return res, rows.Err()
+}
+
+// vectorLiteral форматирует вектор в текстовом виде pgvector: [1,2,3].
+func vectorLiteral(v []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// resetEmbedding удаляет вектор отзыва id и отметки о копиях этого отзыва:
+// после изменения текста сходство нужно вычислить заново.
+func resetEmbedding(ctx context.Context, tx pgx.Tx, id string) error {
+	_, err := tx.Exec(
+		ctx,
+		`DELETE FROM review_embeddings
+		WHERE review_id = $1 OR review_id IN (SELECT id FROM reviews WHERE duplicate_of = $1)`,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE reviews SET duplicate_of = NULL, duplicate_similarity = 0 WHERE id = $1 OR duplicate_of = $1",
+		id,
+	)
+	return err
+}
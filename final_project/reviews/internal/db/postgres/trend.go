@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (pg *Postgres) RatingHistory(ctx context.Context, productID string, from, to time.Time) ([]db.DailyRating, error) {
+	if _, err := pg.GetProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	// Даты сравниваются с моментами в UTC, в котором вычисляются дни отзывов.
+	rows, err := pg.pool.Query(
+		ctx,
+		`SELECT day, positive, neutral, negative, weight_sum, score_sum
+		FROM product_rating_daily
+		WHERE product_id = $1
+			AND day >= ($2::timestamptz AT TIME ZONE 'UTC')
+			AND day < ($3::timestamptz AT TIME ZONE 'UTC')
+			AND positive + neutral + negative > 0
+		ORDER BY day`,
+		productID,
+		from,
+		to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []db.DailyRating{}
+	for rows.Next() {
+		var d db.DailyRating
+		a := &d.Rating
+		if err := rows.Scan(&d.Day, &a.Positive, &a.Neutral, &a.Negative, &a.WeightSum, &a.ScoreSum); err != nil {
+			return nil, err
+		}
+		d.Day = d.Day.UTC()
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
+
+func (pg *Postgres) ChangedRatings(ctx context.Context, since time.Time) ([]string, error) {
+	rows, err := pg.pool.Query(
+		ctx,
+		`SELECT DISTINCT product_id FROM product_rating_daily
+		WHERE updated_at >= $1 ORDER BY product_id`,
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
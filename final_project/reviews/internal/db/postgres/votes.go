@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func (pg *Postgres) Vote(ctx context.Context, productID string, v models.Vote) (models.Review, error) {
+	if uuid.Validate(productID) != nil || uuid.Validate(v.ReviewID) != nil {
+		return models.Review{}, db.ErrNotFound
+	}
+
+	var res models.Review
+	err := pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		r, err := lockReview(ctx, tx, v.ReviewID)
+		if err != nil {
+			return err
+		}
+		if r.ProductID != productID || r.Moderation.Status != models.ReviewPublished {
+			return db.ErrNotFound
+		}
+
+		// Голос пользователя, отданный с другого клиента, не заменяется.
+		tag, err := tx.Exec(
+			ctx,
+			`INSERT INTO review_votes (review_id, voter, client, helpful) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (review_id, voter) DO UPDATE
+			SET helpful = excluded.helpful, updated_at = now()
+			WHERE review_votes.client = excluded.client`,
+			v.ReviewID,
+			v.Voter,
+			v.Client,
+			v.Helpful,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return db.ErrConflict
+		}
+		res, err = countVotes(ctx, tx, v.ReviewID)
+		return err
+	})
+
+	// С клиента уже голосовал другой пользователь.
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return models.Review{}, db.ErrConflict
+	}
+	return res, err
+}
+
+func (pg *Postgres) Unvote(ctx context.Context, productID, reviewID, voter, client string) (models.Review, error) {
+	if uuid.Validate(productID) != nil || uuid.Validate(reviewID) != nil {
+		return models.Review{}, db.ErrNotFound
+	}
+
+	var res models.Review
+	err := pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		r, err := lockReview(ctx, tx, reviewID)
+		if err != nil {
+			return err
+		}
+		if r.ProductID != productID {
+			return db.ErrNotFound
+		}
+
+		// Голос с другого клиента не удаляется. Голоса меняются только под
+		// блокировкой отзыва, поэтому проверка не расходится с удалением.
+		var other bool
+		err = tx.QueryRow(
+			ctx,
+			`SELECT EXISTS (
+				SELECT 1 FROM review_votes WHERE review_id = $1 AND voter = $2 AND client <> $3
+			)`,
+			reviewID,
+			voter,
+			client,
+		).Scan(&other)
+		if err != nil {
+			return err
+		}
+		if other {
+			return db.ErrNotFound
+		}
+
+		_, err = tx.Exec(ctx, "DELETE FROM review_votes WHERE review_id = $1 AND voter = $2", reviewID, voter)
+		if err != nil {
+			return err
+		}
+		res, err = countVotes(ctx, tx, reviewID)
+		return err
+	})
+	return res, err
+}
+
+// countVotes пересчитывает счетчики голосов отзыва. Отзыв заблокирован
+// вызывающим, поэтому параллельные голоса не теряются.
+func countVotes(ctx context.Context, tx pgx.Tx, reviewID string) (models.Review, error) {
+	return scanReview(tx.QueryRow(
+		ctx,
+		`UPDATE reviews SET
+			helpful_up = (SELECT count(*) FROM review_votes WHERE review_id = $1 AND helpful),
+			helpful_down = (SELECT count(*) FROM review_votes WHERE review_id = $1 AND NOT helpful)
+		WHERE id = $1
+		RETURNING `+reviewColumns,
+		reviewID,
+	))
+}
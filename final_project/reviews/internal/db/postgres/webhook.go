@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/webhook"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const webhookColumns = "id, url, secret, events, product_ids, labels, created_at, updated_at"
+
+func scanWebhook(row pgx.Row) (webhook.Webhook, error) {
+	var w webhook.Webhook
+	err := row.Scan(&w.ID, &w.URL, &w.Secret, &w.Events, &w.ProductIDs, &w.Labels, &w.CreatedAt, &w.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return webhook.Webhook{}, db.ErrNotFound
+	}
+	return w, err
+}
+
+const deliveryColumns = `id, webhook_id, event_id, event, payload, status, attempts,
+	next_attempt_at, response_status, error, created_at, updated_at`
+
+func scanDelivery(row pgx.Row) (webhook.Delivery, error) {
+	var d webhook.Delivery
+	err := row.Scan(
+		&d.ID,
+		&d.WebhookID,
+		&d.EventID,
+		&d.Event,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.ResponseStatus,
+		&d.Error,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return webhook.Delivery{}, db.ErrNotFound
+	}
+	return d, err
+}
+
+func (pg *Postgres) AddWebhook(ctx context.Context, w webhook.Webhook) (webhook.Webhook, error) {
+	return scanWebhook(pg.pool.QueryRow(
+		ctx,
+		`INSERT INTO webhooks (id, url, secret, events, product_ids, labels)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING `+webhookColumns,
+		uuid.NewString(),
+		w.URL,
+		w.Secret,
+		textArray(w.Events),
+		textArray(w.ProductIDs),
+		textArray(w.Labels),
+	))
+}
+
+func (pg *Postgres) GetWebhook(ctx context.Context, id string) (webhook.Webhook, error) {
+	if uuid.Validate(id) != nil {
+		return webhook.Webhook{}, db.ErrNotFound
+	}
+	return scanWebhook(pg.pool.QueryRow(ctx, "SELECT "+webhookColumns+" FROM webhooks WHERE id = $1", id))
+}
+
+func (pg *Postgres) ListWebhooks(ctx context.Context) ([]webhook.Webhook, error) {
+	rows, err := pg.pool.Query(ctx, "SELECT "+webhookColumns+" FROM webhooks ORDER BY created_at, id")
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (webhook.Webhook, error) {
+		return scanWebhook(row)
+	})
+}
+
+func (pg *Postgres) UpdateWebhook(ctx context.Context, w webhook.Webhook) (webhook.Webhook, error) {
+	if uuid.Validate(w.ID) != nil {
+		return webhook.Webhook{}, db.ErrNotFound
+	}
+	return scanWebhook(pg.pool.QueryRow(
+		ctx,
+		`UPDATE webhooks
+		SET url = $2, secret = $3, events = $4, product_ids = $5, labels = $6, updated_at = now()
+		WHERE id = $1 RETURNING `+webhookColumns,
+		w.ID,
+		w.URL,
+		w.Secret,
+		textArray(w.Events),
+		textArray(w.ProductIDs),
+		textArray(w.Labels),
+	))
+}
+
+func (pg *Postgres) DeleteWebhook(ctx context.Context, id string) error {
+	if uuid.Validate(id) != nil {
+		return db.ErrNotFound
+	}
+	tag, err := pg.pool.Exec(ctx, "DELETE FROM webhooks WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return db.ErrNotFound
+	}
+	return nil
+}
+
+func (pg *Postgres) Publish(ctx context.Context, e webhook.Event) error {
+	return pgx.BeginFunc(ctx, pg.pool, func(tx pgx.Tx) error {
+		return publish(ctx, tx, e)
+	})
+}
+
+// publish сохраняет доставки события подходящим подпискам в рамках
+// транзакции. Условия совпадают с webhook.Webhook.Match.
+func publish(ctx context.Context, tx pgx.Tx, e webhook.Event) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO webhook_deliveries (id, webhook_id, event_id, event, payload)
+		SELECT gen_random_uuid(), id, $1::uuid, $2::text, $3::jsonb FROM webhooks
+		WHERE (cardinality(events) = 0 OR $2 = ANY(events))
+			AND (cardinality(product_ids) = 0 OR $4 = ANY(product_ids))
+			AND ($5 = '' OR cardinality(labels) = 0 OR $5 = ANY(labels))`,
+		e.ID,
+		e.Type,
+		e.Payload,
+		e.ProductID,
+		e.Label,
+	)
+	return err
+}
+
+// ClaimDeliveries реализует webhook.Store. Доставки арендуются сдвигом
+// next_attempt_at и получают общий токен захвата, как задания классификации.
+func (pg *Postgres) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error) {
+	token := uuid.NewString()
+	rows, err := pg.pool.Query(ctx, `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1,
+			next_attempt_at = now() + $2::interval,
+			lease_token = $4,
+			updated_at = now()
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $3 AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+deliveryColumns,
+		limit,
+		lease,
+		webhook.StatusPending,
+		token,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (webhook.Delivery, error) {
+		d, err := scanDelivery(row)
+		d.Token = token
+		return d, err
+	})
+}
+
+func (pg *Postgres) SaveDelivery(ctx context.Context, d webhook.Delivery) error {
+	if uuid.Validate(d.Token) != nil {
+		return db.ErrNotFound
+	}
+	tag, err := pg.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $3, next_attempt_at = $4, response_status = $5, error = $6, updated_at = now()
+		WHERE id = $1 AND webhook_id = $2 AND lease_token = $7`,
+		d.ID,
+		d.WebhookID,
+		d.Status,
+		d.NextAttemptAt,
+		d.ResponseStatus,
+		d.Error,
+		d.Token,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return db.ErrNotFound
+	}
+	return nil
+}
+
+func (pg *Postgres) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]webhook.Delivery, error) {
+	if uuid.Validate(webhookID) != nil {
+		return nil, db.ErrNotFound
+	}
+	rows, err := pg.pool.Query(
+		ctx,
+		`SELECT `+deliveryColumns+` FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`,
+		webhookID,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (webhook.Delivery, error) {
+		return scanDelivery(row)
+	})
+}
+
+func (pg *Postgres) ReplayDelivery(ctx context.Context, webhookID, deliveryID string) (webhook.Delivery, error) {
+	if uuid.Validate(webhookID) != nil || uuid.Validate(deliveryID) != nil {
+		return webhook.Delivery{}, db.ErrNotFound
+	}
+	return scanDelivery(pg.pool.QueryRow(
+		ctx,
+		`INSERT INTO webhook_deliveries (id, webhook_id, event_id, event, payload)
+		SELECT $3::uuid, webhook_id, event_id, event, payload FROM webhook_deliveries
+		WHERE id = $1 AND webhook_id = $2
+		RETURNING `+deliveryColumns,
+		deliveryID,
+		webhookID,
+		uuid.NewString(),
+	))
+}
+
+// textArray возвращает пустой массив вместо nil, который pgx
+// передает как NULL.
+func textArray(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,73 @@
+package helpful
+
+import (
+	"testing"
+	"time"
+
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWilson(t *testing.T) {
+	assert.Zero(t, Wilson(0, 0))
+	assert.InDelta(t, 0.2065, Wilson(1, 0), 1e-4)
+
+	// Много голосов "за" надежнее нескольких
+	assert.Greater(t, Wilson(40, 10), Wilson(2, 0))
+	assert.Greater(t, Wilson(10, 0), Wilson(10, 1))
+	assert.Less(t, Wilson(0, 5), Wilson(1, 5))
+}
+
+func TestSort(t *testing.T) {
+	now := time.Now()
+	review := func(id string, up, down int, age time.Duration) models.Review {
+		return models.Review{ID: id, Helpful: models.Helpfulness{Up: up, Down: down}, CreatedAt: now.Add(-age)}
+	}
+	reviews := []models.Review{
+		review("old-popular", 40, 10, 2*365*24*time.Hour),
+		review("none", 0, 0, time.Hour),
+		review("popular", 40, 10, 24*time.Hour),
+		review("few", 2, 0, 24*time.Hour),
+		review("none-new", 0, 0, time.Minute),
+	}
+	ids := func(rs []models.Review) []string {
+		res := make([]string, len(rs))
+		for i, r := range rs {
+			res[i] = r.ID
+		}
+		return res
+	}
+
+	Sort(reviews, SortHelpful, now)
+	assert.Equal(t, []string{"popular", "few", "old-popular", "none-new", "none"}, ids(reviews))
+
+	Sort(reviews, SortRecent, now)
+	assert.Equal(t, []string{"none-new", "none", "popular", "few", "old-popular"}, ids(reviews))
+
+	assert.True(t, ValidSort(SortCreated))
+	assert.False(t, ValidSort("rating"))
+}
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(2, time.Hour)
+
+	for range 2 {
+		ok, _ := l.Allow("a")
+		assert.True(t, ok)
+	}
+	ok, retry := l.Allow("a")
+	assert.False(t, ok)
+	assert.Greater(t, retry, 59*time.Minute)
+
+	// Лимит считается для каждого клиента отдельно
+	ok, _ = l.Allow("b")
+	assert.True(t, ok)
+
+	// Пустой лимит не ограничивает голоса
+	unlimited := NewLimiter(0, time.Hour)
+	for range 100 {
+		ok, _ := unlimited.Allow("a")
+		assert.True(t, ok)
+	}
+}
@@ -0,0 +1,68 @@
+package helpful
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter ограничивает число голосов клиента за окно времени, чтобы один
+// клиент не мог массово голосовать от имени разных пользователей.
+type Limiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	// Окна клиентов по ключу клиента.
+	clients map[string]*window
+	// Время последней очистки истекших окон.
+	swept time.Time
+}
+
+// window - окно клиента: начало и число голосов в нем.
+type window struct {
+	start time.Time
+	count int
+}
+
+// NewLimiter создает ограничитель: не более limit голосов за period.
+// Пустой limit не ограничивает голоса.
+func NewLimiter(limit int, period time.Duration) *Limiter {
+	return &Limiter{limit: limit, window: period, clients: make(map[string]*window)}
+}
+
+// Allow учитывает голос клиента. Если лимит исчерпан, возвращает false
+// и время до начала следующего окна.
+func (l *Limiter) Allow(client string) (bool, time.Duration) {
+	if l.limit <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	w := l.clients[client]
+	if w == nil || now.Sub(w.start) >= l.window {
+		w = &window{start: now}
+		l.clients[client] = w
+	}
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// sweep удаляет истекшие окна не чаще раза за окно.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < l.window {
+		return
+	}
+	for client, w := range l.clients {
+		if now.Sub(w.start) >= l.window {
+			delete(l.clients, client)
+		}
+	}
+	l.swept = now
+}
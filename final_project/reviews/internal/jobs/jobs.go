@@ -0,0 +1,49 @@
+// Package jobs реализует фоновую классификацию настроения отзывов.
+//
+// Новые и измененные отзывы сохраняются со статусом pending вместе с
+// заданием в таблице заданий. Пул воркеров выбирает задания из хранилища,
+// классифицирует отзывы с ограниченной параллельностью, повторяет неудачные
+// попытки с экспоненциальной задержкой и после исчерпания попыток переводит
+// задание в dead-letter. Задания хранятся в БД и переживают перезапуск.
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go-masters/final_project/reviews/internal/models"
+)
+
+// Состояния задания.
+const (
+	// Задание ожидает выполнения или повторной попытки.
+	StateQueued = "queued"
+	// Попытки исчерпаны, задание требует ручного разбора.
+	StateDead = "dead"
+)
+
+// Job - задание на классификацию отзыва.
+type Job struct {
+	ReviewID string
+	Text     string
+	// Код языка отзыва для выбора классификатора.
+	Language string
+	// Номер текущей попытки, начиная с 1.
+	Attempt int
+	// Token - идентификатор захвата задания. Если отзыв изменился и задание
+	// было поставлено заново, результат устаревшего захвата отбрасывается.
+	Token string
+}
+
+// Store - хранилище заданий.
+type Store interface {
+	// ClaimJobs захватывает до limit готовых к выполнению заданий на время lease.
+	// Если воркер не успеет завершить задание, оно будет выбрано повторно.
+	ClaimJobs(ctx context.Context, limit int, lease time.Duration) ([]Job, error)
+	// CompleteJob сохраняет настроение отзыва и удаляет задание.
+	CompleteJob(ctx context.Context, job Job, s models.Sentiment) error
+	// RetryJob откладывает задание до момента at.
+	RetryJob(ctx context.Context, job Job, at time.Time, reason string) error
+	// FailJob переводит задание в dead-letter, а отзыв - в статус failed.
+	FailJob(ctx context.Context, job Job, reason string) error
+}
@@ -0,0 +1,206 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-masters/final_project/reviews/internal/metrics"
+	"go-masters/final_project/reviews/internal/sentiment"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Параметры пула по умолчанию.
+const (
+	DefaultWorkers      = 4
+	DefaultBatchSize    = 32
+	DefaultPollInterval = time.Second
+	DefaultLease        = 2 * time.Minute
+	DefaultTimeout      = 30 * time.Second
+	DefaultMaxAttempts  = 5
+	DefaultMinBackoff   = 5 * time.Second
+	DefaultMaxBackoff   = 10 * time.Minute
+)
+
+// Pool - пул воркеров, классифицирующих отзывы из очереди заданий.
+type Pool struct {
+	store      Store
+	classifier sentiment.Classifier
+
+	// Число воркеров; значения меньше 1 означают одного воркера.
+	Workers      int
+	BatchSize    int
+	PollInterval time.Duration
+	// Наименьшее время аренды заданий. Пакет арендуется целиком, поэтому
+	// аренда увеличивается до времени обработки всего пакета (см. lease).
+	Lease time.Duration
+	// Ограничение времени одной классификации.
+	Timeout     time.Duration
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+func NewPool(store Store, classifier sentiment.Classifier) *Pool {
+	return &Pool{
+		store:        store,
+		classifier:   classifier,
+		Workers:      DefaultWorkers,
+		BatchSize:    DefaultBatchSize,
+		PollInterval: DefaultPollInterval,
+		Lease:        DefaultLease,
+		Timeout:      DefaultTimeout,
+		MaxAttempts:  DefaultMaxAttempts,
+		MinBackoff:   DefaultMinBackoff,
+		MaxBackoff:   DefaultMaxBackoff,
+	}
+}
+
+// Run обрабатывает очередь до отмены контекста.
+func (p *Pool) Run(ctx context.Context) {
+	log.Info().Int("workers", p.Workers).Msg("Запуск очереди классификации")
+
+	ticker := time.NewTicker(p.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := p.RunOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Error().Err(err).Msg("Ошибка обработки очереди классификации")
+			}
+			// Если пакет заполнен целиком, сразу выбираем следующий.
+			if n < p.BatchSize || err != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Остановка очереди классификации")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce выбирает пакет заданий, распределяет его между воркерами
+// и ждет завершения. Возвращает количество выбранных заданий.
+func (p *Pool) RunOnce(ctx context.Context) (int, error) {
+	jobs, err := p.store.ClaimJobs(ctx, p.BatchSize, p.lease())
+	if err != nil {
+		return 0, fmt.Errorf("ошибка выборки заданий: %w", err)
+	}
+	if len(jobs) == 0 {
+		return 0, nil
+	}
+
+	tasks := make(chan Job)
+	results := make(chan error)
+
+	var wg sync.WaitGroup
+	for range min(max(p.Workers, 1), len(jobs)) {
+		wg.Add(1)
+		go p.worker(ctx, tasks, results, &wg)
+	}
+
+	var errs error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for err := range results {
+			errs = errors.Join(errs, err)
+		}
+	}()
+
+	for _, job := range jobs {
+		tasks <- job
+	}
+	close(tasks)
+
+	wg.Wait()
+	close(results)
+	<-done
+
+	return len(jobs), errs
+}
+
+// lease возвращает время аренды пакета. Задания пакета ждут свободного
+// воркера, и последние из них не должны быть захвачены другим экземпляром
+// сервиса, пока стоят в очереди: аренда покрывает ceil(BatchSize/Workers)
+// классификаций с запасом еще на одну.
+func (p *Pool) lease() time.Duration {
+	workers := max(p.Workers, 1)
+	rounds := (max(p.BatchSize, 1) + workers - 1) / workers
+	return max(p.Lease, time.Duration(rounds+1)*p.Timeout)
+}
+
+// worker обрабатывает задания из канала tasks.
+func (p *Pool) worker(ctx context.Context, tasks <-chan Job, results chan<- error, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for job := range tasks {
+		// Если пул остановлен, оставшиеся задания будут выбраны
+		// повторно после истечения аренды.
+		if ctx.Err() != nil {
+			continue
+		}
+		if err := p.process(ctx, job); err != nil {
+			results <- fmt.Errorf("отзыв %s: %w", job.ReviewID, err)
+		}
+	}
+}
+
+// process классифицирует отзыв и фиксирует результат в хранилище.
+func (p *Pool) process(ctx context.Context, job Job) error {
+	start := time.Now()
+
+	cctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	res, err := p.classifier.Classify(sentiment.WithLanguage(cctx, job.Language), job.Text)
+	cancel()
+	if err == nil {
+		err = res.Validate()
+	}
+
+	if err == nil {
+		metrics.ObserveClassification(metrics.ClassificationDone, time.Since(start))
+		return p.store.CompleteJob(ctx, job, res.Sentiment())
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	if job.Attempt >= p.MaxAttempts {
+		metrics.ObserveClassification(metrics.ClassificationDead, time.Since(start))
+		log.Error().
+			Err(err).
+			Str("review_id", job.ReviewID).
+			Int("attempt", job.Attempt).
+			Msg("Попытки классификации исчерпаны, задание переведено в dead-letter")
+		return p.store.FailJob(ctx, job, err.Error())
+	}
+
+	metrics.ObserveClassification(metrics.ClassificationRetry, time.Since(start))
+	next := time.Now().Add(p.backoff(job.Attempt))
+	log.Warn().
+		Err(err).
+		Str("review_id", job.ReviewID).
+		Int("attempt", job.Attempt).
+		Time("next_attempt", next).
+		Msg("Не удалось классифицировать отзыв, повтор запланирован")
+
+	return p.store.RetryJob(ctx, job, next, err.Error())
+}
+
+// backoff - экспоненциальная задержка перед повторной попыткой.
+func (p *Pool) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxBackoff)
+}
@@ -0,0 +1,237 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/sentiment"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// classifierFunc позволяет использовать функцию как классификатор.
+type classifierFunc func(ctx context.Context, text string) (sentiment.Result, error)
+
+func (f classifierFunc) Classify(ctx context.Context, text string) (sentiment.Result, error) {
+	return f(ctx, text)
+}
+
+var positive = classifierFunc(func(context.Context, string) (sentiment.Result, error) {
+	return sentiment.Result{Label: sentiment.Positive, Confidence: 0.8}, nil
+})
+
+func addReviews(t *testing.T, m *memdb.MemDB, n int) (string, []models.Review) {
+	t.Helper()
+
+	p, err := m.AddProduct(context.Background(), models.Product{Name: "Чайник"})
+	require.NoError(t, err)
+
+	var reviews []models.Review
+	for range n {
+		r, err := m.AddReview(context.Background(), models.Review{
+			ProductID: p.ID, Author: "Иван", Text: "Отличный чайник", Rating: 5,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, models.SentimentPending, r.Sentiment.Status)
+		reviews = append(reviews, r)
+	}
+	return p.ID, reviews
+}
+
+func TestPool_Complete(t *testing.T) {
+	m := memdb.New()
+	productID, reviews := addReviews(t, m, 5)
+
+	pool := jobs.NewPool(m, positive)
+	n, err := pool.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	for _, r := range reviews {
+		got, err := m.GetReview(context.Background(), productID, r.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.Sentiment{Status: models.SentimentDone, Label: "positive", Confidence: 0.8}, got.Sentiment)
+	}
+
+	// Выполненные задания удаляются из очереди.
+	n, err = pool.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+// leaseStore запоминает время аренды, с которым выбираются задания.
+type leaseStore struct {
+	jobs.Store
+	lease time.Duration
+}
+
+func (s *leaseStore) ClaimJobs(ctx context.Context, limit int, lease time.Duration) ([]jobs.Job, error) {
+	s.lease = lease
+	return s.Store.ClaimJobs(ctx, limit, lease)
+}
+
+func TestPool_LeaseCoversBatch(t *testing.T) {
+	store := &leaseStore{Store: memdb.New()}
+	pool := jobs.NewPool(store, positive)
+	pool.Workers = 4
+	pool.BatchSize = 32
+	pool.Timeout = time.Minute
+	pool.Lease = time.Minute
+
+	// 32 задания на 4 воркерах - 8 классификаций подряд и одна в запас.
+	_, err := pool.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 9*time.Minute, store.lease)
+
+	// Большая аренда из настроек не уменьшается.
+	pool.Lease = time.Hour
+	_, err = pool.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, store.lease)
+}
+
+func TestPool_NoWorkers(t *testing.T) {
+	m := memdb.New()
+	addReviews(t, m, 3)
+
+	// Некорректное число воркеров не должно блокировать очередь.
+	pool := jobs.NewPool(m, positive)
+	pool.Workers = 0
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		n, err := pool.RunOnce(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunOnce заблокирован")
+	}
+}
+
+func TestPool_RetryAndDeadLetter(t *testing.T) {
+	m := memdb.New()
+	productID, reviews := addReviews(t, m, 1)
+
+	var calls atomic.Int32
+	failing := classifierFunc(func(context.Context, string) (sentiment.Result, error) {
+		calls.Add(1)
+		return sentiment.Result{}, errors.New("connection refused")
+	})
+
+	pool := jobs.NewPool(m, failing)
+	pool.MaxAttempts = 3
+	pool.MinBackoff = 0
+
+	for range pool.MaxAttempts {
+		n, err := pool.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+	}
+	assert.EqualValues(t, 3, calls.Load())
+
+	got, err := m.GetReview(context.Background(), productID, reviews[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SentimentFailed, got.Sentiment.Status)
+
+	// Задание в dead-letter больше не выбирается.
+	n, err := pool.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	// Изменение текста ставит отзыв в очередь заново.
+	seen := reviews[0].Text
+	reviews[0].Text = "Пользоваться можно"
+	_, err = m.UpdateReview(context.Background(), reviews[0], seen)
+	require.NoError(t, err)
+	n, err = jobs.NewPool(m, positive).RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestPool_Backoff(t *testing.T) {
+	m := memdb.New()
+	addReviews(t, m, 1)
+
+	failing := classifierFunc(func(context.Context, string) (sentiment.Result, error) {
+		return sentiment.Result{}, errors.New("timeout")
+	})
+	pool := jobs.NewPool(m, failing)
+	pool.MinBackoff = time.Hour
+
+	n, err := pool.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// Повтор запланирован через час.
+	n, err = pool.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestPool_StaleResult(t *testing.T) {
+	m := memdb.New()
+	productID, reviews := addReviews(t, m, 1)
+	r := reviews[0]
+
+	// Отзыв меняется, пока идет классификация старого текста.
+	editing := classifierFunc(func(ctx context.Context, text string) (sentiment.Result, error) {
+		seen := r.Text
+		r.Text = "Ужасный чайник"
+		_, err := m.UpdateReview(ctx, r, seen)
+		require.NoError(t, err)
+		return sentiment.Result{Label: sentiment.Positive, Confidence: 0.9}, nil
+	})
+	_, err := jobs.NewPool(m, editing).RunOnce(context.Background())
+	require.NoError(t, err)
+
+	got, err := m.GetReview(context.Background(), productID, r.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SentimentPending, got.Sentiment.Status)
+
+	n, err := jobs.NewPool(m, positive).RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestPool_BoundedConcurrency(t *testing.T) {
+	m := memdb.New()
+	addReviews(t, m, 20)
+
+	var (
+		mu             sync.Mutex
+		active, maxAct int
+	)
+	slow := classifierFunc(func(context.Context, string) (sentiment.Result, error) {
+		mu.Lock()
+		active++
+		maxAct = max(maxAct, active)
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return sentiment.Result{Label: sentiment.Neutral, Confidence: 0.5}, nil
+	})
+
+	pool := jobs.NewPool(m, slow)
+	pool.Workers = 3
+	n, err := pool.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 20, n)
+	assert.LessOrEqual(t, maxAct, 3)
+	assert.Greater(t, maxAct, 1)
+}
@@ -0,0 +1,157 @@
+// Package language определяет язык текста отзыва.
+//
+// Детектор не требует внешних сервисов: язык с собственной письменностью
+// определяется по преобладающему алфавиту, кириллические тексты различаются
+// по характерным буквам, а латинские - по частым служебным словам
+// и диакритическим знакам. Результат - код языка ISO 639-1.
+package language
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Коды языков.
+const (
+	Russian   = "ru"
+	Ukrainian = "uk"
+	English   = "en"
+	German    = "de"
+	French    = "fr"
+	Spanish   = "es"
+	// Unknown - язык не определен: в тексте нет букв.
+	Unknown = "und"
+)
+
+// scripts - языки, определяемые по письменности.
+var scripts = []struct {
+	table *unicode.RangeTable
+	code  string
+}{
+	{unicode.Hangul, "ko"},
+	// Японский текст содержит и иероглифы, поэтому кана проверяется раньше.
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Han, "zh"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Armenian, "hy"},
+	{unicode.Georgian, "ka"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+}
+
+// ukrainian - буквы украинского алфавита, которых нет в русском,
+// russian - буквы русского алфавита, которых нет в украинском.
+const (
+	ukrainian = "іїєґ"
+	russian   = "ыэъё"
+)
+
+// stopwords - частые служебные слова латинских языков.
+var stopwords = map[string][]string{
+	English: {"the", "and", "is", "it", "this", "was", "for", "with", "not", "very", "but", "are", "of", "to", "my", "i"},
+	German:  {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "sehr", "mit", "ich", "es", "auch", "aber", "gut"},
+	French:  {"le", "la", "les", "et", "est", "une", "un", "pas", "très", "avec", "je", "ce", "c'est", "pour", "mais", "du"},
+	Spanish: {"el", "los", "las", "es", "muy", "una", "y", "con", "no", "pero", "por", "para", "lo", "que", "del", "se"},
+}
+
+// diacritics - буквы, характерные для латинского языка.
+var diacritics = map[rune]string{
+	'ß': German, 'ä': German, 'ö': German, 'ü': German,
+	'ñ': Spanish, '¿': Spanish, '¡': Spanish, 'á': Spanish, 'í': Spanish, 'ó': Spanish, 'ú': Spanish,
+	'ç': French, 'è': French, 'ê': French, 'à': French, 'ù': French, 'œ': French, 'â': French,
+}
+
+// latin - порядок латинских языков при равных оценках; английский
+// первым, так как без служебных слов короткий латинский отзыв вероятнее
+// всего английский.
+var latin = []string{English, German, French, Spanish}
+
+// Detect возвращает код языка текста или Unknown.
+func Detect(text string) string {
+	var (
+		cyrillic, lat int
+		other         = make(map[string]int)
+		uk, ru        bool
+	)
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case !unicode.IsLetter(r):
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+			uk = uk || strings.ContainsRune(ukrainian, r)
+			ru = ru || strings.ContainsRune(russian, r)
+		case unicode.Is(unicode.Latin, r):
+			lat++
+		default:
+			for _, s := range scripts {
+				if unicode.Is(s.table, r) {
+					other[s.code]++
+					break
+				}
+			}
+		}
+	}
+
+	code, n := Unknown, 0
+	for _, s := range scripts {
+		if c := other[s.code]; c > n {
+			code, n = s.code, c
+		}
+	}
+	// Кана вместе с иероглифами - японский текст.
+	if code == "zh" && other["ja"] > 0 {
+		code = "ja"
+	}
+	switch {
+	case cyrillic == 0 && lat == 0:
+		return code
+	case n > cyrillic && n > lat:
+		return code
+	case cyrillic >= lat:
+		if uk && !ru {
+			return Ukrainian
+		}
+		return Russian
+	}
+	return detectLatin(text)
+}
+
+// detectLatin выбирает латинский язык по служебным словам и диакритике.
+func detectLatin(text string) string {
+	score := make(map[string]int, len(latin))
+	for _, r := range strings.ToLower(text) {
+		if code, ok := diacritics[r]; ok {
+			score[code]++
+		}
+	}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		for code, words := range stopwords {
+			if slices.Contains(words, w) {
+				score[code] += 2
+			}
+		}
+	}
+
+	best := English
+	for _, code := range latin {
+		if score[code] > score[best] {
+			best = code
+		}
+	}
+	return best
+}
+
+// Valid сообщает, является ли code допустимым кодом языка: Unknown
+// или двухбуквенным кодом ISO 639-1 в нижнем регистре.
+func Valid(code string) bool {
+	if code == Unknown {
+		return true
+	}
+	return len(code) == 2 && code[0] >= 'a' && code[0] <= 'z' && code[1] >= 'a' && code[1] <= 'z'
+}
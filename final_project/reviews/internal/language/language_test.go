@@ -0,0 +1,43 @@
+package language
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Отличный чайник, всем советую!", Russian},
+		{"Чайник хороший, но шумный. Great kettle", Russian},
+		{"Чудовий чайник, ціна відмінна", Ukrainian},
+		{"The kettle is great, works fine", English},
+		{"Great kettle", English},
+		{"Der Wasserkocher ist sehr gut", German},
+		{"Größe passt", German},
+		{"C'est une très bonne bouilloire", French},
+		{"El hervidor es muy bueno, pero caro", Spanish},
+		{"很好的水壶", "zh"},
+		{"とても良いケトルです", "ja"},
+		{"좋은 주전자", "ko"},
+		{"12345 !!! 👍", Unknown},
+		{"", Unknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, Detect(tt.text))
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	for _, code := range []string{"ru", "en", "zh", Unknown} {
+		assert.True(t, Valid(code), code)
+	}
+	for _, code := range []string{"", "RU", "rus", "r1", "en-US"} {
+		assert.False(t, Valid(code), code)
+	}
+}
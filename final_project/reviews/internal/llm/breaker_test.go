@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	// Одна ошибка не размыкает breaker, успех сбрасывает счетчик.
+	assert.NoError(t, b.Allow())
+	b.Done(false)
+	assert.NoError(t, b.Allow())
+	b.Done(true)
+	assert.NoError(t, b.Allow())
+	b.Done(false)
+	assert.Equal(t, stateClosed, b.State())
+
+	assert.NoError(t, b.Allow())
+	b.Done(false)
+	assert.Equal(t, stateOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// После паузы пропускается один пробный вызов.
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Allow())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+	assert.Equal(t, stateHalfOpen, b.State())
+
+	// Неудачный пробный вызов снова размыкает breaker.
+	b.Done(false)
+	assert.Equal(t, stateOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// Отмененный пробный вызов не меняет состояние.
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Allow())
+	b.Cancel()
+	assert.Equal(t, stateHalfOpen, b.State())
+
+	// Успешный пробный вызов замыкает breaker.
+	assert.NoError(t, b.Allow())
+	b.Done(true)
+	assert.Equal(t, stateClosed, b.State())
+	assert.NoError(t, b.Allow())
+}
@@ -0,0 +1,228 @@
+// Package llm - клиент Ollama для сервиса отзывов.
+//
+// Клиент оборачивает api.Client и добавляет к каждому вызову:
+//   - ограничение времени выполнения (Config.Timeout);
+//   - семафор, ограничивающий число одновременных генераций;
+//   - circuit breaker, отключающий вызовы после серии ошибок;
+//   - метрики Prometheus (число вызовов, длительность, токены) и спаны OTel.
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go-masters/final_project/reviews/internal/metrics"
+
+	"github.com/ollama/ollama/api"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Параметры клиента по умолчанию.
+const (
+	DefaultTimeout          = time.Minute
+	DefaultMaxConcurrent    = 4
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// Операции клиента, используемые в метриках и спанах.
+const (
+	OpGenerate = "generate"
+	OpChat     = "chat"
+	OpEmbed    = "embed"
+)
+
+// Config - настройки клиента.
+type Config struct {
+	// Адрес сервера Ollama, например http://localhost:11434.
+	Endpoint string
+	// Ограничение времени одного вызова, включая ожидание семафора.
+	Timeout time.Duration
+	// Максимальное число одновременных вызовов.
+	MaxConcurrent int
+	// Число ошибок подряд, после которого breaker размыкается.
+	BreakerThreshold int
+	// Время, на которое breaker отключает вызовы.
+	BreakerCooldown time.Duration
+}
+
+// Client - клиент Ollama с ограничениями и наблюдаемостью.
+type Client struct {
+	api     *api.Client
+	timeout time.Duration
+	sem     chan struct{}
+	breaker *Breaker
+	tracer  trace.Tracer
+}
+
+func New(cfg Config) (*Client, error) {
+	addr, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный адрес Ollama: %w", err)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = DefaultMaxConcurrent
+	}
+	if cfg.BreakerThreshold <= 0 {
+		cfg.BreakerThreshold = DefaultBreakerThreshold
+	}
+	if cfg.BreakerCooldown <= 0 {
+		cfg.BreakerCooldown = DefaultBreakerCooldown
+	}
+
+	return &Client{
+		api:     api.NewClient(addr, &http.Client{}),
+		timeout: cfg.Timeout,
+		sem:     make(chan struct{}, cfg.MaxConcurrent),
+		breaker: NewBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		tracer:  otel.Tracer("reviews/llm"),
+	}, nil
+}
+
+// Breaker возвращает circuit breaker клиента.
+func (c *Client) Breaker() *Breaker {
+	return c.breaker
+}
+
+// Generate выполняет генерацию. fn вызывается для каждого фрагмента ответа.
+func (c *Client) Generate(ctx context.Context, req *api.GenerateRequest, fn api.GenerateResponseFunc) error {
+	return c.call(ctx, OpGenerate, req.Model, func(ctx context.Context, span trace.Span) error {
+		return c.api.Generate(ctx, req, func(resp api.GenerateResponse) error {
+			if resp.Done {
+				observeUsage(span, OpGenerate, req.Model, resp.Metrics)
+			}
+			if err := fn(resp); err != nil {
+				return callbackError{err}
+			}
+			return nil
+		})
+	})
+}
+
+// Chat выполняет генерацию ответа в диалоге. fn вызывается для каждого фрагмента ответа.
+func (c *Client) Chat(ctx context.Context, req *api.ChatRequest, fn api.ChatResponseFunc) error {
+	return c.call(ctx, OpChat, req.Model, func(ctx context.Context, span trace.Span) error {
+		return c.api.Chat(ctx, req, func(resp api.ChatResponse) error {
+			if resp.Done {
+				observeUsage(span, OpChat, req.Model, resp.Metrics)
+			}
+			if err := fn(resp); err != nil {
+				return callbackError{err}
+			}
+			return nil
+		})
+	})
+}
+
+// Embed вычисляет векторы текстов.
+func (c *Client) Embed(ctx context.Context, req *api.EmbedRequest) (*api.EmbedResponse, error) {
+	var resp *api.EmbedResponse
+	err := c.call(ctx, OpEmbed, req.Model, func(ctx context.Context, span trace.Span) error {
+		var err error
+		resp, err = c.api.Embed(ctx, req)
+		if err != nil {
+			return err
+		}
+		observeUsage(span, OpEmbed, req.Model, api.Metrics{
+			PromptEvalCount: resp.PromptEvalCount,
+			TotalDuration:   resp.TotalDuration,
+		})
+		return nil
+	})
+	return resp, err
+}
+
+// call выполняет вызов Ollama с учетом ограничений клиента.
+func (c *Client) call(ctx context.Context, op, model string, fn func(context.Context, trace.Span) error) (err error) {
+	start := time.Now()
+
+	ctx, span := c.tracer.Start(ctx, "llm."+op, trace.WithAttributes(
+		attribute.String("llm.operation", op),
+		attribute.String("llm.model", model),
+	))
+	defer func() {
+		result := metrics.LLMSuccess
+		switch {
+		case errors.Is(err, ErrCircuitOpen):
+			result = metrics.LLMRejected
+		case err != nil:
+			result = metrics.LLMError
+		}
+		metrics.ObserveLLM(op, model, result, time.Since(start))
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	if err := c.breaker.Allow(); err != nil {
+		return err
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	// Семафор ограничивает число одновременных вызовов.
+	select {
+	case c.sem <- struct{}{}:
+	case <-ctx.Done():
+		// Ожидание в очереди - не отказ Ollama, breaker не учитывает его.
+		c.breaker.Cancel()
+		return fmt.Errorf("ожидание свободного слота LLM: %w", ctx.Err())
+	}
+	defer func() { <-c.sem }()
+	span.AddEvent("slot acquired")
+
+	err = fn(ctx, span)
+	var cbErr callbackError
+	if errors.As(err, &cbErr) {
+		// Ошибка обработки ответа (например, клиент SSE отключился)
+		// не говорит о неисправности Ollama.
+		c.breaker.Cancel()
+		return cbErr.err
+	}
+	if err != nil && errors.Is(parent.Err(), context.Canceled) {
+		// Отмена вызова клиентом не говорит о неисправности Ollama,
+		// в отличие от превышения времени ожидания.
+		c.breaker.Cancel()
+		return err
+	}
+	c.breaker.Done(err == nil)
+	return err
+}
+
+// callbackError - ошибка функции, обрабатывающей фрагменты ответа.
+type callbackError struct {
+	err error
+}
+
+func (e callbackError) Error() string {
+	return e.err.Error()
+}
+
+func (e callbackError) Unwrap() error {
+	return e.err
+}
+
+// observeUsage учитывает статистику завершенного вызова.
+func observeUsage(span trace.Span, op, model string, m api.Metrics) {
+	metrics.AddLLMTokens(op, model, m.PromptEvalCount, m.EvalCount)
+	span.SetAttributes(
+		attribute.Int("llm.prompt_tokens", m.PromptEvalCount),
+		attribute.Int("llm.completion_tokens", m.EvalCount),
+		attribute.Int64("llm.total_duration_ms", m.TotalDuration.Milliseconds()),
+	)
+}
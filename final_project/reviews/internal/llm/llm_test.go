@@ -0,0 +1,148 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-masters/final_project/reviews/internal/llm/ollamatest"
+
+	"github.com/ollama/ollama/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generate(ctx context.Context, c *Client) (string, error) {
+	var out string
+	err := c.Generate(ctx, &api.GenerateRequest{Model: "test", Prompt: "привет"}, func(resp api.GenerateResponse) error {
+		out += resp.Response
+		return nil
+	})
+	return out, err
+}
+
+func TestClient_Generate(t *testing.T) {
+	srv := ollamatest.NewServer(func(api.GenerateRequest) (string, error) {
+		return "ответ", nil
+	})
+	defer srv.Close()
+
+	c, err := New(Config{Endpoint: srv.URL})
+	require.NoError(t, err)
+
+	out, err := generate(context.Background(), c)
+	require.NoError(t, err)
+	assert.Equal(t, "ответ", out)
+}
+
+func TestClient_Concurrency(t *testing.T) {
+	var active, maxActive atomic.Int32
+	srv := ollamatest.NewServer(func(api.GenerateRequest) (string, error) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			m := maxActive.Load()
+			if n <= m || maxActive.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return "ok", nil
+	})
+	defer srv.Close()
+
+	c, err := New(Config{Endpoint: srv.URL, MaxConcurrent: 2})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := generate(context.Background(), c)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 2, maxActive.Load())
+}
+
+func TestClient_Timeout(t *testing.T) {
+	srv := ollamatest.NewServer(func(api.GenerateRequest) (string, error) {
+		time.Sleep(200 * time.Millisecond)
+		return "ok", nil
+	})
+	defer srv.Close()
+
+	c, err := New(Config{Endpoint: srv.URL, Timeout: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	_, err = generate(context.Background(), c)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	srv := ollamatest.NewServer(func(api.GenerateRequest) (string, error) {
+		calls.Add(1)
+		return "", errors.New("model not found")
+	})
+	defer srv.Close()
+
+	c, err := New(Config{Endpoint: srv.URL, BreakerThreshold: 3, BreakerCooldown: time.Hour})
+	require.NoError(t, err)
+
+	for range 3 {
+		_, err := generate(context.Background(), c)
+		assert.ErrorContains(t, err, "model not found")
+	}
+
+	// Breaker разомкнут: вызов отклоняется без обращения к Ollama.
+	_, err = generate(context.Background(), c)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.EqualValues(t, 3, calls.Load())
+}
+
+func TestClient_CancelDoesNotTrip(t *testing.T) {
+	srv := ollamatest.NewServer(func(api.GenerateRequest) (string, error) {
+		time.Sleep(100 * time.Millisecond)
+		return "ok", nil
+	})
+	defer srv.Close()
+
+	c, err := New(Config{Endpoint: srv.URL, BreakerThreshold: 1})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = generate(ctx, c)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, stateClosed, c.Breaker().State())
+}
+
+func TestClient_CallbackErrorDoesNotTrip(t *testing.T) {
+	srv := ollamatest.NewServer(func(api.GenerateRequest) (string, error) {
+		return "ответ", nil
+	})
+	defer srv.Close()
+
+	c, err := New(Config{Endpoint: srv.URL, BreakerThreshold: 1})
+	require.NoError(t, err)
+
+	// Ошибка записи ответа клиенту - не отказ Ollama.
+	errWrite := errors.New("broken pipe")
+	for range 3 {
+		err = c.Generate(context.Background(), &api.GenerateRequest{Model: "test"}, func(api.GenerateResponse) error {
+			return errWrite
+		})
+		assert.ErrorIs(t, err, errWrite)
+	}
+	assert.Equal(t, stateClosed, c.Breaker().State())
+
+	_, err = generate(context.Background(), c)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,264 @@
+// Package ollamatest содержит поддельный сервер Ollama для тестов.
+package ollamatest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ollama/ollama/api"
+)
+
+// GenerateFunc формирует ответ модели на запрос генерации.
+// Возврат ошибки приводит к ответу со статусом 500.
+type GenerateFunc func(req api.GenerateRequest) (string, error)
+
+// ChatFunc формирует ответ модели в диалоге.
+// Возврат ошибки приводит к ответу со статусом 500.
+type ChatFunc func(req api.ChatRequest) (string, error)
+
+// EmbedFunc вычисляет вектор текста.
+type EmbedFunc func(text string) []float32
+
+// Server - поддельный сервер Ollama, поддерживающий /api/generate,
+// /api/chat и /api/embed.
+type Server struct {
+	*httptest.Server
+
+	// Задержка между фрагментами потокового ответа.
+	ChunkDelay time.Duration
+	// Число потоковых ответов, прерванных клиентом.
+	canceled atomic.Int32
+
+	mu       sync.Mutex
+	generate GenerateFunc
+	requests []api.GenerateRequest
+	chat     ChatFunc
+	chats    []api.ChatRequest
+	embed    EmbedFunc
+	embeds   []api.EmbedRequest
+}
+
+// NewServer запускает сервер. Его необходимо остановить методом Close.
+func NewServer(fn GenerateFunc) *Server {
+	s := &Server{generate: fn}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/generate", s.handleGenerate)
+	mux.HandleFunc("POST /api/chat", s.handleChat)
+	mux.HandleFunc("POST /api/embed", s.handleEmbed)
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// Requests возвращает полученные сервером запросы генерации.
+func (s *Server) Requests() []api.GenerateRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]api.GenerateRequest{}, s.requests...)
+}
+
+// HandleChat задает ответы на запросы /api/chat.
+// Без него сервер отвечает на них ошибкой.
+func (s *Server) HandleChat(fn ChatFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chat = fn
+}
+
+// ChatRequests возвращает полученные сервером запросы /api/chat.
+func (s *Server) ChatRequests() []api.ChatRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]api.ChatRequest{}, s.chats...)
+}
+
+func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	var req api.GenerateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+
+	text, err := s.generate(req)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	eval := len(strings.Fields(text))
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+
+	// Потоковый ответ передается по словам, как это делает Ollama.
+	if req.Stream == nil || *req.Stream {
+		rc := http.NewResponseController(w)
+		for _, word := range strings.SplitAfter(text, " ") {
+			select {
+			case <-r.Context().Done():
+				s.canceled.Add(1)
+				return
+			case <-time.After(s.ChunkDelay):
+			}
+			enc.Encode(api.GenerateResponse{Model: req.Model, CreatedAt: time.Now(), Response: word})
+			rc.Flush()
+		}
+		text = ""
+	}
+
+	enc.Encode(api.GenerateResponse{
+		Model:      req.Model,
+		CreatedAt:  time.Now(),
+		Response:   text,
+		Done:       true,
+		DoneReason: "stop",
+		Metrics: api.Metrics{
+			PromptEvalCount: len(strings.Fields(req.Prompt)),
+			EvalCount:       eval,
+			TotalDuration:   time.Millisecond,
+		},
+	})
+}
+
+func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
+	var req api.ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.chats = append(s.chats, req)
+	fn := s.chat
+	s.mu.Unlock()
+
+	if fn == nil {
+		writeError(w, http.StatusNotFound, "chat is not supported")
+		return
+	}
+	text, err := fn(req)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var prompt int
+	for _, m := range req.Messages {
+		prompt += len(strings.Fields(m.Content))
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	rc := http.NewResponseController(w)
+	for _, word := range strings.SplitAfter(text, " ") {
+		select {
+		case <-r.Context().Done():
+			s.canceled.Add(1)
+			return
+		case <-time.After(s.ChunkDelay):
+		}
+		enc.Encode(api.ChatResponse{
+			Model:     req.Model,
+			CreatedAt: time.Now(),
+			Message:   api.Message{Role: "assistant", Content: word},
+		})
+		rc.Flush()
+	}
+	enc.Encode(api.ChatResponse{
+		Model:      req.Model,
+		CreatedAt:  time.Now(),
+		Message:    api.Message{Role: "assistant"},
+		Done:       true,
+		DoneReason: "stop",
+		Metrics: api.Metrics{
+			PromptEvalCount: prompt,
+			EvalCount:       len(strings.Fields(text)),
+			TotalDuration:   time.Millisecond,
+		},
+	})
+}
+
+// HandleEmbed задает векторы для запросов /api/embed.
+// Без него сервер отвечает на них ошибкой.
+func (s *Server) HandleEmbed(fn EmbedFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.embed = fn
+}
+
+// EmbedRequests возвращает полученные сервером запросы /api/embed.
+func (s *Server) EmbedRequests() []api.EmbedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]api.EmbedRequest{}, s.embeds...)
+}
+
+func (s *Server) handleEmbed(w http.ResponseWriter, r *http.Request) {
+	var req api.EmbedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.embeds = append(s.embeds, req)
+	fn := s.embed
+	s.mu.Unlock()
+
+	if fn == nil {
+		writeError(w, http.StatusNotFound, "embed is not supported")
+		return
+	}
+
+	// Input - строка или массив строк.
+	var texts []string
+	switch in := req.Input.(type) {
+	case string:
+		texts = []string{in}
+	case []any:
+		for _, v := range in {
+			text, ok := v.(string)
+			if !ok {
+				writeError(w, http.StatusBadRequest, "invalid input")
+				return
+			}
+			texts = append(texts, text)
+		}
+	default:
+		writeError(w, http.StatusBadRequest, "invalid input")
+		return
+	}
+
+	resp := api.EmbedResponse{Model: req.Model, TotalDuration: time.Millisecond}
+	for _, text := range texts {
+		resp.Embeddings = append(resp.Embeddings, fn(text))
+		resp.PromptEvalCount += len(strings.Fields(text))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Canceled возвращает число потоковых ответов, прерванных клиентом.
+func (s *Server) Canceled() int {
+	return int(s.canceled.Load())
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests",
+		},
+		[]string{"method", "path", "status"},
+	)
+
+	httpRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of HTTP requests",
+			Buckets: []float64{0.1, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"method", "path", "status"},
+	)
+
+	classificationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "review_classifications_total",
+			Help: "Total number of review classification attempts",
+		},
+		[]string{"result"},
+	)
+
+	moderationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "review_moderations_total",
+			Help: "Total number of review moderation decisions",
+		},
+		[]string{"moderator", "status"},
+	)
+
+	webhookDeliveriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webhook_deliveries_total",
+			Help: "Total number of webhook delivery attempts",
+		},
+		[]string{"result"},
+	)
+
+	ratingAnomaliesTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "product_rating_anomalies_total",
+			Help: "Total number of detected product rating drops",
+		},
+	)
+
+	llmRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "llm_requests_total",
+			Help: "Total number of LLM requests",
+		},
+		[]string{"operation", "model", "result"},
+	)
+
+	llmRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "llm_request_duration_seconds",
+			Help:    "Duration of LLM requests",
+			Buckets: []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		},
+		[]string{"operation", "model"},
+	)
+
+	llmTokensTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "llm_tokens_total",
+			Help: "Total number of LLM tokens",
+		},
+		[]string{"operation", "model", "kind"},
+	)
+
+	classificationDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "review_classification_duration_seconds",
+			Help:    "Duration of review classification attempts",
+			Buckets: []float64{0.01, 0.1, 0.5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{"result"},
+	)
+)
+
+// Результаты вызова LLM.
+const (
+	LLMSuccess  = "success"
+	LLMError    = "error"
+	LLMRejected = "rejected"
+)
+
+// ObserveLLM учитывает вызов LLM.
+func ObserveLLM(op, model, result string, d time.Duration) {
+	llmRequestsTotal.WithLabelValues(op, model, result).Inc()
+	llmRequestDuration.WithLabelValues(op, model).Observe(d.Seconds())
+}
+
+// AddLLMTokens учитывает токены запроса и ответа LLM.
+func AddLLMTokens(op, model string, prompt, completion int) {
+	llmTokensTotal.WithLabelValues(op, model, "prompt").Add(float64(prompt))
+	llmTokensTotal.WithLabelValues(op, model, "completion").Add(float64(completion))
+}
+
+// Результаты попытки классификации отзыва.
+const (
+	ClassificationDone  = "done"
+	ClassificationRetry = "retry"
+	ClassificationDead  = "dead"
+)
+
+// ObserveClassification учитывает попытку классификации отзыва.
+func ObserveClassification(result string, d time.Duration) {
+	classificationsTotal.WithLabelValues(result).Inc()
+	classificationDuration.WithLabelValues(result).Observe(d.Seconds())
+}
+
+// ObserveModeration учитывает решение по отзыву: moderator - "auto"
+// для автоматической проверки или "manual" для решения модератора.
+func ObserveModeration(moderator, status string) {
+	moderationsTotal.WithLabelValues(moderator, status).Inc()
+}
+
+// Результаты попытки доставки уведомления.
+const (
+	WebhookDelivered = "delivered"
+	WebhookRetry     = "retry"
+	WebhookFailed    = "failed"
+)
+
+// ObserveWebhookDelivery учитывает попытку доставки уведомления подписчику.
+func ObserveWebhookDelivery(result string) {
+	webhookDeliveriesTotal.WithLabelValues(result).Inc()
+}
+
+// ObserveRatingAnomaly учитывает обнаруженное падение рейтинга товара.
+func ObserveRatingAnomaly() {
+	ratingAnomaliesTotal.Inc()
+}
+
+// PrometheusMiddleware - middleware для сбора метрик
+func PrometheusMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		status := strconv.Itoa(ww.Status())
+		duration := time.Since(start).Seconds()
+
+		httpRequestsTotal.WithLabelValues(r.Method, r.URL.Path, status).Inc()
+		httpRequestDuration.WithLabelValues(r.Method, r.URL.Path, status).Observe(duration)
+	})
+}
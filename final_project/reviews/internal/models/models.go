@@ -0,0 +1,245 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// Product - товар или услуга, к которым оставляют отзывы.
+type Product struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Категория товара; пустая, если товар не отнесен к категории.
+	CategoryID string    `json:"category_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Category - категория каталога товаров.
+type Category struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Родительская категория; пустая у категорий верхнего уровня.
+	ParentID  string    `json:"parent_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CategoryRating - рейтинг категории, сведенный по рейтингам товаров
+// категории и всех ее подкатегорий.
+type CategoryRating struct {
+	CategoryID string `json:"category_id"`
+	Name       string `json:"name"`
+	// Оценка от 1 до 5.
+	Score float64 `json:"score"`
+	// Число классифицированных отзывов.
+	Reviews      int          `json:"reviews"`
+	Distribution Distribution `json:"distribution"`
+	// Число товаров категории и подкатегорий.
+	Products int `json:"products"`
+	// Рейтинги подкатегорий в порядке названий.
+	Subcategories []CategoryRating `json:"subcategories,omitempty"`
+}
+
+// Review - отзыв пользователя о товаре.
+type Review struct {
+	ID        string `json:"id"`
+	ProductID string `json:"product_id"`
+	Author    string `json:"author"`
+	Text      string `json:"text"`
+	// Оценка пользователя от 1 до 5 звезд.
+	Rating int `json:"rating"`
+	// Код языка текста (ISO 639-1), определенный при сохранении отзыва;
+	// "und", если язык не определен.
+	Language   string     `json:"language"`
+	Moderation Moderation `json:"moderation"`
+	Sentiment  Sentiment  `json:"sentiment"`
+	// Ранее опубликованный отзыв, который этот почти дословно повторяет.
+	Duplicate *Duplicate `json:"duplicate,omitempty"`
+	// Голоса пользователей за полезность отзыва.
+	Helpful   Helpfulness `json:"helpful"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Границы оценки отзыва.
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Validate проверяет поля отзыва, заполняемые автором.
+func (r Review) Validate() error {
+	switch {
+	case r.Author == "":
+		return errors.New("не указан автор отзыва")
+	case r.Text == "":
+		return errors.New("не указан текст отзыва")
+	case r.Rating < MinRating || r.Rating > MaxRating:
+		return errors.New("оценка должна быть от 1 до 5")
+	}
+	return nil
+}
+
+// Helpfulness - число голосов за полезность отзыва.
+type Helpfulness struct {
+	Up   int `json:"up"`
+	Down int `json:"down"`
+}
+
+// Vote - голос пользователя за полезность отзыва.
+type Vote struct {
+	ReviewID string
+	// Пользователь; у пользователя один голос за отзыв.
+	Voter string
+	// Ключ клиента (хэш адреса), с которого подан голос. С одного клиента
+	// за отзыв голосует только один пользователь.
+	Client  string
+	Helpful bool
+}
+
+// Duplicate - ссылка на отзыв, копией которого признан отзыв.
+type Duplicate struct {
+	ReviewID string `json:"review_id"`
+	// Косинусное сходство векторов текстов отзывов.
+	Similarity float64 `json:"similarity"`
+}
+
+// SimilarReview - отзыв, похожий на заданный.
+type SimilarReview struct {
+	Review     Review  `json:"review"`
+	Similarity float64 `json:"similarity"`
+}
+
+// SearchResult - отзыв, найденный полнотекстовым поиском.
+type SearchResult struct {
+	Review Review  `json:"review"`
+	Rank   float64 `json:"rank"`
+	// Фрагменты текста отзыва (HTML) с найденными словами в тегах mark.
+	Highlight string `json:"highlight"`
+}
+
+// Статусы публикации отзыва.
+const (
+	// Отзыв ожидает решения модератора.
+	ReviewPending = "pending"
+	// Отзыв опубликован.
+	ReviewPublished = "published"
+	// Отзыв отклонен.
+	ReviewRejected = "rejected"
+)
+
+// AutoModerator - имя модератора для решений автоматической проверки.
+const AutoModerator = "auto"
+
+// Moderation - результат модерации отзыва.
+type Moderation struct {
+	Status string `json:"status"`
+	// Причины задержки или отклонения, например "pii:phone" или "profanity".
+	Reasons []string `json:"reasons,omitempty"`
+	// Кто принял решение: AutoModerator или имя модератора.
+	Moderator   string     `json:"moderator,omitempty"`
+	ModeratedAt *time.Time `json:"moderated_at,omitempty"`
+}
+
+// Статусы классификации настроения отзыва.
+const (
+	// Отзыв ожидает классификации в фоновой очереди.
+	SentimentPending = "pending"
+	// Отзыв классифицирован.
+	SentimentDone = "done"
+	// Классификация не удалась после всех попыток.
+	SentimentFailed = "failed"
+)
+
+// Sentiment - настроение отзыва, определенное классификатором.
+type Sentiment struct {
+	Status string `json:"status"`
+	// Метка настроения: positive, neutral или negative.
+	Label      string  `json:"label,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+	// Модель и версия запроса, которыми определено настроение.
+	Model         string `json:"model,omitempty"`
+	PromptVersion string `json:"prompt_version,omitempty"`
+	// Настроение по аспектам товара (качество, цена, доставка, поддержка),
+	// упомянутым в отзыве, в порядке названий аспектов.
+	Aspects []AspectSentiment `json:"aspects,omitempty"`
+}
+
+// Equal сообщает, совпадают ли настроения.
+func (s Sentiment) Equal(o Sentiment) bool {
+	return s.Status == o.Status &&
+		s.Label == o.Label &&
+		s.Confidence == o.Confidence &&
+		s.Model == o.Model &&
+		s.PromptVersion == o.PromptVersion &&
+		slices.Equal(s.Aspects, o.Aspects)
+}
+
+// AspectSentiment - настроение отзыва по аспекту товара.
+type AspectSentiment struct {
+	Aspect     string  `json:"aspect"`
+	Label      string  `json:"label"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Rating - пользовательский рейтинг товара, вычисленный по настроению отзывов.
+type Rating struct {
+	ProductID string `json:"product_id"`
+	// Оценка от 1 до 5.
+	Score float64 `json:"score"`
+	// Число классифицированных отзывов.
+	Reviews      int          `json:"reviews"`
+	Distribution Distribution `json:"distribution"`
+	// Рейтинг по аспектам, упомянутым в отзывах.
+	Aspects map[string]AspectRating `json:"aspects,omitempty"`
+	// Рейтинг по отзывам на каждом языке.
+	Languages map[string]AspectRating `json:"languages,omitempty"`
+}
+
+// AspectRating - рейтинг товара по аспекту или по отзывам на одном языке.
+type AspectRating struct {
+	Score        float64      `json:"score"`
+	Reviews      int          `json:"reviews"`
+	Distribution Distribution `json:"distribution"`
+}
+
+// Distribution - распределение отзывов по меткам настроения.
+type Distribution struct {
+	Positive int `json:"positive"`
+	Neutral  int `json:"neutral"`
+	Negative int `json:"negative"`
+}
+
+// RatingHistory - изменение рейтинга товара по периодам.
+type RatingHistory struct {
+	ProductID string `json:"product_id"`
+	// Длина периода: day или week.
+	Bucket string `json:"bucket"`
+	// Периоды с классифицированными отзывами в порядке времени.
+	Points []RatingPoint `json:"points"`
+}
+
+// RatingPoint - рейтинг по отзывам, оставленным за период.
+type RatingPoint struct {
+	// Начало периода (UTC).
+	Start time.Time `json:"start"`
+	// Средняя оценка отзывов периода от 1 до 5 без сглаживания.
+	Score        float64      `json:"score"`
+	Reviews      int          `json:"reviews"`
+	Distribution Distribution `json:"distribution"`
+}
+
+// RatingAnomaly - резкое падение рейтинга товара: средняя оценка отзывов
+// за окно наблюдения ниже средней оценки за предшествующий окну период.
+type RatingAnomaly struct {
+	ProductID string `json:"product_id"`
+	// Начало окна наблюдения (UTC).
+	WindowStart time.Time `json:"window_start"`
+	// Средняя оценка и число отзывов в окне.
+	Score   float64 `json:"score"`
+	Reviews int     `json:"reviews"`
+	// Средняя оценка и число отзывов за предшествующий период.
+	Baseline        float64   `json:"baseline"`
+	BaselineReviews int       `json:"baseline_reviews"`
+	DetectedAt      time.Time `json:"detected_at"`
+}
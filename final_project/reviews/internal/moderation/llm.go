@@ -0,0 +1,102 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-masters/final_project/reviews/internal/llm"
+	"go-masters/final_project/reviews/internal/prompts"
+
+	"github.com/ollama/ollama/api"
+)
+
+// PromptName - имя шаблона запроса модерации в реестре prompts.
+const PromptName = "moderation"
+
+// llmFormat - JSON схема ответа модели.
+var llmFormat = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"flagged": {"type": "boolean"},
+		"categories": {
+			"type": "array",
+			"items": {"type": "string", "enum": ["insult", "threat", "hate", "profanity", "spam", "pii"]}
+		}
+	},
+	"required": ["flagged", "categories"]
+}`)
+
+// LLM проверяет отзыв моделью Ollama. Нарушение, найденное моделью,
+// передает отзыв модератору: модель ошибается чаще словаря.
+type LLM struct {
+	client  *llm.Client
+	model   string
+	prompt  *prompts.Prompt
+	timeout time.Duration
+}
+
+// NewLLM создает проверку моделью model с шаблоном запроса prompt.
+// Пустой timeout не ограничивает время проверки.
+func NewLLM(client *llm.Client, model string, prompt *prompts.Prompt, timeout time.Duration) *LLM {
+	return &LLM{client: client, model: model, prompt: prompt, timeout: timeout}
+}
+
+func (*LLM) Name() string {
+	return "llm"
+}
+
+func (l *LLM) Detect(ctx context.Context, text string) ([]Finding, error) {
+	prompt, err := l.prompt.Execute(struct{ Text string }{Text: text})
+	if err != nil {
+		return nil, err
+	}
+
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.timeout)
+		defer cancel()
+	}
+
+	stream := false
+	req := &api.GenerateRequest{
+		Model:   l.model,
+		Prompt:  prompt,
+		Stream:  &stream,
+		Format:  llmFormat,
+		Options: map[string]any{"temperature": 0},
+	}
+	var out strings.Builder
+	err = l.client.Generate(ctx, req, func(resp api.GenerateResponse) error {
+		out.WriteString(resp.Response)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации: %w", err)
+	}
+
+	var resp struct {
+		Flagged    *bool    `json:"flagged"`
+		Categories []string `json:"categories"`
+	}
+	if err := json.Unmarshal([]byte(out.String()), &resp); err != nil {
+		return nil, fmt.Errorf("некорректный ответ модели: %w", err)
+	}
+	if resp.Flagged == nil {
+		return nil, errors.New("некорректный ответ модели: нет поля flagged")
+	}
+	if !*resp.Flagged {
+		return nil, nil
+	}
+	if len(resp.Categories) == 0 {
+		return []Finding{{Reason: "llm", Action: Hold}}, nil
+	}
+	res := make([]Finding, len(resp.Categories))
+	for i, c := range resp.Categories {
+		res[i] = Finding{Reason: "llm:" + c, Action: Hold}
+	}
+	return res, nil
+}
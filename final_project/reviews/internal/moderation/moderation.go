@@ -0,0 +1,157 @@
+// Package moderation проверяет отзывы перед публикацией.
+//
+// Проверки (Detector) выполняются этапами конвейера Pipeline[T] из
+// 04-concurrency: персональные данные (телефоны, адреса почты, номера карт),
+// нецензурная лексика и оскорбления по словарю и, при необходимости, LLM.
+// Каждая находка требует либо отклонить отзыв (Block), либо передать его
+// модератору (Hold). Отзыв без находок публикуется сразу. Если отзыв уже
+// отклонен, следующие этапы его не проверяют, чтобы не тратить вызовы LLM.
+package moderation
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	concurrency "go-masters/04-concurrency"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Action - действие, которого требует находка.
+type Action int
+
+const (
+	// Hold - отзыв публикуется только после проверки модератором.
+	Hold Action = iota + 1
+	// Block - отзыв отклоняется.
+	Block
+)
+
+// Finding - нарушение, найденное в тексте отзыва.
+type Finding struct {
+	// Причина, например "pii:email" или "profanity". Найденный текст
+	// не сохраняется, чтобы не копировать персональные данные.
+	Reason string
+	Action Action
+}
+
+// Detector - проверка текста отзыва.
+type Detector interface {
+	// Name - имя проверки для журнала и причин ошибок.
+	Name() string
+	Detect(ctx context.Context, text string) ([]Finding, error)
+}
+
+// Verdict - решение по отзыву.
+type Verdict struct {
+	// Статус публикации: models.ReviewPublished, ReviewPending или ReviewRejected.
+	Status  string
+	Reasons []string
+}
+
+// Moderation возвращает сведения о модерации для сохранения с отзывом.
+func (v Verdict) Moderation() models.Moderation {
+	now := time.Now().UTC()
+	return models.Moderation{
+		Status:      v.Status,
+		Reasons:     v.Reasons,
+		Moderator:   models.AutoModerator,
+		ModeratedAt: &now,
+	}
+}
+
+// check - отзыв на конвейере проверок.
+type check struct {
+	text     string
+	findings []Finding
+}
+
+// blocked сообщает, что отзыв уже отклонен.
+func (c *check) blocked() bool {
+	return slices.ContainsFunc(c.findings, func(f Finding) bool { return f.Action == Block })
+}
+
+func (c *check) verdict() Verdict {
+	v := Verdict{Status: models.ReviewPublished}
+	for _, f := range c.findings {
+		if !slices.Contains(v.Reasons, f.Reason) {
+			v.Reasons = append(v.Reasons, f.Reason)
+		}
+		switch {
+		case f.Action == Block:
+			v.Status = models.ReviewRejected
+		case f.Action == Hold && v.Status == models.ReviewPublished:
+			v.Status = models.ReviewPending
+		}
+	}
+	return v
+}
+
+// Moderator проверяет отзывы набором проверок.
+type Moderator struct {
+	detectors []Detector
+}
+
+// New создает модератор. Проверки выполняются в порядке перечисления;
+// дорогие проверки (LLM) следует указывать последними.
+func New(detectors ...Detector) *Moderator {
+	return &Moderator{detectors: detectors}
+}
+
+// Default создает модератор со встроенными проверками без LLM.
+func Default() *Moderator {
+	return New(NewPII(), NewWordList(Profanity, Toxic))
+}
+
+// Moderate проверяет текст отзыва.
+func (m *Moderator) Moderate(ctx context.Context, text string) Verdict {
+	return m.ModerateAll(ctx, []string{text})[0]
+}
+
+// ModerateAll проверяет тексты отзывов и возвращает решения в том же
+// порядке. Ошибка проверки передает отзыв модератору.
+func (m *Moderator) ModerateAll(ctx context.Context, texts []string) []Verdict {
+	stages := make([]concurrency.Stage[*check], len(m.detectors))
+	for i, d := range m.detectors {
+		stages[i] = stage(ctx, d)
+	}
+
+	in := make(chan *check)
+	go func() {
+		defer close(in)
+		for _, text := range texts {
+			in <- &check{text: text}
+		}
+	}()
+
+	// Этапы обрабатывают отзывы по одному, поэтому порядок сохраняется.
+	res := make([]Verdict, 0, len(texts))
+	for c := range concurrency.Pipeline(stages...)(in) {
+		res = append(res, c.verdict())
+	}
+	return res
+}
+
+// stage оборачивает проверку в этап конвейера.
+func stage(ctx context.Context, d Detector) concurrency.Stage[*check] {
+	return func(in <-chan *check) <-chan *check {
+		out := make(chan *check)
+		go func() {
+			defer close(out)
+			for c := range in {
+				if !c.blocked() {
+					findings, err := d.Detect(ctx, c.text)
+					if err != nil {
+						log.Warn().Err(err).Str("detector", d.Name()).Msg("Ошибка проверки отзыва")
+						findings = []Finding{{Reason: "error:" + d.Name(), Action: Hold}}
+					}
+					c.findings = append(c.findings, findings...)
+				}
+				out <- c
+			}
+		}()
+		return out
+	}
+}
@@ -0,0 +1,142 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go-masters/final_project/reviews/internal/llm"
+	"go-masters/final_project/reviews/internal/llm/ollamatest"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/prompts"
+
+	"github.com/ollama/ollama/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPII(t *testing.T) {
+	tests := []struct {
+		text string
+		want []Finding
+	}{
+		{text: "Хороший чайник, модель 2024 года", want: nil},
+		{text: "Пишите на ivan.petrov@mail.ru", want: []Finding{{ReasonEmail, Block}}},
+		{text: "Звоните +7 (912) 345-67-89", want: []Finding{{ReasonPhone, Hold}}},
+		{text: "Мой номер 89123456789", want: []Finding{{ReasonPhone, Hold}}},
+		{text: "Оплатил картой 4111 1111 1111 1111", want: []Finding{{ReasonCard, Block}}},
+		// Последовательность цифр, не проходящая проверку Луна, - не карта
+		{text: "Заказ 4111 1111 1111 1112 пришел", want: nil},
+		{text: "Артикул 12-34", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := NewPII().Detect(context.Background(), tt.text)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWordList(t *testing.T) {
+	wl := NewWordList(Profanity, Toxic)
+
+	tests := []struct {
+		text string
+		want []Finding
+	}{
+		{text: "Отличный чайник", want: nil},
+		{text: "Продавец - ИДИОТ", want: []Finding{{ReasonToxic, Hold}}},
+		{text: "Fucking awful", want: []Finding{{ReasonProfanity, Block}}},
+		// Совпадение проверяется с начала слова
+		{text: "Небанальный дизайн, урожай яблок", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := wl.Detect(context.Background(), tt.text)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// detectorFunc - проверка для тестов.
+type detectorFunc func(text string) ([]Finding, error)
+
+func (detectorFunc) Name() string {
+	return "test"
+}
+
+func (f detectorFunc) Detect(_ context.Context, text string) ([]Finding, error) {
+	return f(text)
+}
+
+func TestModerator(t *testing.T) {
+	var checked []string
+	last := detectorFunc(func(text string) ([]Finding, error) {
+		checked = append(checked, text)
+		if strings.Contains(text, "ошибка") {
+			return nil, errors.New("сбой")
+		}
+		return nil, nil
+	})
+	m := New(NewPII(), NewWordList(Profanity, Toxic), last)
+
+	verdicts := m.ModerateAll(context.Background(), []string{
+		"Отличный чайник",
+		"Звоните +7 912 345 67 89, продавец идиот",
+		"Карта 4111111111111111, звоните +7 912 345 67 89",
+		"ошибка",
+	})
+	assert.Equal(t, []Verdict{
+		{Status: models.ReviewPublished},
+		{Status: models.ReviewPending, Reasons: []string{ReasonPhone, ReasonToxic}},
+		{Status: models.ReviewRejected, Reasons: []string{ReasonCard, ReasonPhone}},
+		{Status: models.ReviewPending, Reasons: []string{"error:test"}},
+	}, verdicts)
+
+	// Отклоненный отзыв не передается следующим проверкам
+	assert.NotContains(t, checked, "Карта 4111111111111111, звоните +7 912 345 67 89")
+	assert.Len(t, checked, 3)
+
+	mod := verdicts[1].Moderation()
+	assert.Equal(t, models.AutoModerator, mod.Moderator)
+	assert.NotNil(t, mod.ModeratedAt)
+}
+
+func TestLLM(t *testing.T) {
+	srv := ollamatest.NewServer(func(req api.GenerateRequest) (string, error) {
+		switch {
+		case strings.Contains(req.Prompt, "реклама"):
+			return `{"flagged": true, "categories": ["spam"]}`, nil
+		case strings.Contains(req.Prompt, "мусор"):
+			return `не JSON`, nil
+		default:
+			return `{"flagged": false, "categories": []}`, nil
+		}
+	})
+	defer srv.Close()
+
+	client, err := llm.New(llm.Config{Endpoint: srv.URL})
+	require.NoError(t, err)
+	prompt, err := prompts.Default().Get(PromptName, prompts.Latest)
+	require.NoError(t, err)
+	d := NewLLM(client, "test", prompt, 0)
+
+	got, err := d.Detect(context.Background(), "Хороший чайник")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	got, err = d.Detect(context.Background(), "Купите, реклама")
+	require.NoError(t, err)
+	assert.Equal(t, []Finding{{Reason: "llm:spam", Action: Hold}}, got)
+
+	_, err = d.Detect(context.Background(), "мусор")
+	assert.Error(t, err)
+
+	reqs := srv.Requests()
+	require.Len(t, reqs, 3)
+	assert.Contains(t, reqs[0].Prompt, "Хороший чайник")
+	assert.NotEmpty(t, reqs[0].Format)
+}
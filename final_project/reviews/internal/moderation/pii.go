@@ -0,0 +1,85 @@
+package moderation
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// Причины находок персональных данных.
+const (
+	ReasonEmail = "pii:email"
+	ReasonPhone = "pii:phone"
+	ReasonCard  = "pii:card"
+)
+
+var (
+	emailRe = regexp.MustCompile(`[\p{L}\d._%+-]+@[\p{L}\d-]+(?:\.[\p{L}\d-]+)*\.\p{L}{2,}`)
+	// Номер карты - 13-19 цифр, возможно разделенных пробелами или дефисами.
+	cardRe = regexp.MustCompile(`\d(?:[ -]?\d){12,18}`)
+	// Телефон - 10-12 цифр с необязательным "+" и разделителями
+	// (пробелы, дефисы, скобки), например +7 (912) 345-67-89.
+	phoneRe = regexp.MustCompile(`\+?\d[\s(-]*\d{3}[\s)-]*\d{3}[\s-]*\d{2}[\s-]*\d{2}\d{0,2}`)
+)
+
+// PII находит персональные данные. Номер карты (проверенный по алгоритму
+// Луна) и адрес почты отклоняют отзыв; телефон передается модератору,
+// так как похожие последовательности цифр встречаются в артикулах и номерах
+// заказов.
+type PII struct{}
+
+func NewPII() *PII {
+	return &PII{}
+}
+
+func (*PII) Name() string {
+	return "pii"
+}
+
+func (*PII) Detect(_ context.Context, text string) ([]Finding, error) {
+	var res []Finding
+	if emailRe.MatchString(text) {
+		res = append(res, Finding{Reason: ReasonEmail, Action: Block})
+	}
+
+	// Найденные номера карт вырезаются, чтобы их части
+	// не считались телефонами.
+	card := false
+	text = cardRe.ReplaceAllStringFunc(text, func(s string) string {
+		if !luhn(s) {
+			return s
+		}
+		card = true
+		return " "
+	})
+	if card {
+		res = append(res, Finding{Reason: ReasonCard, Action: Block})
+	}
+	if phoneRe.MatchString(text) {
+		res = append(res, Finding{Reason: ReasonPhone, Action: Hold})
+	}
+	return res, nil
+}
+
+// luhn проверяет контрольную цифру номера карты.
+func luhn(s string) bool {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+
+	var sum int
+	for i := range len(digits) {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
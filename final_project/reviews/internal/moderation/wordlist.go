@@ -0,0 +1,88 @@
+package moderation
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// Причины находок словаря.
+const (
+	ReasonProfanity = "profanity"
+	ReasonToxic     = "toxicity"
+)
+
+// Words - словарь основ слов с причиной и действием.
+type Words struct {
+	Reason string
+	Action Action
+	// Основы слов в нижнем регистре, "ё" заменена на "е". Слово текста
+	// совпадает с основой, если начинается с нее.
+	Stems []string
+}
+
+// Profanity - нецензурная лексика; отзыв отклоняется.
+var Profanity = Words{
+	Reason: ReasonProfanity,
+	Action: Block,
+	Stems: []string{
+		"хуй", "хуе", "хуи", "хуя", "пизд", "ебан", "ебат", "ебал", "ебну", "заеб",
+		"уеб", "выеб", "бляд", "блят", "сука", "суки", "сучк", "мудак", "мудил", "пидор",
+		"fuck", "shit", "bitch", "cunt", "asshole", "motherfuck",
+	},
+}
+
+// Toxic - оскорбления и угрозы; отзыв передается модератору, так как
+// такие слова встречаются и в допустимом контексте ("не для идиотов").
+var Toxic = Words{
+	Reason: ReasonToxic,
+	Action: Hold,
+	Stems: []string{
+		"идиот", "дебил", "кретин", "придур", "урод", "дура", "дурак", "тупиц", "ублюд",
+		"мраз", "твар", "скотин", "сдохн", "убью", "убей",
+		"idiot", "moron", "stupid", "retard", "scum", "bastard",
+	},
+}
+
+// WordList находит слова из словарей.
+type WordList struct {
+	lists []Words
+}
+
+func NewWordList(lists ...Words) *WordList {
+	return &WordList{lists: lists}
+}
+
+func (*WordList) Name() string {
+	return "wordlist"
+}
+
+func (wl *WordList) Detect(_ context.Context, text string) ([]Finding, error) {
+	words := strings.FieldsFunc(normalize(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	var res []Finding
+	for _, l := range wl.lists {
+		if containsStem(words, l.Stems) {
+			res = append(res, Finding{Reason: l.Reason, Action: l.Action})
+		}
+	}
+	return res, nil
+}
+
+// normalize приводит текст к нижнему регистру и заменяет "ё" на "е".
+func normalize(text string) string {
+	return strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+}
+
+func containsStem(words, stems []string) bool {
+	for _, w := range words {
+		for _, s := range stems {
+			if strings.HasPrefix(w, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
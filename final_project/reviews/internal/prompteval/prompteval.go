@@ -0,0 +1,184 @@
+// Package prompteval сравнивает две версии запроса классификации (A/B)
+// на размеченном наборе отзывов.
+//
+// Каждый отзыв классифицируется обеими версиями поочередно, чтобы колебания
+// нагрузки на модель одинаково влияли на обе. Для каждой версии вычисляются
+// точность, число ошибок и задержка, для пары - доля совпавших меток
+// (согласованность) среди отзывов, классифицированных обеими версиями.
+package prompteval
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go-masters/final_project/reviews/internal/sentiment"
+)
+
+// Sample - размеченный отзыв.
+type Sample struct {
+	Text  string          `json:"text"`
+	Label sentiment.Label `json:"label"`
+}
+
+// LoadDataset читает набор в формате JSON Lines: по объекту
+// {"text": "...", "label": "..."} в строке. Пустые строки пропускаются.
+func LoadDataset(r io.Reader) ([]Sample, error) {
+	var data []Sample
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var s Sample
+		if err := json.Unmarshal([]byte(line), &s); err != nil {
+			return nil, fmt.Errorf("строка %d: %w", n, err)
+		}
+		if !s.Label.Valid() {
+			return nil, fmt.Errorf("строка %d: неизвестная метка %q", n, s.Label)
+		}
+		data = append(data, s)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("набор пуст")
+	}
+	return data, nil
+}
+
+// Candidate - сравниваемая версия запроса.
+type Candidate struct {
+	Name       string
+	Classifier sentiment.Classifier
+}
+
+// Result - результаты одной версии.
+type Result struct {
+	Name    string
+	Correct int
+	// Число отзывов, которые не удалось классифицировать.
+	Errors      int
+	Accuracy    float64
+	MeanLatency time.Duration
+	P95Latency  time.Duration
+	// Метки по отзывам набора; пустая метка - ошибка классификации.
+	Labels []sentiment.Label
+}
+
+// Report - результаты сравнения.
+type Report struct {
+	Samples int
+	A, B    Result
+	// Доля совпавших меток среди отзывов, классифицированных обеими версиями.
+	Agreement float64
+}
+
+// Compare классифицирует набор версиями a и b. Ошибки классификации
+// учитываются в результатах; сравнение прерывается только отменой контекста.
+func Compare(ctx context.Context, a, b Candidate, data []Sample) (Report, error) {
+	runs := []*run{newRun(a, len(data)), newRun(b, len(data))}
+	for i, s := range data {
+		for _, r := range runs {
+			if err := r.classify(ctx, i, s); err != nil {
+				return Report{}, err
+			}
+		}
+	}
+
+	rep := Report{Samples: len(data), A: runs[0].result(), B: runs[1].result()}
+	var both, same int
+	for i := range data {
+		la, lb := rep.A.Labels[i], rep.B.Labels[i]
+		if la == "" || lb == "" {
+			continue
+		}
+		both++
+		if la == lb {
+			same++
+		}
+	}
+	if both > 0 {
+		rep.Agreement = float64(same) / float64(both)
+	}
+	return rep, nil
+}
+
+// run - накопление результатов одной версии.
+type run struct {
+	classifier sentiment.Classifier
+	res        Result
+	latencies  []time.Duration
+}
+
+func newRun(c Candidate, n int) *run {
+	return &run{
+		classifier: c.Classifier,
+		res:        Result{Name: c.Name, Labels: make([]sentiment.Label, n)},
+		latencies:  make([]time.Duration, 0, n),
+	}
+}
+
+// classify классифицирует отзыв i набора.
+func (r *run) classify(ctx context.Context, i int, s Sample) error {
+	start := time.Now()
+	res, err := r.classifier.Classify(ctx, s.Text)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	r.latencies = append(r.latencies, time.Since(start))
+	if err != nil {
+		r.res.Errors++
+		return nil
+	}
+	r.res.Labels[i] = res.Label
+	if res.Label == s.Label {
+		r.res.Correct++
+	}
+	return nil
+}
+
+// result вычисляет итоговые показатели.
+func (r *run) result() Result {
+	res := r.res
+	if n := len(res.Labels); n > 0 {
+		res.Accuracy = float64(res.Correct) / float64(n)
+	}
+	if len(r.latencies) == 0 {
+		return res
+	}
+
+	var sum time.Duration
+	for _, d := range r.latencies {
+		sum += d
+	}
+	res.MeanLatency = sum / time.Duration(len(r.latencies))
+
+	sorted := slices.Clone(r.latencies)
+	slices.Sort(sorted)
+	res.P95Latency = sorted[(len(sorted)*95+99)/100-1]
+	return res
+}
+
+// Write выводит отчет таблицей.
+func (rep Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Отзывов: %d\n\n", rep.Samples)
+	fmt.Fprintln(tw, "Версия\tТочность\tОшибок\tСредняя задержка\tp95")
+	for _, r := range []Result{rep.A, rep.B} {
+		fmt.Fprintf(tw, "%s\t%.1f%%\t%d\t%s\t%s\n",
+			r.Name, r.Accuracy*100, r.Errors,
+			r.MeanLatency.Round(time.Millisecond), r.P95Latency.Round(time.Millisecond))
+	}
+	fmt.Fprintf(tw, "\nСогласованность: %.1f%%\n", rep.Agreement*100)
+	return tw.Flush()
+}
@@ -0,0 +1,101 @@
+package prompteval
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go-masters/final_project/reviews/internal/llm"
+	"go-masters/final_project/reviews/internal/llm/ollamatest"
+	"go-masters/final_project/reviews/internal/prompts"
+	"go-masters/final_project/reviews/internal/sentiment"
+	"go-masters/final_project/reviews/internal/sentiment/ollama"
+
+	"github.com/ollama/ollama/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dataset = `{"text": "Отличный чайник", "label": "positive"}
+{"text": "Ужасный чайник", "label": "negative"}
+
+{"text": "Обычный чайник", "label": "neutral"}
+{"text": "Сломанный чайник", "label": "negative"}
+`
+
+func TestLoadDataset(t *testing.T) {
+	data, err := LoadDataset(strings.NewReader(dataset))
+	require.NoError(t, err)
+	require.Len(t, data, 4)
+	assert.Equal(t, Sample{Text: "Ужасный чайник", Label: sentiment.Negative}, data[1])
+
+	_, err = LoadDataset(strings.NewReader(`{"text": "Чайник", "label": "bad"}`))
+	assert.ErrorContains(t, err, "строка 1")
+
+	_, err = LoadDataset(strings.NewReader("\n"))
+	assert.Error(t, err)
+}
+
+func TestCompare(t *testing.T) {
+	// Модель-заглушка: по запросу второй версии отвечает верно, по первой
+	// считает все отзывы положительными, а на "Сломанный" отвечает ошибкой.
+	srv := ollamatest.NewServer(func(req api.GenerateRequest) (string, error) {
+		switch {
+		case !strings.Contains(req.Prompt, "aspects"):
+			if strings.Contains(req.Prompt, "Сломанный") {
+				return "", errors.New("out of memory")
+			}
+			return `{"label": "positive", "confidence": 0.9}`, nil
+		case strings.Contains(req.Prompt, "Отличный"):
+			return `{"label": "positive", "confidence": 0.9, "aspects": []}`, nil
+		case strings.Contains(req.Prompt, "Обычный"):
+			return `{"label": "neutral", "confidence": 0.6, "aspects": []}`, nil
+		default:
+			return `{"label": "negative", "confidence": 0.8, "aspects": []}`, nil
+		}
+	})
+	defer srv.Close()
+
+	client, err := llm.New(llm.Config{Endpoint: srv.URL})
+	require.NoError(t, err)
+	candidate := func(version string) Candidate {
+		p, err := prompts.Default().Get(ollama.PromptName, version)
+		require.NoError(t, err)
+		c, err := ollama.New(ollama.Config{Client: client, Model: "test", Prompt: p})
+		require.NoError(t, err)
+		return Candidate{Name: version, Classifier: c}
+	}
+
+	data, err := LoadDataset(strings.NewReader(dataset))
+	require.NoError(t, err)
+
+	rep, err := Compare(context.Background(), candidate("1"), candidate("2"), data)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, rep.Samples)
+	assert.Equal(t, 1, rep.A.Correct)
+	assert.Equal(t, 1, rep.A.Errors)
+	assert.InDelta(t, 0.25, rep.A.Accuracy, 1e-9)
+	assert.Equal(t, 1.0, rep.B.Accuracy)
+	assert.Zero(t, rep.B.Errors)
+	// Из трех отзывов, классифицированных обеими версиями, совпал один
+	assert.InDelta(t, 1.0/3, rep.Agreement, 1e-9)
+	assert.Positive(t, rep.B.MeanLatency)
+	assert.Positive(t, rep.B.P95Latency)
+
+	// Запросы версий чередуются
+	reqs := srv.Requests()
+	require.Len(t, reqs, 8)
+	assert.NotContains(t, reqs[0].Prompt, "aspects")
+	assert.Contains(t, reqs[1].Prompt, "aspects")
+
+	var out strings.Builder
+	require.NoError(t, rep.Write(&out))
+	assert.Contains(t, out.String(), "Согласованность: 33.3%")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Compare(ctx, candidate("1"), candidate("2"), data)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,147 @@
+// Package prompts хранит версионированные шаблоны запросов к LLM.
+//
+// Шаблон - файл text/template <имя>/<версия>.tmpl. Версия шаблона сохраняется
+// вместе с результатом классификации, поэтому опубликованная версия не
+// меняется: измененный запрос добавляется новым файлом. Шаблоны по умолчанию
+// встроены в бинарный файл (каталог templates); их можно заменить каталогом
+// на диске, не пересобирая сервис.
+package prompts
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Latest - версия, означающая последнюю версию шаблона.
+const Latest = "latest"
+
+// ext - расширение файлов шаблонов.
+const ext = ".tmpl"
+
+// ErrNotFound - шаблон с указанными именем и версией не найден.
+var ErrNotFound = errors.New("шаблон запроса не найден")
+
+//go:embed templates
+var embedded embed.FS
+
+// Prompt - версия шаблона запроса.
+type Prompt struct {
+	Name    string
+	Version string
+	tmpl    *template.Template
+}
+
+// Execute подставляет данные в шаблон.
+func (p *Prompt) Execute(data any) (string, error) {
+	var buf bytes.Buffer
+	if err := p.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("шаблон %s/%s: %w", p.Name, p.Version, err)
+	}
+	return buf.String(), nil
+}
+
+// Parse создает версию шаблона из текста.
+func Parse(name, version, text string) (*Prompt, error) {
+	tmpl, err := template.New(name + "/" + version).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора шаблона %s/%s: %w", name, version, err)
+	}
+	return &Prompt{Name: name, Version: version, tmpl: tmpl}, nil
+}
+
+// Registry - набор шаблонов запросов.
+type Registry struct {
+	// Версии шаблонов по имени, по возрастанию версии.
+	prompts map[string][]*Prompt
+}
+
+// Default возвращает реестр встроенных шаблонов.
+func Default() *Registry {
+	sub, err := fs.Sub(embedded, "templates")
+	if err != nil {
+		panic(err)
+	}
+	r, err := Load(sub)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Load загружает шаблоны <имя>/<версия>.tmpl из fsys.
+func Load(fsys fs.FS) (*Registry, error) {
+	r := &Registry{prompts: make(map[string][]*Prompt)}
+
+	files, err := fs.Glob(fsys, "*/*"+ext)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		text, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		name := path.Dir(file)
+		p, err := Parse(name, strings.TrimSuffix(path.Base(file), ext), string(text))
+		if err != nil {
+			return nil, err
+		}
+		r.prompts[name] = append(r.prompts[name], p)
+	}
+	if len(r.prompts) == 0 {
+		return nil, errors.New("шаблоны запросов не найдены")
+	}
+
+	for _, versions := range r.prompts {
+		slices.SortFunc(versions, func(a, b *Prompt) int {
+			return compareVersions(a.Version, b.Version)
+		})
+	}
+	return r, nil
+}
+
+// Get возвращает шаблон name версии version. Пустая версия или Latest
+// означают последнюю версию.
+func (r *Registry) Get(name, version string) (*Prompt, error) {
+	versions := r.prompts[name]
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if version == "" || version == Latest {
+		return versions[len(versions)-1], nil
+	}
+	for _, p := range versions {
+		if p.Version == version {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s/%s", ErrNotFound, name, version)
+}
+
+// Versions возвращает версии шаблона name по возрастанию.
+func (r *Registry) Versions(name string) []string {
+	var res []string
+	for _, p := range r.prompts[name] {
+		res = append(res, p.Version)
+	}
+	return res
+}
+
+// compareVersions сравнивает версии: числовые - как числа,
+// остальные - как строки.
+func compareVersions(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return na - nb
+	}
+	return strings.Compare(a, b)
+}
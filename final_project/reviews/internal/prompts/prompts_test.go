@@ -0,0 +1,65 @@
+package prompts
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	r := Default()
+
+	assert.Equal(t, []string{"1", "2"}, r.Versions("sentiment"))
+
+	p, err := r.Get("sentiment", Latest)
+	require.NoError(t, err)
+	assert.Equal(t, "2", p.Version)
+
+	text, err := p.Execute(struct{ Text string }{Text: "Отличный чайник"})
+	require.NoError(t, err)
+	assert.Contains(t, text, "Отличный чайник")
+	assert.Contains(t, text, "aspects")
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"summary/2.tmpl":  {Data: []byte("v2 {{.Text}}")},
+		"summary/10.tmpl": {Data: []byte("v10 {{.Text}}")},
+		"summary/1.tmpl":  {Data: []byte("v1 {{.Text}}")},
+		"README.md":       {Data: []byte("не шаблон")},
+	}
+	r, err := Load(fsys)
+	require.NoError(t, err)
+
+	// Числовые версии сравниваются как числа
+	assert.Equal(t, []string{"1", "2", "10"}, r.Versions("summary"))
+
+	p, err := r.Get("summary", "")
+	require.NoError(t, err)
+	assert.Equal(t, "10", p.Version)
+
+	p, err = r.Get("summary", "2")
+	require.NoError(t, err)
+	text, err := p.Execute(struct{ Text string }{Text: "отзыв"})
+	require.NoError(t, err)
+	assert.Equal(t, "v2 отзыв", text)
+
+	_, err = r.Get("summary", "3")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = r.Get("unknown", "")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Отсутствующее поле данных - ошибка, а не пустая строка в запросе
+	_, err = p.Execute(map[string]string{})
+	assert.Error(t, err)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	_, err := Load(fstest.MapFS{})
+	assert.Error(t, err)
+
+	_, err = Load(fstest.MapFS{"sentiment/1.tmpl": {Data: []byte("{{.Text")}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,207 @@
+// Package rating вычисляет пользовательский рейтинг товара по настроению отзывов.
+//
+// Каждый классифицированный отзыв дает оценку по шкале от 1 до 5
+// (negative - 1, neutral - 3, positive - 5) с весом, равным уверенности
+// классификатора, умноженной на коэффициент свежести отзыва. Свежесть убывает
+// экспоненциально с периодом полураспада HalfLife.
+//
+// Чтобы не пересчитывать рейтинг по всем отзывам, хранилище поддерживает
+// агрегат товара: суммы весов и взвешенных оценок. Веса отсчитываются от
+// фиксированной эпохи, поэтому вклад отзыва не меняется со временем и
+// агрегат обновляется инкрементально; затухание относительно текущего момента
+// применяется при вычислении рейтинга.
+package rating
+
+import (
+	"math"
+	"time"
+
+	"go-masters/final_project/reviews/internal/models"
+)
+
+const (
+	// HalfLife - период, за который вес отзыва уменьшается вдвое.
+	HalfLife = 180 * 24 * time.Hour
+	// PriorMean - априорная оценка товара без отзывов.
+	PriorMean = 3.0
+	// PriorWeight - вес априорной оценки (в "полных" свежих отзывах
+	// с уверенностью 1). Сглаживает рейтинг товаров с малым числом отзывов.
+	PriorWeight = 5.0
+)
+
+// epoch - точка отсчета весов. Изменение эпохи или HalfLife требует
+// пересчета агрегатов (см. миграцию product_ratings).
+var epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// scores - оценки отзывов по меткам настроения.
+var scores = map[string]float64{
+	"negative": 1,
+	"neutral":  3,
+	"positive": 5,
+}
+
+// Aggregate - агрегат отзывов товара.
+type Aggregate struct {
+	Positive int
+	Neutral  int
+	Negative int
+	// Сумма весов отзывов относительно эпохи.
+	WeightSum float64
+	// Сумма взвешенных оценок отзывов относительно эпохи.
+	ScoreSum float64
+}
+
+// Of возвращает вклад отзыва в агрегат. Неклассифицированные
+// отзывы в рейтинге не учитываются.
+func Of(s models.Sentiment, createdAt time.Time) Aggregate {
+	score, ok := scores[s.Label]
+	if s.Status != models.SentimentDone || !ok {
+		return Aggregate{}
+	}
+
+	w := s.Confidence * growth(createdAt)
+	a := Aggregate{WeightSum: w, ScoreSum: w * score}
+	switch s.Label {
+	case "positive":
+		a.Positive = 1
+	case "neutral":
+		a.Neutral = 1
+	case "negative":
+		a.Negative = 1
+	}
+	return a
+}
+
+// OfAspects возвращает вклад отзыва в агрегаты аспектов товара.
+// Оценки аспектов взвешиваются так же, как оценка отзыва в целом.
+func OfAspects(s models.Sentiment, createdAt time.Time) map[string]Aggregate {
+	if s.Status != models.SentimentDone || len(s.Aspects) == 0 {
+		return nil
+	}
+	res := make(map[string]Aggregate, len(s.Aspects))
+	for _, as := range s.Aspects {
+		a := Of(models.Sentiment{
+			Status:     models.SentimentDone,
+			Label:      as.Label,
+			Confidence: as.Confidence,
+		}, createdAt)
+		if !a.IsZero() {
+			res[as.Aspect] = a
+		}
+	}
+	return res
+}
+
+// DiffGroups возвращает изменения агрегатов групп отзывов при переходе
+// from -> to, например вкладов отзыва в агрегаты аспектов. Группы
+// без изменений опускаются.
+func DiffGroups(from, to map[string]Aggregate) map[string]Aggregate {
+	res := make(map[string]Aggregate, len(from)+len(to))
+	for key, a := range to {
+		res[key] = a.Sub(from[key])
+	}
+	for key, a := range from {
+		if _, ok := to[key]; !ok {
+			res[key] = Aggregate{}.Sub(a)
+		}
+	}
+	for key, d := range res {
+		if d.IsZero() {
+			delete(res, key)
+		}
+	}
+	return res
+}
+
+// Add возвращает сумму агрегатов. Суммы весов агрегата без отзывов
+// обнуляются: после вычитания всех вкладов в них остается только
+// ошибка округления.
+func (a Aggregate) Add(b Aggregate) Aggregate {
+	sum := Aggregate{
+		Positive:  a.Positive + b.Positive,
+		Neutral:   a.Neutral + b.Neutral,
+		Negative:  a.Negative + b.Negative,
+		WeightSum: a.WeightSum + b.WeightSum,
+		ScoreSum:  a.ScoreSum + b.ScoreSum,
+	}
+	if sum.Count() == 0 {
+		sum.WeightSum, sum.ScoreSum = 0, 0
+	}
+	return sum
+}
+
+// Sub возвращает разность агрегатов.
+func (a Aggregate) Sub(b Aggregate) Aggregate {
+	return a.Add(Aggregate{
+		Positive:  -b.Positive,
+		Neutral:   -b.Neutral,
+		Negative:  -b.Negative,
+		WeightSum: -b.WeightSum,
+		ScoreSum:  -b.ScoreSum,
+	})
+}
+
+// IsZero сообщает, что агрегат пуст.
+func (a Aggregate) IsZero() bool {
+	return a == Aggregate{}
+}
+
+// Count - число учтенных отзывов.
+func (a Aggregate) Count() int {
+	return a.Positive + a.Neutral + a.Negative
+}
+
+// Mean возвращает среднюю оценку отзывов агрегата, взвешенную по
+// уверенности классификатора, без априорной оценки и затухания;
+// 0 для пустого агрегата. Подходит для отзывов за короткий период,
+// в пределах которого коэффициенты свежести почти не различаются.
+func (a Aggregate) Mean() float64 {
+	if a.Count() <= 0 || a.WeightSum <= 0 {
+		return 0
+	}
+	return math.Round(a.ScoreSum/a.WeightSum*100) / 100
+}
+
+// Compute вычисляет рейтинг товара на момент now.
+func Compute(productID string, a Aggregate, now time.Time) models.Rating {
+	// Приводим веса от эпохи к текущему моменту.
+	decay := 1 / growth(now)
+	weight := max(a.WeightSum*decay, 0)
+	sum := max(a.ScoreSum*decay, 0)
+
+	score := (PriorWeight*PriorMean + sum) / (PriorWeight + weight)
+
+	return models.Rating{
+		ProductID: productID,
+		Score:     math.Round(score*100) / 100,
+		Reviews:   a.Count(),
+		Distribution: models.Distribution{
+			Positive: a.Positive,
+			Neutral:  a.Neutral,
+			Negative: a.Negative,
+		},
+	}
+}
+
+// ComputeGroups вычисляет рейтинги групп отзывов товара, например
+// по аспектам или языкам, на момент now.
+func ComputeGroups(groups map[string]Aggregate, now time.Time) map[string]models.AspectRating {
+	if len(groups) == 0 {
+		return nil
+	}
+	res := make(map[string]models.AspectRating, len(groups))
+	for key, a := range groups {
+		r := Compute("", a, now)
+		res[key] = models.AspectRating{
+			Score:        r.Score,
+			Reviews:      r.Reviews,
+			Distribution: r.Distribution,
+		}
+	}
+	return res
+}
+
+// growth - коэффициент свежести момента t относительно эпохи.
+func growth(t time.Time) float64 {
+	return math.Exp2(float64(t.Sub(epoch)) / float64(HalfLife))
+}
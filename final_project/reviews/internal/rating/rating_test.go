@@ -0,0 +1,134 @@
+package rating
+
+import (
+	"testing"
+	"time"
+
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func done(label string, confidence float64) models.Sentiment {
+	return models.Sentiment{Status: models.SentimentDone, Label: label, Confidence: confidence}
+}
+
+func TestOf(t *testing.T) {
+	now := time.Now()
+
+	assert.True(t, Of(models.Sentiment{Status: models.SentimentPending}, now).IsZero())
+	assert.True(t, Of(models.Sentiment{Status: models.SentimentFailed}, now).IsZero())
+
+	a := Of(done("positive", 0.5), now)
+	assert.Equal(t, 1, a.Positive)
+	assert.InDelta(t, 5*a.WeightSum, a.ScoreSum, 1e-9)
+
+	// Отзыв, оставленный на период полураспада раньше, весит вдвое меньше.
+	old := Of(done("positive", 0.5), now.Add(-HalfLife))
+	assert.InDelta(t, a.WeightSum/2, old.WeightSum, 1e-9)
+}
+
+func TestAggregate_AddSub(t *testing.T) {
+	now := time.Now()
+	a := Of(done("positive", 0.9), now)
+	b := Of(done("negative", 0.4), now)
+
+	sum := a.Add(b)
+	assert.Equal(t, 2, sum.Count())
+	// После вычитания всех вкладов ошибка округления не остается.
+	assert.True(t, sum.Sub(b).Sub(a).IsZero())
+	assert.True(t, sum.Sub(a).Sub(b).IsZero())
+}
+
+func TestAggregate_Mean(t *testing.T) {
+	now := time.Now()
+	assert.Zero(t, Aggregate{}.Mean())
+
+	// Оценки взвешиваются по уверенности: (5*0.9 + 1*0.3) / 1.2.
+	a := Of(done("positive", 0.9), now).Add(Of(done("negative", 0.3), now))
+	assert.Equal(t, 4.0, a.Mean())
+}
+
+func TestCompute(t *testing.T) {
+	now := time.Now()
+
+	// Без отзывов рейтинг равен априорной оценке.
+	assert.Equal(t, PriorMean, Compute("p", Aggregate{}, now).Score)
+
+	// Один положительный отзыв лишь немного сдвигает рейтинг.
+	one := Compute("p", Of(done("positive", 1), now), now)
+	assert.InDelta(t, (PriorWeight*PriorMean+5)/(PriorWeight+1), one.Score, 0.01)
+
+	// Много положительных отзывов приближают рейтинг к 5.
+	var many Aggregate
+	for range 100 {
+		many = many.Add(Of(done("positive", 1), now))
+	}
+	assert.Greater(t, Compute("p", many, now).Score, 4.8)
+
+	// Свежие отзывы важнее старых.
+	agg := Of(done("negative", 1), now.Add(-3*HalfLife)).
+		Add(Of(done("positive", 1), now))
+	r := Compute("p", agg, now)
+	assert.Greater(t, r.Score, PriorMean)
+	assert.Equal(t, models.Distribution{Positive: 1, Negative: 1}, r.Distribution)
+
+	// Рейтинг не зависит от момента, в который агрегат был накоплен.
+	later := now.Add(HalfLife)
+	assert.InDelta(t,
+		Compute("p", Of(done("positive", 1), now), later).Score,
+		Compute("p", Of(done("positive", 0.5), later), later).Score,
+		0.01)
+}
+
+func TestAspects(t *testing.T) {
+	now := time.Now()
+
+	s := done("neutral", 0.8)
+	s.Aspects = []models.AspectSentiment{
+		{Aspect: "price", Label: "negative", Confidence: 0.9},
+		{Aspect: "quality", Label: "positive", Confidence: 0.7},
+	}
+	assert.Nil(t, OfAspects(models.Sentiment{Status: models.SentimentPending, Aspects: s.Aspects}, now))
+
+	aspects := OfAspects(s, now)
+	require.Len(t, aspects, 2)
+	assert.Equal(t, 1, aspects["price"].Negative)
+
+	// Аспекты разных отзывов суммируются независимо.
+	other := done("positive", 1)
+	other.Aspects = []models.AspectSentiment{{Aspect: "price", Label: "positive", Confidence: 1}}
+	for aspect, a := range OfAspects(other, now) {
+		aspects[aspect] = aspects[aspect].Add(a)
+	}
+
+	ratings := ComputeGroups(aspects, now)
+	assert.Equal(t, 2, ratings["price"].Reviews)
+	assert.Equal(t, models.Distribution{Positive: 1, Negative: 1}, ratings["price"].Distribution)
+	assert.Greater(t, ratings["quality"].Score, PriorMean)
+	assert.Nil(t, ComputeGroups(nil, now))
+}
+
+func TestDiffGroups(t *testing.T) {
+	now := time.Now()
+	from := map[string]Aggregate{
+		"price":   Of(done("negative", 0.9), now),
+		"quality": Of(done("positive", 0.7), now),
+	}
+	to := map[string]Aggregate{
+		"price":    Of(done("positive", 0.9), now),
+		"quality":  Of(done("positive", 0.7), now),
+		"delivery": Of(done("neutral", 1), now),
+	}
+
+	// Неизменившиеся группы опускаются.
+	d := DiffGroups(from, to)
+	require.Len(t, d, 2)
+	assert.Equal(t, 1, d["price"].Positive)
+	assert.Equal(t, -1, d["price"].Negative)
+	assert.Equal(t, 1, d["delivery"].Neutral)
+
+	assert.Empty(t, DiffGroups(to, to))
+	assert.Equal(t, -1, DiffGroups(to, nil)["delivery"].Neutral)
+}
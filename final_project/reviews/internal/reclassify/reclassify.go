@@ -0,0 +1,153 @@
+// Package reclassify реализует повторную классификацию уже размеченных отзывов,
+// например после смены модели или шаблона запроса.
+//
+// Запуск (Run) обходит отзывы, подходящие под фильтр, в порядке
+// (created_at, id) страницами, классифицирует страницу и сохраняет результаты
+// одним пакетом вместе с курсором запуска. Прерванный запуск продолжается
+// с сохраненного курсора.
+//
+// Запуск выполняет один экземпляр сервиса: перед обработкой запуск
+// захватывается в хранилище на время аренды, которая продлевается
+// с каждым сохраненным пакетом.
+package reclassify
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-masters/final_project/reviews/internal/language"
+	"go-masters/final_project/reviews/internal/models"
+)
+
+// Состояния запуска.
+const (
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+var (
+	// ErrInvalidFilter - некорректный фильтр запуска.
+	ErrInvalidFilter = errors.New("некорректный фильтр")
+	// ErrConflict - запуск уже завершен или выполняется.
+	ErrConflict = errors.New("запуск нельзя продолжить")
+)
+
+// Filter - отбор отзывов для повторной классификации.
+// Пустые поля не ограничивают выборку. Отзывы, ожидающие
+// классификации в очереди, не выбираются.
+type Filter struct {
+	ProductID string `json:"product_id,omitempty"`
+	// Интервал времени создания отзыва [From, To).
+	From time.Time `json:"from,omitzero"`
+	To   time.Time `json:"to,omitzero"`
+	// Модель и версия запроса, которыми отзыв был классифицирован.
+	Model         string `json:"model,omitempty"`
+	PromptVersion string `json:"prompt_version,omitempty"`
+	// Код языка отзыва, например для повторной классификации отзывов
+	// на языке, для которого появился отдельный запрос.
+	Language string `json:"language,omitempty"`
+}
+
+// Validate проверяет фильтр.
+func (f Filter) Validate() error {
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return errors.Join(ErrInvalidFilter, errors.New("начало интервала должно быть раньше конца"))
+	}
+	if f.Language != "" && !language.Valid(f.Language) {
+		return errors.Join(ErrInvalidFilter, errors.New("некорректный код языка"))
+	}
+	return nil
+}
+
+// Match сообщает, подходит ли отзыв под фильтр.
+func (f Filter) Match(r models.Review) bool {
+	switch {
+	case r.Sentiment.Status == models.SentimentPending:
+		return false
+	case f.ProductID != "" && r.ProductID != f.ProductID:
+		return false
+	case !f.From.IsZero() && r.CreatedAt.Before(f.From):
+		return false
+	case !f.To.IsZero() && !r.CreatedAt.Before(f.To):
+		return false
+	case f.Model != "" && r.Sentiment.Model != f.Model:
+		return false
+	case f.PromptVersion != "" && r.Sentiment.PromptVersion != f.PromptVersion:
+		return false
+	case f.Language != "" && r.Language != f.Language:
+		return false
+	}
+	return true
+}
+
+// Cursor - позиция последнего обработанного отзыва.
+type Cursor struct {
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	ReviewID  string    `json:"review_id,omitempty"`
+}
+
+// After сообщает, следует ли отзыв за курсором.
+func (c Cursor) After(r models.Review) bool {
+	if c.ReviewID == "" {
+		return true
+	}
+	if !r.CreatedAt.Equal(c.CreatedAt) {
+		return r.CreatedAt.After(c.CreatedAt)
+	}
+	return r.ID > c.ReviewID
+}
+
+// Run - запуск повторной классификации.
+type Run struct {
+	ID     string `json:"id"`
+	Filter Filter `json:"filter"`
+	Cursor Cursor `json:"cursor"`
+	// Число обработанных отзывов и отзывов, которые не удалось классифицировать.
+	Processed int       `json:"processed"`
+	Failed    int       `json:"failed"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Окончание аренды запуска экземпляром сервиса, который его выполняет.
+	LeasedUntil time.Time `json:"leased_until,omitzero"`
+	// Token - идентификатор захвата запуска; заполняется ClaimRun.
+	Token string `json:"-"`
+}
+
+// Leased сообщает, выполняется ли запуск сейчас.
+func (r Run) Leased(now time.Time) bool {
+	return r.LeasedUntil.After(now)
+}
+
+// Update - новое настроение отзыва. Review - отзыв в том виде, в каком он
+// был прочитан: если с тех пор отзыв изменился, обновление пропускается.
+type Update struct {
+	Review    models.Review
+	Sentiment models.Sentiment
+}
+
+// Store - хранилище запусков и отзывов.
+type Store interface {
+	CreateRun(ctx context.Context, f Filter) (Run, error)
+	GetRun(ctx context.Context, id string) (Run, error)
+	// ListRuns возвращает запуски с указанным состоянием.
+	ListRuns(ctx context.Context, status string) ([]Run, error)
+	// NextReviews возвращает до limit отзывов, подходящих под фильтр,
+	// следующих за курсором.
+	NextReviews(ctx context.Context, f Filter, after Cursor, limit int) ([]models.Review, error)
+	// ClaimRun захватывает выполняющийся запуск на время lease и возвращает
+	// его с новым токеном. Если запуск завершен или захвачен другим
+	// экземпляром и аренда не истекла, возвращает ErrConflict.
+	ClaimRun(ctx context.Context, id string, lease time.Duration) (Run, error)
+	// ReleaseRun освобождает захват запуска с токеном run.Token.
+	ReleaseRun(ctx context.Context, run Run) error
+	// SaveBatch атомарно применяет обновления, сохраняет курсор и счетчики
+	// запуска и продлевает его аренду на lease. Если запуск захвачен заново
+	// (токен run.Token устарел), ничего не сохраняет и возвращает ErrConflict.
+	SaveBatch(ctx context.Context, run Run, lease time.Duration, updates []Update) error
+	// SetRunStatus меняет состояние запуска; reason - причина ошибки.
+	SetRunStatus(ctx context.Context, id, status, reason string) error
+}
@@ -0,0 +1,223 @@
+package reclassify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/sentiment"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Параметры по умолчанию.
+const (
+	DefaultBatchSize = 100
+	DefaultWorkers   = 4
+	DefaultLease     = 30 * time.Minute
+)
+
+// Runner выполняет запуски повторной классификации.
+type Runner struct {
+	store      Store
+	classifier sentiment.Classifier
+
+	BatchSize int
+	Workers   int
+	// Время аренды запуска; должно превышать время классификации пакета.
+	Lease time.Duration
+}
+
+func NewRunner(store Store, classifier sentiment.Classifier) *Runner {
+	return &Runner{
+		store:      store,
+		classifier: classifier,
+		BatchSize:  DefaultBatchSize,
+		Workers:    DefaultWorkers,
+		Lease:      DefaultLease,
+	}
+}
+
+// Get возвращает запуск.
+func (rn *Runner) Get(ctx context.Context, id string) (Run, error) {
+	return rn.store.GetRun(ctx, id)
+}
+
+// Start создает запуск для фильтра f. Сам запуск выполняется методом Process.
+func (rn *Runner) Start(ctx context.Context, f Filter) (Run, error) {
+	if err := f.Validate(); err != nil {
+		return Run{}, err
+	}
+	return rn.store.CreateRun(ctx, f)
+}
+
+// Resume подготавливает к продолжению запуск id, в том числе завершившийся
+// ошибкой. Сам запуск выполняется методом Process.
+func (rn *Runner) Resume(ctx context.Context, id string) (Run, error) {
+	run, err := rn.store.GetRun(ctx, id)
+	if err != nil {
+		return Run{}, err
+	}
+	switch {
+	case run.Status == StatusDone:
+		return run, fmt.Errorf("%w: запуск %s уже завершен", ErrConflict, run.ID)
+	case run.Leased(time.Now()):
+		return run, fmt.Errorf("%w: запуск %s уже выполняется", ErrConflict, run.ID)
+	case run.Status == StatusFailed:
+		if err := rn.store.SetRunStatus(ctx, run.ID, StatusRunning, ""); err != nil {
+			return run, err
+		}
+		run.Status, run.Error = StatusRunning, ""
+	}
+	return run, nil
+}
+
+// ResumeAll продолжает все незавершенные запуски, например после перезапуска
+// сервиса.
+func (rn *Runner) ResumeAll(ctx context.Context) {
+	runs, err := rn.store.ListRuns(ctx, StatusRunning)
+	if err != nil {
+		log.Error().Err(err).Msg("Ошибка получения незавершенных запусков переклассификации")
+		return
+	}
+	for _, run := range runs {
+		log.Info().Str("run_id", run.ID).Int("processed", run.Processed).Msg("Продолжение переклассификации")
+		_, err := rn.Process(ctx, run)
+		if errors.Is(err, ErrConflict) {
+			log.Info().Str("run_id", run.ID).Msg("Запуск выполняется другим экземпляром")
+			continue
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Str("run_id", run.ID).Msg("Ошибка переклассификации")
+		}
+	}
+}
+
+// Process захватывает запуск и выполняет его с сохраненного курсора до конца
+// выборки или отмены контекста. При отмене запуск остается в состоянии
+// running, освобождается и может быть продолжен.
+func (rn *Runner) Process(ctx context.Context, run Run) (Run, error) {
+	if run.Status != StatusRunning {
+		return run, fmt.Errorf("%w: запуск %s уже завершен", ErrConflict, run.ID)
+	}
+	claimed, err := rn.store.ClaimRun(ctx, run.ID, rn.Lease)
+	if errors.Is(err, ErrConflict) {
+		return run, fmt.Errorf("%w: запуск %s уже выполняется", ErrConflict, run.ID)
+	}
+	if err != nil {
+		return run, err
+	}
+	// Курсор и счетчики берутся из хранилища: переданный запуск мог устареть.
+	run = claimed
+	defer func() {
+		if err := rn.store.ReleaseRun(context.WithoutCancel(ctx), run); err != nil {
+			log.Error().Err(err).Str("run_id", run.ID).Msg("Ошибка освобождения запуска")
+		}
+	}()
+
+	for {
+		reviews, err := rn.store.NextReviews(ctx, run.Filter, run.Cursor, rn.BatchSize)
+		if err != nil {
+			return run, rn.fail(ctx, run, err)
+		}
+		if len(reviews) == 0 {
+			break
+		}
+
+		updates, failed := rn.classify(ctx, reviews)
+		if ctx.Err() != nil {
+			return run, ctx.Err()
+		}
+
+		last := reviews[len(reviews)-1]
+		run.Cursor = Cursor{CreatedAt: last.CreatedAt, ReviewID: last.ID}
+		run.Processed += len(reviews)
+		run.Failed += failed
+		err = rn.store.SaveBatch(ctx, run, rn.Lease, updates)
+		// Аренда истекла, и запуск выполняет другой экземпляр.
+		if errors.Is(err, ErrConflict) {
+			return run, fmt.Errorf("%w: запуск %s захвачен другим экземпляром", ErrConflict, run.ID)
+		}
+		if err != nil {
+			return run, rn.fail(ctx, run, err)
+		}
+	}
+
+	run.Status = StatusDone
+	log.Info().
+		Str("run_id", run.ID).
+		Int("processed", run.Processed).
+		Int("failed", run.Failed).
+		Msg("Переклассификация завершена")
+	return run, rn.store.SetRunStatus(ctx, run.ID, StatusDone, "")
+}
+
+// fail завершает запуск с ошибкой, если она не вызвана отменой контекста.
+func (rn *Runner) fail(ctx context.Context, run Run, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if ferr := rn.store.SetRunStatus(ctx, run.ID, StatusFailed, err.Error()); ferr != nil {
+		log.Error().Err(ferr).Str("run_id", run.ID).Msg("Ошибка сохранения состояния запуска")
+	}
+	return err
+}
+
+// classify классифицирует страницу отзывов пулом воркеров. Отзывы, которые
+// не удалось классифицировать, сохраняют прежнее настроение.
+func (rn *Runner) classify(ctx context.Context, reviews []models.Review) ([]Update, int) {
+	tasks := make(chan models.Review)
+	results := make(chan Update)
+
+	var wg sync.WaitGroup
+	for range min(rn.Workers, len(reviews)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for r := range tasks {
+				res, err := rn.classifier.Classify(sentiment.WithLanguage(ctx, r.Language), r.Text)
+				if err == nil {
+					err = res.Validate()
+				}
+				if err != nil {
+					log.Warn().Err(err).Str("review_id", r.ID).Msg("Не удалось переклассифицировать отзыв")
+					results <- Update{Review: r}
+					continue
+				}
+				results <- Update{Review: r, Sentiment: res.Sentiment()}
+			}
+		}()
+	}
+
+	var (
+		updates []Update
+		failed  int
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for u := range results {
+			if u.Sentiment.Status == "" {
+				failed++
+				continue
+			}
+			updates = append(updates, u)
+		}
+	}()
+
+	for _, r := range reviews {
+		tasks <- r
+	}
+	close(tasks)
+
+	wg.Wait()
+	close(results)
+	<-done
+
+	return updates, failed
+}
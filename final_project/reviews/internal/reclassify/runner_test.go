@@ -0,0 +1,197 @@
+package reclassify_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/reclassify"
+	"go-masters/final_project/reviews/internal/sentiment"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// classifierFunc позволяет использовать функцию как классификатор.
+type classifierFunc func(ctx context.Context, text string) (sentiment.Result, error)
+
+func (f classifierFunc) Classify(ctx context.Context, text string) (sentiment.Result, error) {
+	return f(ctx, text)
+}
+
+// model возвращает классификатор, помечающий все отзывы положительными
+// от имени модели name.
+func model(name string) classifierFunc {
+	return func(context.Context, string) (sentiment.Result, error) {
+		return sentiment.Result{Label: sentiment.Positive, Confidence: 0.9, Model: name, PromptVersion: "1"}, nil
+	}
+}
+
+// setup создает n отзывов, классифицированных моделью old.
+func setup(t *testing.T, n int) (*memdb.MemDB, string) {
+	t.Helper()
+	ctx := context.Background()
+
+	m := memdb.New()
+	p, err := m.AddProduct(ctx, models.Product{Name: "Чайник"})
+	require.NoError(t, err)
+	for range n {
+		_, err := m.AddReview(ctx, models.Review{ProductID: p.ID, Author: "A", Text: "Текст", Rating: 3})
+		require.NoError(t, err)
+	}
+	_, err = jobs.NewPool(m, model("old")).RunOnce(ctx)
+	require.NoError(t, err)
+
+	return m, p.ID
+}
+
+func TestRunner_Process(t *testing.T) {
+	ctx := context.Background()
+	m, pid := setup(t, 5)
+
+	rn := reclassify.NewRunner(m, model("new"))
+	rn.BatchSize = 2
+
+	// Фильтр не совпадает ни с одним отзывом.
+	run, err := rn.Start(ctx, reclassify.Filter{Model: "unknown"})
+	require.NoError(t, err)
+	run, err = rn.Process(ctx, run)
+	require.NoError(t, err)
+	assert.Equal(t, reclassify.StatusDone, run.Status)
+	assert.Zero(t, run.Processed)
+
+	run, err = rn.Start(ctx, reclassify.Filter{ProductID: pid, Model: "old"})
+	require.NoError(t, err)
+	run, err = rn.Process(ctx, run)
+	require.NoError(t, err)
+	assert.Equal(t, 5, run.Processed)
+
+	reviews, err := m.ListReviews(ctx, pid, "")
+	require.NoError(t, err)
+	for _, r := range reviews {
+		assert.Equal(t, "new", r.Sentiment.Model)
+	}
+
+	// Агрегат рейтинга не задваивается.
+	agg, err := m.ProductRating(ctx, pid)
+	require.NoError(t, err)
+	assert.Equal(t, 5, agg.Count())
+
+	stored, err := m.GetRun(ctx, run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, reclassify.StatusDone, stored.Status)
+}
+
+func TestRunner_Resume(t *testing.T) {
+	m, pid := setup(t, 6)
+
+	// Первый запуск прерывается на третьем отзыве.
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	interrupted := classifierFunc(func(ctx context.Context, text string) (sentiment.Result, error) {
+		if calls.Add(1) == 3 {
+			cancel()
+		}
+		return model("new")(ctx, text)
+	})
+	rn := reclassify.NewRunner(m, interrupted)
+	rn.BatchSize = 2
+	rn.Workers = 1
+
+	run, err := rn.Start(ctx, reclassify.Filter{Model: "old"})
+	require.NoError(t, err)
+	_, err = rn.Process(ctx, run)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	stored, err := m.GetRun(context.Background(), run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, reclassify.StatusRunning, stored.Status)
+	assert.Equal(t, 2, stored.Processed)
+
+	// Продолжение обрабатывает только оставшиеся отзывы.
+	calls.Store(0)
+	counting := classifierFunc(func(ctx context.Context, text string) (sentiment.Result, error) {
+		calls.Add(1)
+		return model("new")(ctx, text)
+	})
+	rn = reclassify.NewRunner(m, counting)
+	rn.BatchSize = 2
+
+	run, err = rn.Resume(context.Background(), run.ID)
+	require.NoError(t, err)
+	run, err = rn.Process(context.Background(), run)
+	require.NoError(t, err)
+	assert.Equal(t, 6, run.Processed)
+	assert.EqualValues(t, 4, calls.Load())
+
+	reviews, err := m.ListReviews(context.Background(), pid, "")
+	require.NoError(t, err)
+	for _, r := range reviews {
+		assert.Equal(t, "new", r.Sentiment.Model)
+	}
+}
+
+func TestRunner_SkipsChangedReviews(t *testing.T) {
+	ctx := context.Background()
+	m, pid := setup(t, 1)
+	reviews, err := m.ListReviews(ctx, pid, "")
+	require.NoError(t, err)
+	r := reviews[0]
+
+	// Отзыв меняется во время переклассификации.
+	editing := classifierFunc(func(ctx context.Context, text string) (sentiment.Result, error) {
+		seen := r.Text
+		r.Text = "Новый текст"
+		_, err := m.UpdateReview(ctx, r, seen)
+		require.NoError(t, err)
+		return model("new")(ctx, text)
+	})
+	rn := reclassify.NewRunner(m, editing)
+	run, err := rn.Start(ctx, reclassify.Filter{})
+	require.NoError(t, err)
+	_, err = rn.Process(ctx, run)
+	require.NoError(t, err)
+
+	got, err := m.GetReview(ctx, pid, r.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SentimentPending, got.Sentiment.Status)
+}
+
+func TestRunner_SingleInstance(t *testing.T) {
+	ctx := context.Background()
+	m, _ := setup(t, 4)
+
+	var calls atomic.Int32
+	counting := classifierFunc(func(ctx context.Context, text string) (sentiment.Result, error) {
+		calls.Add(1)
+		return model("new")(ctx, text)
+	})
+	rn := reclassify.NewRunner(m, counting)
+	run, err := rn.Start(ctx, reclassify.Filter{Model: "old"})
+	require.NoError(t, err)
+
+	// Запуск выполняет другой экземпляр сервиса.
+	other, err := m.ClaimRun(ctx, run.ID, time.Hour)
+	require.NoError(t, err)
+	_, err = rn.Process(ctx, run)
+	assert.ErrorIs(t, err, reclassify.ErrConflict)
+	_, err = rn.Resume(ctx, run.ID)
+	assert.ErrorIs(t, err, reclassify.ErrConflict)
+	rn.ResumeAll(ctx)
+	assert.Zero(t, calls.Load())
+
+	// После истечения аренды запуск захватывается заново,
+	// а прежний экземпляр больше не сохраняет результаты.
+	require.NoError(t, m.ReleaseRun(ctx, other))
+	other, err = m.ClaimRun(ctx, run.ID, 0)
+	require.NoError(t, err)
+	run, err = rn.Process(ctx, run)
+	require.NoError(t, err)
+	assert.Equal(t, 4, run.Processed)
+	assert.EqualValues(t, 4, calls.Load())
+	assert.ErrorIs(t, m.SaveBatch(ctx, other, time.Hour, nil), reclassify.ErrConflict)
+}
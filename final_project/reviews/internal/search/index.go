@@ -0,0 +1,102 @@
+package search
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// Hit - найденный документ.
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// Index - инвертированный индекс основ слов документов.
+// Index не потокобезопасен.
+type Index struct {
+	// postings - число вхождений основы в документы по идентификатору.
+	postings map[string]map[string]int
+	// docs - различные основы документа.
+	docs map[string][]string
+}
+
+// NewIndex возвращает пустой индекс.
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[string]int),
+		docs:     make(map[string][]string),
+	}
+}
+
+// Add индексирует текст документа, заменяя прежний текст документа id.
+func (ix *Index) Add(id, text string) {
+	ix.Remove(id)
+	var stems []string
+	for _, t := range Tokenize(text) {
+		if t.Stem == "" {
+			continue
+		}
+		p, ok := ix.postings[t.Stem]
+		if !ok {
+			p = make(map[string]int)
+			ix.postings[t.Stem] = p
+		}
+		if p[id] == 0 {
+			stems = append(stems, t.Stem)
+		}
+		p[id]++
+	}
+	ix.docs[id] = stems
+}
+
+// Remove удаляет документ из индекса.
+func (ix *Index) Remove(id string) {
+	for _, stem := range ix.docs[id] {
+		p := ix.postings[stem]
+		delete(p, id)
+		if len(p) == 0 {
+			delete(ix.postings, stem)
+		}
+	}
+	delete(ix.docs, id)
+}
+
+// Search возвращает документы, содержащие все основы terms, по убыванию
+// релевантности tf-idf. Пустой запрос ничего не находит.
+func (ix *Index) Search(terms []string) []Hit {
+	if len(terms) == 0 {
+		return nil
+	}
+	// Перебираются документы самой редкой основы.
+	rarest := ix.postings[terms[0]]
+	for _, t := range terms[1:] {
+		if len(ix.postings[t]) < len(rarest) {
+			rarest = ix.postings[t]
+		}
+	}
+
+	n := float64(len(ix.docs))
+	var hits []Hit
+	for id := range rarest {
+		score := 0.0
+		for _, t := range terms {
+			p := ix.postings[t]
+			tf := p[id]
+			if tf == 0 {
+				score = -1
+				break
+			}
+			idf := math.Log(1 + n/float64(len(p)))
+			score += (1 + math.Log(float64(tf))) * idf
+		}
+		if score < 0 {
+			continue
+		}
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	slices.SortFunc(hits, func(a, b Hit) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.ID, b.ID))
+	})
+	return hits
+}
@@ -0,0 +1,197 @@
+// Package search реализует полнотекстовый поиск по отзывам для хранилища
+// в памяти и подсветку найденных слов.
+//
+// В Postgres отзывы ищутся по колонке tsvector с конфигурациями russian
+// и english (см. миграцию review_search). Index - упрощенная замена для
+// хранилища в памяти: инвертированный индекс основ слов, которые получаются
+// отбрасыванием типичных окончаний русских и английских слов. Запрос
+// находит отзывы, содержащие все его слова, кроме служебных.
+package search
+
+import (
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Разметка подсветки. Фрагменты подсветки - HTML: текст отзыва
+// экранирован, найденные слова обрамлены тегами mark.
+const (
+	StartSel = "<mark>"
+	StopSel  = "</mark>"
+	// FragmentDelimiter разделяет фрагменты текста.
+	FragmentDelimiter = " … "
+	// MaxFragments - наибольшее число фрагментов в подсветке.
+	MaxFragments = 3
+	// FragmentWords - число слов фрагмента до и после найденного слова.
+	FragmentWords = 5
+)
+
+// minStem - минимальная длина основы в символах.
+const minStem = 3
+
+// suffixes - окончания, отбрасываемые при получении основы,
+// от длинных к коротким.
+var suffixes = func() []string {
+	s := []string{
+		// Русские окончания и суффиксы форм слов.
+		"иями", "ями", "ами", "ого", "его", "ому", "ему", "ыми", "ими", "ешь", "ишь",
+		"ить", "ать", "ять", "еть", "уть", "ая", "яя", "ое", "ее", "ые", "ие", "ой",
+		"ей", "ий", "ый", "ом", "ем", "ам", "ям", "ах", "ях", "ую", "юю", "ов", "ев",
+		"ет", "ит", "ут", "ют", "ят",
+		"а", "я", "о", "е", "ы", "и", "у", "ю", "ь", "й",
+		// Английские окончания.
+		"ing", "ed", "ly", "s",
+	}
+	slices.SortStableFunc(s, func(a, b string) int {
+		return utf8.RuneCountInString(b) - utf8.RuneCountInString(a)
+	})
+	return s
+}()
+
+// stopwords - служебные слова, не участвующие в поиске.
+var stopwords = map[string]bool{
+	"и": true, "в": true, "во": true, "не": true, "на": true, "с": true, "со": true,
+	"а": true, "но": true, "что": true, "как": true, "к": true, "по": true, "из": true,
+	"у": true, "за": true, "от": true, "о": true, "об": true, "же": true, "бы": true,
+	"ли": true, "до": true, "для": true, "это": true, "то": true, "очень": true,
+	"the": true, "a": true, "an": true, "and": true, "or": true, "is": true, "are": true,
+	"was": true, "it": true, "to": true, "of": true, "in": true, "on": true, "for": true,
+	"with": true, "this": true, "that": true, "very": true, "not": true, "but": true,
+}
+
+// Token - слово текста.
+type Token struct {
+	// Основа слова; пустая у служебных слов.
+	Stem string
+	// Границы слова в тексте в байтах.
+	Start, End int
+}
+
+// Tokenize разбивает текст на слова и вычисляет их основы.
+func Tokenize(text string) []Token {
+	var (
+		toks  []Token
+		start = -1
+	)
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		toks = append(toks, Token{Stem: Stem(text[start:end]), Start: start, End: end})
+		start = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+	return toks
+}
+
+// Stem возвращает основу слова или пустую строку для служебного слова.
+func Stem(word string) string {
+	w := strings.ReplaceAll(strings.ToLower(word), "ё", "е")
+	if stopwords[w] {
+		return ""
+	}
+	// Возвратные глаголы: "сломался" и "сломал" имеют общую основу.
+	for _, s := range []string{"ся", "сь"} {
+		if b, ok := strings.CutSuffix(w, s); ok && utf8.RuneCountInString(b) >= minStem {
+			w = b
+			break
+		}
+	}
+	for _, s := range suffixes {
+		if b, ok := strings.CutSuffix(w, s); ok && utf8.RuneCountInString(b) >= minStem {
+			return b
+		}
+	}
+	return w
+}
+
+// Terms возвращает различные основы слов запроса без служебных слов.
+func Terms(query string) []string {
+	var terms []string
+	for _, t := range Tokenize(query) {
+		if t.Stem != "" && !slices.Contains(terms, t.Stem) {
+			terms = append(terms, t.Stem)
+		}
+	}
+	return terms
+}
+
+// Highlight возвращает до MaxFragments фрагментов текста вокруг слов
+// с основами terms, выделенных StartSel и StopSel. Если таких слов нет,
+// возвращается начало текста.
+func Highlight(text string, terms []string) string {
+	toks := Tokenize(text)
+	if len(toks) == 0 {
+		return html.EscapeString(text)
+	}
+
+	// Диапазоны слов фрагментов; соседние диапазоны объединяются.
+	type span struct{ from, to int }
+	var spans []span
+	for i, t := range toks {
+		if t.Stem == "" || !slices.Contains(terms, t.Stem) {
+			continue
+		}
+		s := span{max(i-FragmentWords, 0), min(i+FragmentWords, len(toks)-1)}
+		if n := len(spans); n > 0 && s.from <= spans[n-1].to+1 {
+			spans[n-1].to = s.to
+			continue
+		}
+		if len(spans) == MaxFragments {
+			break
+		}
+		spans = append(spans, s)
+	}
+	if len(spans) == 0 {
+		spans = append(spans, span{0, min(2*FragmentWords, len(toks)-1)})
+	}
+
+	var b strings.Builder
+	for i, s := range spans {
+		if i > 0 {
+			b.WriteString(FragmentDelimiter)
+		}
+		// Фрагменты на краях текста включают знаки до первого
+		// и после последнего слова.
+		pos, end := toks[s.from].Start, toks[s.to].End
+		if s.from == 0 {
+			pos = 0
+		}
+		if s.to == len(toks)-1 {
+			end = len(text)
+		}
+		for _, t := range toks[s.from : s.to+1] {
+			if t.Stem == "" || !slices.Contains(terms, t.Stem) {
+				continue
+			}
+			b.WriteString(html.EscapeString(text[pos:t.Start]))
+			b.WriteString(StartSel)
+			b.WriteString(html.EscapeString(text[t.Start:t.End]))
+			b.WriteString(StopSel)
+			pos = t.End
+		}
+		b.WriteString(html.EscapeString(text[pos:end]))
+	}
+	return b.String()
+}
+
+// EscapeHeadline экранирует подсветку, размеченную StartSel и StopSel
+// поверх неэкранированного текста (ts_headline в Postgres), сохраняя
+// разметку.
+func EscapeHeadline(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, html.EscapeString(StartSel), StartSel)
+	return strings.ReplaceAll(s, html.EscapeString(StopSel), StopSel)
+}
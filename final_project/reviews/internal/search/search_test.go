@@ -0,0 +1,97 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"чайник", "Чайники", "чайника", "чайниками"}, "чайник"},
+		{[]string{"крышка", "крышки", "крышкой"}, "крышк"},
+		{[]string{"сломался", "сломалась", "сломал"}, "сломал"},
+		{[]string{"ёлка", "елки"}, "елк"},
+		{[]string{"kettle", "kettles"}, "kettle"},
+		{[]string{"working", "worked", "works"}, "work"},
+		{[]string{"и", "не", "The"}, ""},
+	}
+	for _, tt := range tests {
+		for _, w := range tt.words {
+			assert.Equal(t, tt.want, Stem(w), w)
+		}
+	}
+}
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"крышк", "чайник"}, Terms("Крышка и крышки чайника!"))
+	assert.Empty(t, Terms("и не"))
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name, text, query, want string
+	}{
+		{
+			name:  "escape",
+			text:  "Крышка <b>скрипит</b>",
+			query: "крышки",
+			want:  "<mark>Крышка</mark> &lt;b&gt;скрипит&lt;/b&gt;",
+		},
+		{
+			name:  "fragments",
+			text:  "Крышка плотная. Один два три четыре пять шесть семь восемь девять десять. Вторая крышка тоже",
+			query: "крышка",
+			want:  "<mark>Крышка</mark> плотная. Один два три четыре … семь восемь девять десять. Вторая <mark>крышка</mark> тоже",
+		},
+		{
+			name:  "no match",
+			text:  "Один два три четыре пять шесть семь восемь девять десять одиннадцать двенадцать",
+			query: "крышка",
+			want:  "Один два три четыре пять шесть семь восемь девять десять одиннадцать",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Highlight(tt.text, Terms(tt.query)))
+		})
+	}
+}
+
+func TestEscapeHeadline(t *testing.T) {
+	assert.Equal(t, "<mark>Крышка</mark> &lt;b&gt;", EscapeHeadline("<mark>Крышка</mark> <b>"))
+}
+
+func TestIndex(t *testing.T) {
+	ix := NewIndex()
+	ix.Add("1", "Чайник шумит, чайник течет")
+	ix.Add("2", "Хороший чайник, тихий")
+	ix.Add("3", "Утюг тихий")
+
+	ids := func(hits []Hit) []string {
+		var res []string
+		for _, h := range hits {
+			res = append(res, h.ID)
+		}
+		return res
+	}
+
+	// Чаще встречающееся слово повышает релевантность
+	assert.Equal(t, []string{"1", "2"}, ids(ix.Search(Terms("чайники"))))
+	assert.Equal(t, []string{"2"}, ids(ix.Search(Terms("тихий чайник"))))
+	assert.Empty(t, ix.Search(Terms("и")))
+	assert.Empty(t, ix.Search(Terms("чайник пылесос")))
+
+	ix.Add("1", "Утюг тихий")
+	assert.Equal(t, []string{"2"}, ids(ix.Search(Terms("чайник"))))
+	require.Len(t, ix.Search(Terms("утюг")), 2)
+
+	ix.Remove("3")
+	ix.Remove("1")
+	assert.Empty(t, ix.Search(Terms("утюг")))
+	assert.Len(t, ix.postings, 3)
+}
@@ -0,0 +1,34 @@
+package sentiment
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// fallback - классификатор, переключающийся на запасной при ошибке основного.
+type fallback struct {
+	primary  Classifier
+	fallback Classifier
+}
+
+// WithFallback возвращает классификатор, который использует primary,
+// а при его ошибке или истечении времени ожидания - secondary.
+func WithFallback(primary, secondary Classifier) Classifier {
+	return &fallback{primary: primary, fallback: secondary}
+}
+
+func (f *fallback) Classify(ctx context.Context, text string) (Result, error) {
+	res, err := f.primary.Classify(ctx, text)
+	if err == nil {
+		return res, nil
+	}
+	// Если отменен сам запрос, а не истекло время основного классификатора,
+	// запасной вызывать бессмысленно.
+	if ctx.Err() != nil {
+		return Result{}, ctx.Err()
+	}
+
+	log.Warn().Err(err).Msg("Основной классификатор недоступен, используется запасной")
+	return f.fallback.Classify(ctx, text)
+}
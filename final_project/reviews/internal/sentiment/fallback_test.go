@@ -0,0 +1,41 @@
+package sentiment
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// classifierFunc позволяет использовать функцию как классификатор.
+type classifierFunc func(ctx context.Context, text string) (Result, error)
+
+func (f classifierFunc) Classify(ctx context.Context, text string) (Result, error) {
+	return f(ctx, text)
+}
+
+func TestWithFallback(t *testing.T) {
+	good := Result{Label: Positive, Confidence: 0.9}
+	spare := Result{Label: Neutral, Confidence: 0.5}
+
+	ok := classifierFunc(func(context.Context, string) (Result, error) { return good, nil })
+	failing := classifierFunc(func(context.Context, string) (Result, error) {
+		return Result{}, errors.New("connection refused")
+	})
+	secondary := classifierFunc(func(context.Context, string) (Result, error) { return spare, nil })
+
+	res, err := WithFallback(ok, secondary).Classify(context.Background(), "текст")
+	require.NoError(t, err)
+	assert.Equal(t, good, res)
+
+	res, err = WithFallback(failing, secondary).Classify(context.Background(), "текст")
+	require.NoError(t, err)
+	assert.Equal(t, spare, res)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = WithFallback(failing, secondary).Classify(ctx, "текст")
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,50 @@
+package sentiment
+
+import (
+	"context"
+
+	"go-masters/final_project/reviews/internal/language"
+)
+
+type languageKey struct{}
+
+// WithLanguage возвращает контекст с кодом языка классифицируемого текста,
+// определенным при сохранении отзыва. Пустой код не меняет контекст.
+func WithLanguage(ctx context.Context, code string) context.Context {
+	if code == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, languageKey{}, code)
+}
+
+// Language возвращает код языка текста из контекста или, если он
+// не задан, определяет его по тексту.
+func Language(ctx context.Context, text string) string {
+	if code, ok := ctx.Value(languageKey{}).(string); ok {
+		return code
+	}
+	return language.Detect(text)
+}
+
+// byLanguage - классификатор, выбирающий классификатор по языку текста.
+type byLanguage struct {
+	def    Classifier
+	routes map[string]Classifier
+}
+
+// ByLanguage возвращает классификатор, который классифицирует текст
+// классификатором routes[код языка], а тексты на остальных языках - def.
+// Язык берется из контекста (см. WithLanguage).
+func ByLanguage(def Classifier, routes map[string]Classifier) Classifier {
+	if len(routes) == 0 {
+		return def
+	}
+	return &byLanguage{def: def, routes: routes}
+}
+
+func (b *byLanguage) Classify(ctx context.Context, text string) (Result, error) {
+	if c, ok := b.routes[Language(ctx, text)]; ok {
+		return c.Classify(ctx, text)
+	}
+	return b.def.Classify(ctx, text)
+}
@@ -0,0 +1,37 @@
+package sentiment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestByLanguage(t *testing.T) {
+	fixed := func(version string) Classifier {
+		return classifierFunc(func(context.Context, string) (Result, error) {
+			return Result{Label: Neutral, Confidence: 1, PromptVersion: version}, nil
+		})
+	}
+	c := ByLanguage(fixed("default"), map[string]Classifier{"en": fixed("en")})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		text string
+		want string
+	}{
+		{"detected", context.Background(), "The kettle is great", "en"},
+		{"other language", context.Background(), "Отличный чайник", "default"},
+		{"stored language", WithLanguage(context.Background(), "en"), "Отличный чайник", "en"},
+		{"empty stored language", WithLanguage(context.Background(), ""), "The kettle is great", "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := c.Classify(tt.ctx, tt.text)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, res.PromptVersion)
+		})
+	}
+}
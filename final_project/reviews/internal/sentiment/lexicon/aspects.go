@@ -0,0 +1,110 @@
+package lexicon
+
+import (
+	"go-masters/final_project/reviews/internal/sentiment"
+)
+
+// aspectWords - слова и основы слов, указывающие на аспект товара.
+var aspectWords = map[string]sentiment.Aspect{
+	// Качество.
+	"качеств": sentiment.Quality, "материал": sentiment.Quality, "сборк": sentiment.Quality,
+	"сделан": sentiment.Quality, "брак": sentiment.Quality, "сломал": sentiment.Quality,
+	"quality": sentiment.Quality, "material": sentiment.Quality, "materials": sentiment.Quality,
+	"build": sentiment.Quality, "made": sentiment.Quality, "broke": sentiment.Quality,
+	// Цена.
+	"цена": sentiment.Price, "цене": sentiment.Price, "цену": sentiment.Price,
+	"цены": sentiment.Price, "ценой": sentiment.Price, "ценник": sentiment.Price,
+	"стоимост": sentiment.Price, "стоит": sentiment.Price, "дорог": sentiment.Price,
+	"дешев": sentiment.Price, "переплат": sentiment.Price, "деньг": sentiment.Price,
+	"price": sentiment.Price, "cost": sentiment.Price, "costs": sentiment.Price,
+	"cheap": sentiment.Price, "expensive": sentiment.Price, "overpriced": sentiment.Price,
+	"pricey": sentiment.Price, "money": sentiment.Price,
+	// Доставка.
+	"доставк": sentiment.Delivery, "доставил": sentiment.Delivery, "доставлен": sentiment.Delivery,
+	"курьер": sentiment.Delivery, "привез": sentiment.Delivery, "посылк": sentiment.Delivery,
+	"упаковк": sentiment.Delivery, "пришел": sentiment.Delivery, "пришла": sentiment.Delivery,
+	"пришло": sentiment.Delivery, "delivery": sentiment.Delivery, "delivered": sentiment.Delivery,
+	"shipping": sentiment.Delivery, "shipped": sentiment.Delivery, "arrived": sentiment.Delivery,
+	"courier": sentiment.Delivery, "package": sentiment.Delivery, "packaging": sentiment.Delivery,
+	// Поддержка и обслуживание.
+	"поддержк": sentiment.Support, "продав": sentiment.Support, "оператор": sentiment.Support,
+	"менеджер": sentiment.Support, "сервис": sentiment.Support, "гаранти": sentiment.Support,
+	"support": sentiment.Support, "service": sentiment.Support, "seller": sentiment.Support,
+	"warranty": sentiment.Support, "staff": sentiment.Support,
+}
+
+// aspectOpinions - оценочные слова, которые имеют вес только в связи
+// с аспектом: "дорого" - плохо для цены, но не говорит о товаре в целом.
+var aspectOpinions = map[string]float64{
+	"дорог": -1.5, "дешев": 1, "переплат": -1.5, "долг": -1, "задерж": -1.5,
+	"опоздал": -1.5, "вежлив": 2, "груб": -2, "помогл": 2, "игнорир": -2,
+	"expensive": -1.5, "overpriced": -2.5, "pricey": -1.5, "cheap": 1, "late": -1.5,
+	"delayed": -1.5, "polite": 2, "rude": -2, "helpful": 2, "ignored": -2,
+}
+
+// aspects оценивает аспекты, упомянутые в тексте. Текст делится на фразы
+// по знакам препинания и противительным союзам; оценка фразы относится
+// ко всем аспектам, упомянутым в ней. Аспекты, о которых нет мнения,
+// не возвращаются.
+func aspects(toks []token) []sentiment.AspectResult {
+	sums := make(map[sentiment.Aspect]float64)
+	found := make(map[sentiment.Aspect]bool)
+
+	var clause []token
+	flush := func() {
+		mentioned := make(map[sentiment.Aspect]bool)
+		var extra float64
+		for _, tok := range clause {
+			if a, ok := lookupIn(aspectWords, tok.word); ok {
+				mentioned[a] = true
+			}
+			if v, ok := lookupIn(aspectOpinions, tok.word); ok {
+				extra += v
+			}
+		}
+		if len(mentioned) > 0 {
+			v := score(clause) + extra
+			for a := range mentioned {
+				if v != 0 {
+					sums[a] += v
+					found[a] = true
+				}
+			}
+		}
+		clause = clause[:0]
+	}
+	for _, tok := range toks {
+		if tok.boundary || contrasts[tok.word] {
+			flush()
+			continue
+		}
+		clause = append(clause, tok)
+	}
+	flush()
+
+	var res []sentiment.AspectResult
+	for _, a := range sentiment.Aspects {
+		if !found[a] {
+			continue
+		}
+		r := result(normalize(sums[a]))
+		res = append(res, sentiment.AspectResult{Aspect: a, Label: r.Label, Confidence: r.Confidence})
+	}
+	return res
+}
+
+// lookupIn ищет слово в словаре m: сначала точное совпадение, затем самую
+// длинную основу, являющуюся префиксом слова (как lookup).
+func lookupIn[V any](m map[string]V, w string) (V, bool) {
+	if v, ok := m[w]; ok {
+		return v, true
+	}
+	r := []rune(w)
+	for n := len(r) - 1; n >= minStem; n-- {
+		if v, ok := m[string(r[:n])]; ok {
+			return v, true
+		}
+	}
+	var zero V
+	return zero, false
+}
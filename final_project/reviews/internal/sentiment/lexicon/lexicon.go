@@ -0,0 +1,215 @@
+// Package lexicon реализует классификатор настроения на основе словаря
+// оценочных слов для русского и английского языков. Классификатор не требует
+// внешних сервисов и используется как запасной, когда LLM недоступна.
+package lexicon
+
+import (
+	"context"
+	"math"
+	"strings"
+	"unicode"
+
+	"go-masters/final_project/reviews/internal/sentiment"
+)
+
+// Имя и версия словарного классификатора, сохраняемые вместе с результатом.
+// Версию следует увеличивать при изменении словаря или правил оценки.
+const (
+	Model   = "lexicon"
+	Version = "2"
+)
+
+const (
+	// Минимальная длина основы слова в символах при поиске по префиксу.
+	minStem = 4
+	// Число слов после отрицания, на которые оно распространяется.
+	negationScope = 3
+	// Коэффициент для слов под отрицанием: "не плохо" слабее, чем "хорошо".
+	negationFactor = -0.75
+	// Вес мнения до противительного союза.
+	contrastFactor = 0.5
+	// Параметр нормализации суммы весов в диапазон (-1, 1).
+	alpha = 4
+	// Порог нормализованной оценки для положительной или отрицательной метки.
+	threshold = 0.25
+)
+
+// Classifier - словарный классификатор настроения.
+type Classifier struct{}
+
+var _ sentiment.Classifier = (*Classifier)(nil)
+
+func New() *Classifier {
+	return &Classifier{}
+}
+
+// Classify оценивает текст отзыва.
+func (c *Classifier) Classify(_ context.Context, text string) (sentiment.Result, error) {
+	res := c.classify(text)
+	res.Model = Model
+	res.PromptVersion = Version
+	return res, nil
+}
+
+func (c *Classifier) classify(text string) sentiment.Result {
+	toks := tokenize(text)
+	res := result(normalize(score(toks)))
+	res.Aspects = aspects(toks)
+	return res
+}
+
+// result возвращает метку и уверенность для нормализованной оценки.
+func result(score float64) sentiment.Result {
+	switch {
+	case score >= threshold:
+		return sentiment.Result{Label: sentiment.Positive, Confidence: round(score)}
+	case score <= -threshold:
+		return sentiment.Result{Label: sentiment.Negative, Confidence: round(-score)}
+	default:
+		// Чем ближе оценка к нулю, тем увереннее нейтральная метка.
+		return sentiment.Result{Label: sentiment.Neutral, Confidence: round(1 - math.Abs(score)/threshold/2)}
+	}
+}
+
+// score возвращает сумму весов оценочных слов текста.
+func score(toks []token) float64 {
+	var (
+		sum     float64
+		mod     = 1.0 // множитель от усилителя перед словом
+		negLeft int   // сколько слов еще под отрицанием
+	)
+
+	for _, tok := range toks {
+		switch {
+		case tok.boundary:
+			negLeft, mod = 0, 1
+			continue
+		case tok.emoji != 0:
+			sum += tok.emoji
+			continue
+		}
+
+		w := tok.word
+		if negations[w] || strings.HasSuffix(w, "n't") {
+			negLeft = negationScope
+			continue
+		}
+		if contrasts[w] {
+			sum *= contrastFactor
+			negLeft, mod = 0, 1
+			continue
+		}
+		if m, ok := modifiers[w]; ok {
+			mod *= m
+			continue
+		}
+
+		v, ok := lookup(w)
+		if !ok {
+			if negLeft > 0 {
+				negLeft--
+			}
+			continue
+		}
+		v *= mod
+		if negLeft > 0 {
+			v *= negationFactor
+			negLeft = 0
+		}
+		sum += v
+		mod = 1
+	}
+
+	return sum
+}
+
+// lookup ищет вес слова: сначала точное совпадение, затем самую длинную
+// основу из словаря, являющуюся префиксом слова.
+func lookup(w string) (float64, bool) {
+	if v, ok := words[w]; ok {
+		return v, true
+	}
+	r := []rune(w)
+	for n := len(r) - 1; n >= minStem; n-- {
+		if v, ok := words[string(r[:n])]; ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// normalize приводит сумму весов к диапазону (-1, 1).
+func normalize(sum float64) float64 {
+	return sum / math.Sqrt(sum*sum+alpha)
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// token - слово, эмодзи или граница фразы.
+type token struct {
+	word     string
+	emoji    float64
+	boundary bool
+}
+
+// tokenize разбивает текст на слова в нижнем регистре, эмодзи, смайлики
+// вида ":)" и ")))", а также границы фраз по знакам препинания.
+func tokenize(text string) []token {
+	var (
+		toks []token
+		word strings.Builder
+	)
+	flush := func() {
+		if word.Len() > 0 {
+			toks = append(toks, token{word: word.String()})
+			word.Reset()
+		}
+	}
+
+	r := []rune(strings.ToLower(text))
+	for i := 0; i < len(r); i++ {
+		c := r[i]
+		switch {
+		case c == 'ё':
+			word.WriteRune('е')
+		case unicode.IsLetter(c) || unicode.IsDigit(c):
+			word.WriteRune(c)
+		case c == '\'' || c == '’':
+			// Апостроф внутри английского слова: don't, isn't.
+			if word.Len() > 0 && i+1 < len(r) && unicode.IsLetter(r[i+1]) {
+				word.WriteRune('\'')
+				continue
+			}
+			flush()
+		case c == ')' || c == '(':
+			flush()
+			j := i
+			for j < len(r) && r[j] == c {
+				j++
+			}
+			// Одиночная скобка - обычно часть текста, а не смайлик,
+			// если перед ней нет двоеточия.
+			smiley := j-i > 1 || (i > 0 && (r[i-1] == ':' || r[i-1] == '-'))
+			if smiley {
+				v := 1.5
+				if c == '(' {
+					v = -1.5
+				}
+				toks = append(toks, token{emoji: v})
+			}
+			i = j - 1
+		default:
+			flush()
+			if v, ok := emoji[c]; ok {
+				toks = append(toks, token{emoji: v})
+			} else if c == '.' || c == '!' || c == '?' || c == ';' || c == ',' {
+				toks = append(toks, token{boundary: true})
+			}
+		}
+	}
+	flush()
+
+	return toks
+}
@@ -0,0 +1,160 @@
+package lexicon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"go-masters/final_project/reviews/internal/sentiment"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sample - размеченный отзыв из testdata/reviews.jsonl.
+type sample struct {
+	Text  string          `json:"text"`
+	Label sentiment.Label `json:"label"`
+}
+
+func loadDataset(tb testing.TB) []sample {
+	tb.Helper()
+
+	f, err := os.Open("testdata/reviews.jsonl")
+	require.NoError(tb, err)
+	defer f.Close()
+
+	var data []sample
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var s sample
+		require.NoError(tb, json.Unmarshal(sc.Bytes(), &s))
+		data = append(data, s)
+	}
+	require.NoError(tb, sc.Err())
+
+	return data
+}
+
+// accuracy возвращает долю верно классифицированных отзывов.
+func accuracy(tb testing.TB, c *Classifier, data []sample) float64 {
+	tb.Helper()
+
+	var ok int
+	for _, s := range data {
+		res := c.classify(s.Text)
+		if res.Label == s.Label {
+			ok++
+			continue
+		}
+		if testing.Verbose() {
+			tb.Logf("%q: ожидалось %s, получено %s", s.Text, s.Label, res.Label)
+		}
+	}
+	return float64(ok) / float64(len(data))
+}
+
+func TestClassifier_Classify(t *testing.T) {
+	c := New()
+
+	tests := []struct {
+		text string
+		want sentiment.Label
+	}{
+		{text: "Хороший товар", want: sentiment.Positive},
+		{text: "Не хороший товар", want: sentiment.Negative},
+		{text: "Красивый, но очень медленный", want: sentiment.Negative},
+		{text: "Ничего не понял, но очень круто", want: sentiment.Positive},
+		{text: "It isn't great", want: sentiment.Negative},
+		{text: "👍👍👍", want: sentiment.Positive},
+		{text: "Пришел вчера", want: sentiment.Neutral},
+		{text: "", want: sentiment.Neutral},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			res, err := c.Classify(context.Background(), tt.text)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, res.Label)
+			assert.NoError(t, res.Validate())
+		})
+	}
+}
+
+func TestClassifier_Intensifier(t *testing.T) {
+	c := New()
+
+	plain := c.classify("good")
+	strong := c.classify("very good")
+	assert.Greater(t, strong.Confidence, plain.Confidence)
+}
+
+func TestClassifier_Aspects(t *testing.T) {
+	c := New()
+
+	tests := []struct {
+		text string
+		want map[sentiment.Aspect]sentiment.Label
+	}{
+		{
+			text: "Отличное качество, но цена слишком дорогая",
+			want: map[sentiment.Aspect]sentiment.Label{
+				sentiment.Quality: sentiment.Positive,
+				sentiment.Price:   sentiment.Negative,
+			},
+		},
+		{
+			text: "Доставка быстрая, поддержка грубая",
+			want: map[sentiment.Aspect]sentiment.Label{
+				sentiment.Delivery: sentiment.Positive,
+				sentiment.Support:  sentiment.Negative,
+			},
+		},
+		{
+			text: "Great quality. Shipping was late",
+			want: map[sentiment.Aspect]sentiment.Label{
+				sentiment.Quality:  sentiment.Positive,
+				sentiment.Delivery: sentiment.Negative,
+			},
+		},
+		// Упоминание аспекта без оценки не учитывается.
+		{text: "Пришел вчера", want: map[sentiment.Aspect]sentiment.Label{}},
+		{text: "Хороший товар", want: map[sentiment.Aspect]sentiment.Label{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			res, err := c.Classify(context.Background(), tt.text)
+			require.NoError(t, err)
+			require.NoError(t, res.Validate())
+
+			got := make(map[sentiment.Aspect]sentiment.Label)
+			for _, a := range res.Aspects {
+				got[a.Aspect] = a.Label
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClassifier_Accuracy(t *testing.T) {
+	acc := accuracy(t, New(), loadDataset(t))
+	t.Logf("точность: %.2f", acc)
+	assert.GreaterOrEqual(t, acc, 0.85)
+}
+
+func BenchmarkClassifier(b *testing.B) {
+	c := New()
+	data := loadDataset(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, s := range data {
+			c.Classify(context.Background(), s.Text)
+		}
+	}
+	b.StopTimer()
+
+	b.ReportMetric(accuracy(b, c, data), "accuracy")
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(data)), "ns/review")
+}
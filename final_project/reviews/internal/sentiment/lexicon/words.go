@@ -0,0 +1,174 @@
+package lexicon
+
+// words - оценочные слова и основы слов с их весом.
+// Для русских слов хранятся основы: слово сопоставляется с самой длинной
+// основой, которая является его префиксом (см. Classifier.lookup).
+var words = map[string]float64{
+	// Русский, положительные.
+	"хорош":       2,
+	"отличн":      3,
+	"отлично":     3,
+	"прекрасн":    3,
+	"великолепн":  3.5,
+	"замечательн": 3,
+	"превосходн":  3.5,
+	"идеальн":     3,
+	"шикарн":      3,
+	"классн":      2.5,
+	"класс":       2.5,
+	"супер":       2.5,
+	"крут":        2.5,
+	"удобн":       2,
+	"качествен":   2,
+	"надежн":      2,
+	"быстр":       1,
+	"доволен":     2,
+	"довольн":     2,
+	"рекоменд":    2,
+	"советую":     2,
+	"нравится":    2,
+	"понравил":    2,
+	"люблю":       2.5,
+	"любим":       2,
+	"спасибо":     1.5,
+	"радует":      2,
+	"порадовал":   2,
+	"восторг":     3,
+	"приятн":      2,
+	"норм":        0.5,
+	"нормальн":    0.5,
+	"работает":    0.5,
+	"выгодн":      1.5,
+	"стоит":       0.5,
+
+	// Русский, отрицательные.
+	"плох":         -2,
+	"ужасн":        -3,
+	"кошмар":       -3,
+	"отвратительн": -3.5,
+	"мерзк":        -3,
+	"худш":         -3,
+	"брак":         -2.5,
+	"бракован":     -2.5,
+	"сломал":       -2.5,
+	"слома":        -2.5,
+	"развалил":     -2.5,
+	"разочаров":    -2.5,
+	"недоволен":    -2,
+	"недовольн":    -2,
+	"возврат":      -1.5,
+	"верните":      -2,
+	"обман":        -3,
+	"мошенни":      -3.5,
+	"дешевк":       -2,
+	"хлам":         -3,
+	"мусор":        -2.5,
+	"проблем":      -1.5,
+	"медленн":      -1.5,
+	"тормоз":       -2,
+	"неудобн":      -2,
+	"бесполезн":    -2.5,
+	"некачествен":  -2.5,
+	"жаль":         -1.5,
+	"зря":          -2,
+	"отстой":       -3,
+	"ужас":         -3,
+	"поцарапан":    -2,
+
+	// Английский, положительные.
+	"good":        2,
+	"great":       3,
+	"excellent":   3.5,
+	"amazing":     3.5,
+	"awesome":     3,
+	"perfect":     3,
+	"fantastic":   3.5,
+	"wonderful":   3,
+	"nice":        2,
+	"love":        3,
+	"loved":       3,
+	"loves":       3,
+	"like":        1.5,
+	"liked":       1.5,
+	"recommend":   2,
+	"happy":       2.5,
+	"satisfied":   2,
+	"pleased":     2,
+	"best":        3,
+	"comfortable": 2,
+	"reliable":    2,
+	"fast":        1,
+	"works":       0.5,
+	"worth":       1.5,
+	"thanks":      1.5,
+	"fine":        0.5,
+	"ok":          0.5,
+	"okay":        0.5,
+
+	// Английский, отрицательные.
+	"bad":           -2.5,
+	"badly":         -2.5,
+	"poor":          -2.5,
+	"poorly":        -2.5,
+	"terrible":      -3.5,
+	"awful":         -3.5,
+	"horrible":      -3.5,
+	"worst":         -3.5,
+	"worse":         -2.5,
+	"hate":          -3,
+	"hated":         -3,
+	"broken":        -2.5,
+	"broke":         -2.5,
+	"disappoint":    -2.5,
+	"useless":       -3,
+	"waste":         -3,
+	"refund":        -1.5,
+	"return":        -1,
+	"returned":      -1.5,
+	"defective":     -3,
+	"scam":          -3.5,
+	"junk":          -3,
+	"garbage":       -3,
+	"cheaply":       -2,
+	"slow":          -1.5,
+	"problem":       -1.5,
+	"problems":      -1.5,
+	"issue":         -1,
+	"issues":        -1,
+	"uncomfortable": -2,
+	"unreliable":    -2,
+	"regret":        -2.5,
+	"annoying":      -2,
+}
+
+// negations меняют знак ближайших оценочных слов.
+var negations = map[string]bool{
+	"не": true, "нет": true, "ни": true, "без": true, "никогда": true, "нисколько": true,
+	"not": true, "no": true, "never": true, "without": true, "nothing": true, "hardly": true,
+}
+
+// modifiers усиливают или ослабляют следующее оценочное слово.
+var modifiers = map[string]float64{
+	"очень": 1.5, "крайне": 1.6, "невероятно": 1.7, "абсолютно": 1.6, "совершенно": 1.5,
+	"совсем": 1.4, "просто": 1.2, "самый": 1.4, "самое": 1.4, "самая": 1.4, "весьма": 1.3,
+	"немного": 0.6, "слегка": 0.5, "чуть": 0.5, "довольно": 0.8, "относительно": 0.7,
+	"very": 1.5, "really": 1.4, "extremely": 1.7, "absolutely": 1.6, "totally": 1.5,
+	"so": 1.3, "super": 1.5, "incredibly": 1.7, "highly": 1.5, "most": 1.3,
+	"slightly": 0.5, "somewhat": 0.6, "bit": 0.6, "kinda": 0.6, "fairly": 0.8, "quite": 1.1,
+}
+
+// contrasts - противительные союзы: после них мнение весит больше,
+// чем до них ("дизайн хороший, но батарея ужасная").
+var contrasts = map[string]bool{
+	"но": true, "однако": true, "зато": true,
+	"but": true, "however": true, "although": true,
+}
+
+// emoji - эмодзи с их весом.
+var emoji = map[rune]float64{
+	'😀': 2, '😃': 2, '😄': 2, '😁': 2, '😊': 2, '🙂': 1, '😍': 3, '🥰': 3,
+	'😎': 1.5, '👍': 2, '👌': 1.5, '❤': 3, '💯': 2.5, '🔥': 2, '⭐': 1.5, '🎉': 2,
+	'😐': 0, '🤔': -0.5,
+	'😞': -2, '😔': -2, '😟': -2, '🙁': -1.5, '😕': -1.5, '😢': -2, '😭': -2.5,
+	'😠': -2.5, '😡': -3, '🤬': -3.5, '👎': -2.5, '💩': -3, '🤮': -3.5, '😤': -2,
+}
@@ -0,0 +1,174 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-masters/final_project/reviews/internal/llm"
+	"go-masters/final_project/reviews/internal/prompts"
+	"go-masters/final_project/reviews/internal/sentiment"
+
+	"github.com/ollama/ollama/api"
+)
+
+// PromptName - имя шаблона запроса классификации в реестре prompts.
+// В шаблон передается поле .Text с текстом отзыва.
+const PromptName = "sentiment"
+
+// format - JSON схема ответа для structured output.
+var format = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"label": {"type": "string", "enum": ["positive", "neutral", "negative"]},
+		"confidence": {"type": "number", "minimum": 0, "maximum": 1},
+		"aspects": {
+			"type": "array",
+			"items": {
+				"type": "object",
+				"properties": {
+					"aspect": {"type": "string", "enum": ["quality", "price", "delivery", "support"]},
+					"label": {"type": "string", "enum": ["positive", "neutral", "negative"]},
+					"confidence": {"type": "number", "minimum": 0, "maximum": 1}
+				},
+				"required": ["aspect", "label", "confidence"]
+			}
+		}
+	},
+	"required": ["label", "confidence", "aspects"]
+}`)
+
+// ErrInvalidResponse - модель вернула ответ, не соответствующий формату.
+var ErrInvalidResponse = errors.New("некорректный ответ модели")
+
+// Config - настройки классификатора.
+type Config struct {
+	Client *llm.Client
+	Model  string
+	// Шаблон запроса; его версия сохраняется вместе с результатом.
+	// Если не задан, используется последняя встроенная версия PromptName.
+	Prompt *prompts.Prompt
+	// Ограничение времени одной классификации.
+	Timeout time.Duration
+}
+
+// Classifier - классификатор настроения на базе модели Ollama.
+type Classifier struct {
+	client  *llm.Client
+	model   string
+	prompt  *prompts.Prompt
+	timeout time.Duration
+}
+
+var _ sentiment.Classifier = (*Classifier)(nil)
+
+func New(cfg Config) (*Classifier, error) {
+	if cfg.Client == nil {
+		return nil, errors.New("не задан клиент Ollama")
+	}
+	if cfg.Model == "" {
+		return nil, errors.New("не указана модель Ollama")
+	}
+
+	prompt := cfg.Prompt
+	if prompt == nil {
+		var err error
+		if prompt, err = prompts.Default().Get(PromptName, prompts.Latest); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Classifier{
+		client:  cfg.Client,
+		model:   cfg.Model,
+		prompt:  prompt,
+		timeout: cfg.Timeout,
+	}, nil
+}
+
+// Classify отправляет текст отзыва в модель и разбирает ответ.
+func (c *Classifier) Classify(ctx context.Context, text string) (sentiment.Result, error) {
+	prompt, err := c.prompt.Execute(struct{ Text string }{Text: text})
+	if err != nil {
+		return sentiment.Result{}, fmt.Errorf("ошибка подготовки запроса: %w", err)
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	stream := false
+	req := &api.GenerateRequest{
+		Model:  c.model,
+		Prompt: prompt,
+		Stream: &stream,
+		Format: format,
+		// Для классификации нужен детерминированный ответ.
+		Options: map[string]any{"temperature": 0},
+	}
+
+	var out strings.Builder
+	err = c.client.Generate(ctx, req, func(resp api.GenerateResponse) error {
+		out.WriteString(resp.Response)
+		return nil
+	})
+	if err != nil {
+		return sentiment.Result{}, fmt.Errorf("ошибка генерации: %w", err)
+	}
+
+	res, err := parse(out.String())
+	if err != nil {
+		return sentiment.Result{}, err
+	}
+	res.Model = c.model
+	res.PromptVersion = c.prompt.Version
+	return res, nil
+}
+
+// parse строго разбирает ответ модели: допускается только JSON объект
+// с полями label, confidence и необязательным списком aspects
+// (его нет в ответах на шаблоны, написанные до появления аспектов).
+func parse(s string) (sentiment.Result, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.DisallowUnknownFields()
+
+	var raw struct {
+		Label      *sentiment.Label `json:"label"`
+		Confidence *float64         `json:"confidence"`
+		Aspects    []struct {
+			Aspect     *sentiment.Aspect `json:"aspect"`
+			Label      *sentiment.Label  `json:"label"`
+			Confidence *float64          `json:"confidence"`
+		} `json:"aspects"`
+	}
+	if err := dec.Decode(&raw); err != nil {
+		return sentiment.Result{}, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	if dec.More() {
+		return sentiment.Result{}, fmt.Errorf("%w: лишние данные после JSON", ErrInvalidResponse)
+	}
+	if raw.Label == nil || raw.Confidence == nil {
+		return sentiment.Result{}, fmt.Errorf("%w: отсутствуют обязательные поля", ErrInvalidResponse)
+	}
+
+	res := sentiment.Result{Label: *raw.Label, Confidence: *raw.Confidence}
+	for _, a := range raw.Aspects {
+		if a.Aspect == nil || a.Label == nil || a.Confidence == nil {
+			return sentiment.Result{}, fmt.Errorf("%w: отсутствуют обязательные поля аспекта", ErrInvalidResponse)
+		}
+		res.Aspects = append(res.Aspects, sentiment.AspectResult{
+			Aspect:     *a.Aspect,
+			Label:      *a.Label,
+			Confidence: *a.Confidence,
+		})
+	}
+	if err := res.Validate(); err != nil {
+		return sentiment.Result{}, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,127 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-masters/final_project/reviews/internal/llm"
+	"go-masters/final_project/reviews/internal/llm/ollamatest"
+	"go-masters/final_project/reviews/internal/prompts"
+	"go-masters/final_project/reviews/internal/sentiment"
+
+	"github.com/ollama/ollama/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T, endpoint string) *llm.Client {
+	t.Helper()
+
+	c, err := llm.New(llm.Config{Endpoint: endpoint})
+	require.NoError(t, err)
+	return c
+}
+
+func TestClassifier_Classify(t *testing.T) {
+	srv := ollamatest.NewServer(func(req api.GenerateRequest) (string, error) {
+		if strings.Contains(req.Prompt, "ужасно") {
+			return `{"label": "negative", "confidence": 0.9}`, nil
+		}
+		return `{"label": "positive", "confidence": 0.75}`, nil
+	})
+	defer srv.Close()
+
+	prompt, err := prompts.Parse(PromptName, "v2", "Отзыв: {{.Text}}")
+	require.NoError(t, err)
+	c, err := New(Config{
+		Client: newClient(t, srv.URL),
+		Model:  "qwen2.5:1.5b",
+		Prompt: prompt,
+	})
+	require.NoError(t, err)
+
+	res, err := c.Classify(context.Background(), "Работает ужасно")
+	require.NoError(t, err)
+	assert.Equal(t, sentiment.Result{
+		Label:         sentiment.Negative,
+		Confidence:    0.9,
+		Model:         "qwen2.5:1.5b",
+		PromptVersion: "v2",
+	}, res)
+
+	reqs := srv.Requests()
+	require.Len(t, reqs, 1)
+	assert.Equal(t, "qwen2.5:1.5b", reqs[0].Model)
+	assert.Equal(t, "Отзыв: Работает ужасно", reqs[0].Prompt)
+	assert.NotEmpty(t, reqs[0].Format)
+}
+
+func TestClassifier_ServerError(t *testing.T) {
+	srv := ollamatest.NewServer(func(api.GenerateRequest) (string, error) {
+		return "", errors.New("model not found")
+	})
+	defer srv.Close()
+
+	c, err := New(Config{Client: newClient(t, srv.URL), Model: "missing"})
+	require.NoError(t, err)
+
+	_, err = c.Classify(context.Background(), "текст")
+	assert.ErrorContains(t, err, "model not found")
+}
+
+func TestClassifier_Timeout(t *testing.T) {
+	srv := ollamatest.NewServer(func(api.GenerateRequest) (string, error) {
+		time.Sleep(200 * time.Millisecond)
+		return `{"label": "neutral", "confidence": 0.5}`, nil
+	})
+	defer srv.Close()
+
+	c, err := New(Config{Client: newClient(t, srv.URL), Model: "slow", Timeout: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	_, err = c.Classify(context.Background(), "текст")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func Test_parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    sentiment.Result
+		wantErr bool
+	}{
+		{name: "valid", in: `{"label":"neutral","confidence":0.5}`, want: sentiment.Result{Label: sentiment.Neutral, Confidence: 0.5}},
+		{name: "surrounding spaces", in: " \n{\"label\":\"positive\",\"confidence\":1}\n", want: sentiment.Result{Label: sentiment.Positive, Confidence: 1}},
+		{name: "unknown label", in: `{"label":"angry","confidence":0.5}`, wantErr: true},
+		{name: "confidence out of range", in: `{"label":"neutral","confidence":1.5}`, wantErr: true},
+		{name: "missing confidence", in: `{"label":"neutral"}`, wantErr: true},
+		{name: "extra field", in: `{"label":"neutral","confidence":0.5,"reason":"..."}`, wantErr: true},
+		{name: "prose around json", in: `Ответ: {"label":"neutral","confidence":0.5}`, wantErr: true},
+		{name: "trailing data", in: `{"label":"neutral","confidence":0.5} ok`, wantErr: true},
+		{
+			name: "aspects",
+			in:   `{"label":"neutral","confidence":0.6,"aspects":[{"aspect":"quality","label":"positive","confidence":0.9},{"aspect":"delivery","label":"negative","confidence":0.8}]}`,
+			want: sentiment.Result{Label: sentiment.Neutral, Confidence: 0.6, Aspects: []sentiment.AspectResult{
+				{Aspect: sentiment.Quality, Label: sentiment.Positive, Confidence: 0.9},
+				{Aspect: sentiment.Delivery, Label: sentiment.Negative, Confidence: 0.8},
+			}},
+		},
+		{name: "unknown aspect", in: `{"label":"neutral","confidence":0.5,"aspects":[{"aspect":"color","label":"positive","confidence":0.9}]}`, wantErr: true},
+		{name: "duplicate aspect", in: `{"label":"neutral","confidence":0.5,"aspects":[{"aspect":"price","label":"positive","confidence":0.9},{"aspect":"price","label":"negative","confidence":0.9}]}`, wantErr: true},
+		{name: "aspect without label", in: `{"label":"neutral","confidence":0.5,"aspects":[{"aspect":"price","confidence":0.9}]}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parse(tt.in)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidResponse)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package sentiment
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go-masters/final_project/reviews/internal/models"
+)
+
+// Label - метка настроения отзыва.
+type Label string
+
+const (
+	Positive Label = "positive"
+	Neutral  Label = "neutral"
+	Negative Label = "negative"
+)
+
+// Valid сообщает, является ли метка известной.
+func (l Label) Valid() bool {
+	switch l {
+	case Positive, Neutral, Negative:
+		return true
+	}
+	return false
+}
+
+// Aspect - аспект товара, о котором высказывается отзыв.
+type Aspect string
+
+const (
+	Quality  Aspect = "quality"
+	Price    Aspect = "price"
+	Delivery Aspect = "delivery"
+	Support  Aspect = "support"
+)
+
+// Aspects - все известные аспекты.
+var Aspects = []Aspect{Quality, Price, Delivery, Support}
+
+// Valid сообщает, является ли аспект известным.
+func (a Aspect) Valid() bool {
+	switch a {
+	case Quality, Price, Delivery, Support:
+		return true
+	}
+	return false
+}
+
+// AspectResult - настроение отзыва по отдельному аспекту.
+type AspectResult struct {
+	Aspect     Aspect  `json:"aspect"`
+	Label      Label   `json:"label"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Result - результат классификации.
+type Result struct {
+	Label Label `json:"label"`
+	// Уверенность классификатора от 0 до 1.
+	Confidence float64 `json:"confidence"`
+	// Настроение по аспектам, упомянутым в отзыве.
+	Aspects []AspectResult `json:"aspects,omitempty"`
+	// Модель и версия запроса, которыми получен результат. По ним
+	// находятся отзывы, требующие повторной классификации.
+	Model         string `json:"model,omitempty"`
+	PromptVersion string `json:"prompt_version,omitempty"`
+}
+
+// Validate проверяет корректность результата.
+func (r Result) Validate() error {
+	if !r.Label.Valid() {
+		return fmt.Errorf("неизвестная метка настроения %q", r.Label)
+	}
+	if r.Confidence < 0 || r.Confidence > 1 {
+		return fmt.Errorf("уверенность %v вне диапазона [0, 1]", r.Confidence)
+	}
+	seen := make(map[Aspect]bool, len(r.Aspects))
+	for _, a := range r.Aspects {
+		switch {
+		case !a.Aspect.Valid():
+			return fmt.Errorf("неизвестный аспект %q", a.Aspect)
+		case seen[a.Aspect]:
+			return fmt.Errorf("аспект %q указан дважды", a.Aspect)
+		case !a.Label.Valid():
+			return fmt.Errorf("неизвестная метка настроения %q аспекта %q", a.Label, a.Aspect)
+		case a.Confidence < 0 || a.Confidence > 1:
+			return fmt.Errorf("уверенность %v аспекта %q вне диапазона [0, 1]", a.Confidence, a.Aspect)
+		}
+		seen[a.Aspect] = true
+	}
+	return nil
+}
+
+// Sentiment возвращает настроение отзыва для сохранения в хранилище.
+func (r Result) Sentiment() models.Sentiment {
+	s := models.Sentiment{
+		Status:        models.SentimentDone,
+		Label:         string(r.Label),
+		Confidence:    r.Confidence,
+		Model:         r.Model,
+		PromptVersion: r.PromptVersion,
+	}
+	for _, a := range r.Aspects {
+		s.Aspects = append(s.Aspects, models.AspectSentiment{
+			Aspect:     string(a.Aspect),
+			Label:      string(a.Label),
+			Confidence: a.Confidence,
+		})
+	}
+	slices.SortFunc(s.Aspects, func(a, b models.AspectSentiment) int {
+		return strings.Compare(a.Aspect, b.Aspect)
+	})
+	return s
+}
+
+// Classifier - классификатор настроения текста отзыва.
+type Classifier interface {
+	Classify(ctx context.Context, text string) (Result, error)
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go-masters/final_project/reviews/internal/reclassify"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// adminOnly пропускает запросы с токеном административного API
+// в заголовке Authorization: Bearer <token>.
+func (s *Server) adminOnly(next http.Handler) http.Handler {
+	expected := []byte("Bearer " + s.cfg.AdminToken)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.cfg.AdminToken == "" {
+			writeError(w, http.StatusForbidden, "административный API отключен")
+			return
+		}
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, expected) != 1 {
+			writeError(w, http.StatusUnauthorized, "неверный токен")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) startReclassifyHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса startReclassify")
+	span.AddEvent("Обработка запроса startReclassify")
+
+	var f reclassify.Filter
+	if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+		span.SetStatus(codes.Error, "не удалось декодировать запрос")
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	run, err := s.reclassify.Start(r.Context(), f)
+	if errors.Is(err, reclassify.ErrInvalidFilter) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeDBError(w, span, err, "")
+		return
+	}
+
+	s.process(run)
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+func (s *Server) getReclassifyHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса getReclassify")
+	span.AddEvent("Обработка запроса getReclassify")
+
+	run, err := s.reclassify.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeDBError(w, span, err, "запуск не найден")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, run)
+}
+
+func (s *Server) resumeReclassifyHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса resumeReclassify")
+	span.AddEvent("Обработка запроса resumeReclassify")
+
+	run, err := s.reclassify.Resume(r.Context(), chi.URLParam(r, "id"))
+	if errors.Is(err, reclassify.ErrConflict) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeDBError(w, span, err, "запуск не найден")
+		return
+	}
+
+	s.process(run)
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+// process выполняет запуск в фоне. При остановке сервера запуск
+// прерывается и продолжается после следующего старта.
+func (s *Server) process(run reclassify.Run) {
+	go func() {
+		if _, err := s.reclassify.Process(s.bg, run); err != nil && !errors.Is(err, context.Canceled) {
+			log.Error().Err(err).Str("run_id", run.ID).Msg("Ошибка переклассификации")
+		}
+	}()
+}
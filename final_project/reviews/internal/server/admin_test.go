@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/reclassify"
+	"go-masters/final_project/reviews/internal/sentiment/lexicon"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func doAdmin(s *Server, method, target, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminAuth(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := doAdmin(s, http.MethodPost, "/admin/reclassify", `{}`, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = doAdmin(s, http.MethodPost, "/admin/reclassify", `{}`, "wrong")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	s.cfg.AdminToken = ""
+	rec = doAdmin(s, http.MethodPost, "/admin/reclassify", `{}`, "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestAdminReclassify(t *testing.T) {
+	s := newTestServer(t)
+	pid := addProduct(t, s, "Чайник")
+	rec := do(s, http.MethodPost, "/products/"+pid+"/reviews", `{"author":"A","text":"Отличный чайник","rating":5}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	review := decode[models.Review](t, rec)
+
+	_, err := jobs.NewPool(s.db.(*memdb.MemDB), lexicon.New()).RunOnce(context.Background())
+	require.NoError(t, err)
+
+	rec = doAdmin(s, http.MethodPost, "/admin/reclassify", `{"from":"2030-01-01T00:00:00Z","to":"2020-01-01T00:00:00Z"}`, adminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+
+	rec = doAdmin(s, http.MethodPost, "/admin/reclassify", `{"product_id":"`+pid+`","model":"lexicon"}`, adminToken)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	run := decode[reclassify.Run](t, rec)
+	assert.Equal(t, reclassify.StatusRunning, run.Status)
+
+	require.Eventually(t, func() bool {
+		rec := doAdmin(s, http.MethodGet, "/admin/reclassify/"+run.ID, "", adminToken)
+		run = decode[reclassify.Run](t, rec)
+		return run.Status == reclassify.StatusDone
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, run.Processed)
+
+	rec = do(s, http.MethodGet, "/products/"+pid+"/reviews/"+review.ID, "")
+	got := decode[models.Review](t, rec)
+	assert.Equal(t, lexicon.Model, got.Sentiment.Model)
+	assert.Equal(t, lexicon.Version, got.Sentiment.PromptVersion)
+
+	// Завершенный запуск продолжить нельзя.
+	rec = doAdmin(s, http.MethodPost, "/admin/reclassify/"+run.ID+"/resume", "", adminToken)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = doAdmin(s, http.MethodGet, "/admin/reclassify/unknown", "", adminToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"go-masters/final_project/reviews/internal/bulk"
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/language"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Время на импорт и на отправку каждой части выгрузки. Таймауты
+// http.Server рассчитаны на обычные запросы, а файл импорта или выгрузка
+// всех отзывов передаются дольше.
+const (
+	importTimeout      = 10 * time.Minute
+	exportWriteTimeout = 30 * time.Second
+	// exportFlushRows - число отзывов, после которого выгрузка
+	// отправляется клиенту и срок записи продлевается.
+	exportFlushRows = 1000
+)
+
+// importErrorResponse - ответ на импорт, прерванный ошибкой.
+type importErrorResponse struct {
+	bulk.Report
+	Error string `json:"error"`
+}
+
+func (s *Server) importReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса importReviews")
+	span.AddEvent("Обработка запроса importReviews")
+
+	q := r.URL.Query()
+	format, err := bulk.Format(q.Get("format"), r.Header.Get("Content-Type"))
+	if err != nil {
+		writeError(w, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+	productID := q.Get("product_id")
+	if productID != "" {
+		if _, err := s.db.GetProduct(r.Context(), productID); err != nil {
+			writeDBError(w, span, err, "товар не найден")
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(importTimeout)
+	if err := extendDeadline(rc.SetReadDeadline, deadline); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := extendDeadline(rc.SetWriteDeadline, deadline); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	rd, err := bulk.NewReader(format, r.Body, productID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rep, err := bulk.NewImporter(s.db, s.moderator).Import(r.Context(), rd)
+	span.SetAttributes(
+		attribute.String("import.format", format),
+		attribute.Int("import.imported", rep.Imported),
+		attribute.Int("import.failed", rep.Failed),
+	)
+	if err != nil {
+		log.Err(err).Int("imported", rep.Imported).Msg("Импорт отзывов прерван")
+		span.SetStatus(codes.Error, err.Error())
+		writeJSON(w, http.StatusInternalServerError, importErrorResponse{Report: rep, Error: err.Error()})
+		return
+	}
+	log.Info().
+		Int("imported", rep.Imported).
+		Int("pending", rep.Pending).
+		Int("failed", rep.Failed).
+		Msg("Отзывы импортированы")
+
+	writeJSON(w, http.StatusOK, rep)
+}
+
+func (s *Server) exportReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса exportReviews")
+	span.AddEvent("Обработка запроса exportReviews")
+
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = bulk.FormatNDJSON
+	}
+	if _, err := bulk.Format(format, ""); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	f := db.ReviewFilter{ProductID: q.Get("product_id"), Status: q.Get("status"), Language: q.Get("language")}
+	switch f.Status {
+	case "", models.ReviewPublished, models.ReviewPending, models.ReviewRejected:
+	default:
+		writeError(w, http.StatusBadRequest, "status должен быть published, pending или rejected")
+		return
+	}
+	if f.Language != "" && !language.Valid(f.Language) {
+		writeError(w, http.StatusBadRequest, errInvalidLanguage)
+		return
+	}
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &f.From}, {"to", &f.To}} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, p.name+" должен быть в формате RFC 3339")
+			return
+		}
+		*p.t = t
+	}
+	if f.ProductID != "" {
+		if _, err := s.db.GetProduct(r.Context(), f.ProductID); err != nil {
+			writeDBError(w, span, err, "товар не найден")
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", bulk.ContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="reviews.`+format+`"`)
+	bw, err := bulk.NewWriter(format, w)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	rows := 0
+	err = s.db.ExportReviews(r.Context(), f, func(rev models.Review) error {
+		if rows%exportFlushRows == 0 {
+			if err := bw.Flush(); err != nil {
+				return err
+			}
+			if err := extendDeadline(rc.SetWriteDeadline, time.Now().Add(exportWriteTimeout)); err != nil {
+				return err
+			}
+			if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return err
+			}
+		}
+		rows++
+		return bw.Write(rev)
+	})
+	if err == nil {
+		err = bw.Flush()
+	}
+	span.SetAttributes(attribute.String("export.format", format), attribute.Int("export.reviews", rows))
+	if err != nil && rows == 0 {
+		// Клиенту еще ничего не отправлено.
+		w.Header().Del("Content-Disposition")
+		writeDBError(w, span, err, "")
+		return
+	}
+	if err != nil {
+		// После начала выгрузки ошибку можно только записать в журнал:
+		// клиент увидит оборванный ответ.
+		log.Err(err).Int("reviews", rows).Msg("Выгрузка отзывов прервана")
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	log.Info().Int("reviews", rows).Msg("Отзывы выгружены")
+}
+
+// extendDeadline устанавливает срок чтения или записи соединения.
+// Соединения без поддержки сроков (например, в тестах) пропускаются.
+func extendDeadline(set func(time.Time) error, deadline time.Time) error {
+	if err := set(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
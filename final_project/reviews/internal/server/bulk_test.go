@@ -0,0 +1,71 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-masters/final_project/reviews/internal/bulk"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportExport(t *testing.T) {
+	s := newTestServer(t)
+	pid := addProduct(t, s, "Чайник")
+	other := addProduct(t, s, "Утюг")
+
+	rec := doAdmin(s, http.MethodPost, "/reviews/import?format=csv", "author,text,rating\n", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = doAdmin(s, http.MethodPost, "/reviews/import", "author,text,rating\n", adminToken)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	rec = doAdmin(s, http.MethodPost, "/reviews/import?format=csv", "author,text\n", adminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	csv := "author,text,rating,created_at\n" +
+		"Анна,Хороший чайник,5,2026-01-01T00:00:00Z\n" +
+		"Олег,Отличный,7,\n" +
+		"Иван,Нормально,3,2026-02-01T00:00:00Z\n"
+	rec = doAdmin(s, http.MethodPost, "/reviews/import?format=csv&product_id="+pid, csv, adminToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rep := decode[bulk.Report](t, rec)
+	assert.Equal(t, 2, rep.Imported)
+	assert.Equal(t, []bulk.RowError{{Line: 3, Error: "оценка должна быть от 1 до 5"}}, rep.Errors)
+
+	ndjson := `{"product_id":"` + other + `","author":"Петр","text":"Хороший утюг","rating":4}`
+	rec = doAdmin(s, http.MethodPost, "/reviews/import?format=ndjson", ndjson, adminToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 1, decode[bulk.Report](t, rec).Imported)
+
+	rec = do(s, http.MethodGet, "/products/"+pid+"/reviews", "")
+	assert.Len(t, decode[[]models.Review](t, rec), 2)
+
+	// Выгрузка отзывов товара за интервал
+	rec = doAdmin(s, http.MethodGet, "/reviews/export?product_id="+pid+"&from=2026-01-15T00:00:00Z", "", adminToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	var exported []models.Review
+	sc := bufio.NewScanner(rec.Body)
+	for sc.Scan() {
+		var r models.Review
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &r))
+		exported = append(exported, r)
+	}
+	require.Len(t, exported, 1)
+	assert.Equal(t, "Иван", exported[0].Author)
+
+	rec = doAdmin(s, http.MethodGet, "/reviews/export?format=csv", "", adminToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "id,product_id,author,text,rating"))
+
+	rec = doAdmin(s, http.MethodGet, "/reviews/export?from=вчера", "", adminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doAdmin(s, http.MethodGet, "/reviews/export?product_id=unknown", "", adminToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
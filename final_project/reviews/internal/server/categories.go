@@ -0,0 +1,159 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// categoryInput - тело запроса на создание или изменение категории.
+type categoryInput struct {
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id"`
+}
+
+// decodeCategory декодирует и проверяет тело запроса с категорией.
+func decodeCategory(w http.ResponseWriter, r *http.Request, span trace.Span) (categoryInput, bool) {
+	var req categoryInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		span.SetStatus(codes.Error, "не удалось декодировать запрос")
+		writeError(w, http.StatusBadRequest, err.Error())
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "не указано название категории")
+		return req, false
+	}
+	return req, true
+}
+
+func (s *Server) addCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса addCategory")
+	span.AddEvent("Обработка запроса addCategory")
+
+	req, ok := decodeCategory(w, r, span)
+	if !ok {
+		return
+	}
+
+	c, err := s.db.AddCategory(r.Context(), models.Category{Name: req.Name, ParentID: req.ParentID})
+	if err != nil {
+		writeDBError(w, span, err, "родительская категория не найдена")
+		return
+	}
+	s.categoryRatings.Invalidate()
+
+	writeJSON(w, http.StatusCreated, c)
+}
+
+func (s *Server) listCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса listCategories")
+	span.AddEvent("Обработка запроса listCategories")
+
+	categories, err := s.db.ListCategories(r.Context())
+	if err != nil {
+		writeDBError(w, span, err, "")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, categories)
+}
+
+func (s *Server) getCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса getCategory")
+	span.AddEvent("Обработка запроса getCategory")
+
+	c, err := s.db.GetCategory(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeDBError(w, span, err, "категория не найдена")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) updateCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса updateCategory")
+	span.AddEvent("Обработка запроса updateCategory")
+
+	req, ok := decodeCategory(w, r, span)
+	if !ok {
+		return
+	}
+
+	c, err := s.db.UpdateCategory(r.Context(), models.Category{
+		ID:       chi.URLParam(r, "id"),
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	})
+	if errors.Is(err, db.ErrConflict) {
+		writeError(w, http.StatusConflict, "категория не может быть вложена в себя или свою подкатегорию")
+		return
+	}
+	if err != nil {
+		writeDBError(w, span, err, "категория не найдена")
+		return
+	}
+	s.categoryRatings.Invalidate()
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) deleteCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса deleteCategory")
+	span.AddEvent("Обработка запроса deleteCategory")
+
+	err := s.db.DeleteCategory(r.Context(), chi.URLParam(r, "id"))
+	if errors.Is(err, db.ErrConflict) {
+		writeError(w, http.StatusConflict, "в категории есть подкатегории или товары")
+		return
+	}
+	if err != nil {
+		writeDBError(w, span, err, "категория не найдена")
+		return
+	}
+	s.categoryRatings.Invalidate()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getCategoryRatingHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса getCategoryRating")
+	span.AddEvent("Обработка запроса getCategoryRating")
+
+	res, err := s.categoryRatings.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeDBError(w, span, err, "категория не найдена")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/sentiment/lexicon"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addCategory создает категорию и возвращает ее идентификатор.
+func addCategory(t *testing.T, s *Server, name, parentID string) string {
+	t.Helper()
+
+	rec := do(s, http.MethodPost, "/categories", `{"name":"`+name+`","parent_id":"`+parentID+`"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	return decode[models.Category](t, rec).ID
+}
+
+func TestCategories(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := do(s, http.MethodPost, "/categories", `{"name":" "}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = do(s, http.MethodPost, "/categories", `{"name":"Чайники","parent_id":"unknown"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	appliances := addCategory(t, s, "Техника", "")
+	kettles := addCategory(t, s, "Чайники", appliances)
+
+	rec = do(s, http.MethodGet, "/categories", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, decode[[]models.Category](t, rec), 2)
+
+	// Категория не может стать подкатегорией своей подкатегории
+	rec = do(s, http.MethodPut, "/categories/"+appliances, `{"name":"Техника","parent_id":"`+kettles+`"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = do(s, http.MethodPut, "/categories/"+kettles, `{"name":"Электрочайники","parent_id":"`+appliances+`"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Электрочайники", decode[models.Category](t, rec).Name)
+
+	// Товар в категории
+	rec = do(s, http.MethodPost, "/products", `{"name":"Чайник","category_id":"unknown"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = do(s, http.MethodPost, "/products", `{"name":"Чайник","category_id":"`+kettles+`"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	product := decode[models.Product](t, rec)
+	assert.Equal(t, kettles, product.CategoryID)
+
+	rec = do(s, http.MethodPost, "/products/"+product.ID+"/reviews", `{"author":"Иван","text":"Отличный чайник","rating":5}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	review := decode[models.Review](t, rec)
+	_, err := jobs.NewPool(s.categoryRatings.Jobs(s.db.(*memdb.MemDB)), lexicon.New()).RunOnce(context.Background())
+	require.NoError(t, err)
+
+	rec = do(s, http.MethodGet, "/categories/"+appliances+"/rating", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	rating := decode[models.CategoryRating](t, rec)
+	assert.Equal(t, 1, rating.Products)
+	assert.Equal(t, 1, rating.Reviews)
+	require.Len(t, rating.Subcategories, 1)
+	assert.Equal(t, kettles, rating.Subcategories[0].CategoryID)
+
+	// Товар с отзывами не удаляется
+	rec = do(s, http.MethodDelete, "/products/"+product.ID, "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// Удаление отзыва сбрасывает кэш рейтинга
+	rec = do(s, http.MethodDelete, "/products/"+product.ID+"/reviews/"+review.ID, "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	rec = do(s, http.MethodGet, "/categories/"+appliances+"/rating", "")
+	assert.Zero(t, decode[models.CategoryRating](t, rec).Reviews)
+
+	// Перенос товара из категории
+	rec = do(s, http.MethodPut, "/products/"+product.ID, `{"name":"Чайник"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, decode[models.Product](t, rec).CategoryID)
+	rec = do(s, http.MethodGet, "/categories/"+appliances+"/rating", "")
+	assert.Zero(t, decode[models.CategoryRating](t, rec).Products)
+
+	// Категория с подкатегориями не удаляется
+	rec = do(s, http.MethodDelete, "/categories/"+appliances, "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = do(s, http.MethodDelete, "/categories/"+kettles, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = do(s, http.MethodGet, "/categories/"+kettles+"/rating", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = do(s, http.MethodDelete, "/products/"+product.ID, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = do(s, http.MethodGet, "/products/"+product.ID, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
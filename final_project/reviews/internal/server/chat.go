@@ -0,0 +1,119 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go-masters/final_project/reviews/internal/chat"
+	"go-masters/final_project/reviews/internal/db"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sessionInput - тело запроса на создание сессии.
+type sessionInput struct {
+	System string `json:"system"`
+}
+
+// messageInput - тело запроса с сообщением пользователя.
+type messageInput struct {
+	Content string `json:"content"`
+}
+
+func (s *Server) startChatHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса startChat")
+	span.AddEvent("Обработка запроса startChat")
+
+	if s.chat == nil {
+		writeError(w, http.StatusServiceUnavailable, "LLM не настроена")
+		return
+	}
+
+	var in sessionInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		span.SetStatus(codes.Error, "не удалось декодировать запрос")
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sess, err := s.chat.Start(r.Context(), in.System)
+	if errors.Is(err, chat.ErrInvalidMessage) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeDBError(w, span, err, "")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, sess)
+}
+
+func (s *Server) getChatHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса getChat")
+	span.AddEvent("Обработка запроса getChat")
+
+	if s.chat == nil {
+		writeError(w, http.StatusServiceUnavailable, "LLM не настроена")
+		return
+	}
+
+	sess, err := s.chat.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeDBError(w, span, err, "сессия не найдена")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sess)
+}
+
+// sendChatHandler передает ответ модели на сообщение в формате
+// Server-Sent Events, как generateHandler. Последнее событие done
+// содержит сохраненный ответ и статистику генерации.
+func (s *Server) sendChatHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса sendChat")
+	span.AddEvent("Обработка запроса sendChat")
+
+	if s.chat == nil {
+		writeError(w, http.StatusServiceUnavailable, "LLM не настроена")
+		return
+	}
+
+	var in messageInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		span.SetStatus(codes.Error, "не удалось декодировать запрос")
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sse := newSSEWriter(w)
+	reply, err := s.chat.Send(ctx, chi.URLParam(r, "id"), in.Content, func(chunk string) error {
+		return sse.Send("chunk", generateChunk{Response: chunk})
+	})
+	switch {
+	case err == nil:
+		sse.Send("done", reply)
+	case errors.Is(err, db.ErrNotFound):
+		writeError(w, http.StatusNotFound, "сессия не найдена")
+	case errors.Is(err, chat.ErrInvalidMessage):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, chat.ErrConflict) && !sse.Started():
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeStreamError(ctx, w, sse, span, err)
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"go-masters/final_project/reviews/internal/chat"
+	"go-masters/final_project/reviews/internal/llm/ollamatest"
+
+	"github.com/ollama/ollama/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChat(t *testing.T) {
+	ollama := ollamatest.NewServer(func(api.GenerateRequest) (string, error) {
+		return "", errors.New("unexpected generate")
+	})
+	ollama.HandleChat(func(req api.ChatRequest) (string, error) {
+		return "Доставка занимает два дня", nil
+	})
+	defer ollama.Close()
+	ts := newLLMServer(t, ollama)
+	ctx := context.Background()
+
+	resp := post(t, ctx, ts.URL+"/chat/sessions", `{"system":"Ты консультант"}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var sess chat.Session
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&sess))
+	assert.Equal(t, "Ты консультант", sess.System)
+
+	resp = post(t, ctx, ts.URL+"/chat/sessions/"+sess.ID+"/messages", `{"content":"Сколько ждать доставку?"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	events := readEvents(t, resp)
+	require.NotEmpty(t, events)
+	assert.Equal(t, "chunk", events[0].Name)
+
+	done := events[len(events)-1]
+	require.Equal(t, "done", done.Name)
+	var reply chat.Reply
+	require.NoError(t, json.Unmarshal([]byte(done.Data), &reply))
+	assert.Equal(t, "Доставка занимает два дня", reply.Message.Content)
+
+	// Сессия доступна по идентификатору вместе с историей
+	resp, err := http.Get(ts.URL + "/chat/sessions/" + sess.ID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&sess))
+	require.Len(t, sess.Messages, 2)
+	assert.Equal(t, "Сколько ждать доставку?", sess.Messages[0].Content)
+
+	resp = post(t, ctx, ts.URL+"/chat/sessions/"+sess.ID+"/messages", `{"content":""}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = post(t, ctx, ts.URL+"/chat/sessions/unknown/messages", `{"content":"привет"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go-masters/final_project/reviews/internal/db"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// errorResponse - тело ответа с ошибкой.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeError записывает ошибку в ответ в формате JSON.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: msg})
+}
+
+// writeDBError записывает ответ для ошибки хранилища.
+// notFound - сообщение для отсутствующей записи.
+func writeDBError(w http.ResponseWriter, span trace.Span, err error, notFound string) {
+	if errors.Is(err, db.ErrNotFound) {
+		writeError(w, http.StatusNotFound, notFound)
+		return
+	}
+	span.SetStatus(codes.Error, err.Error())
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
+
+// writeJSON записывает ответ в формате JSON.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"go-masters/final_project/reviews/internal/llm"
+
+	"github.com/ollama/ollama/api"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxPromptLen - максимальная длина запроса генерации в символах.
+const maxPromptLen = 8000
+
+// generateInput - тело запроса на генерацию.
+type generateInput struct {
+	Prompt string `json:"prompt"`
+	System string `json:"system"`
+}
+
+// generateChunk - фрагмент ответа модели (событие chunk).
+type generateChunk struct {
+	Response string `json:"response"`
+}
+
+// generateStats - статистика генерации (событие done).
+type generateStats struct {
+	Model                string `json:"model"`
+	DoneReason           string `json:"done_reason"`
+	PromptEvalCount      int    `json:"prompt_eval_count"`
+	EvalCount            int    `json:"eval_count"`
+	TotalDurationMs      int64  `json:"total_duration_ms"`
+	LoadDurationMs       int64  `json:"load_duration_ms"`
+	PromptEvalDurationMs int64  `json:"prompt_eval_duration_ms"`
+	EvalDurationMs       int64  `json:"eval_duration_ms"`
+}
+
+// generateHandler передает ответ модели клиенту по мере генерации
+// в формате Server-Sent Events:
+//
+//	event: chunk - очередной фрагмент ответа;
+//	event: done  - статистика генерации, последнее событие;
+//	event: error - ошибка после начала передачи.
+//
+// Фрагменты пишутся клиенту синхронно, поэтому медленный клиент
+// замедляет чтение ответа Ollama. Отключение клиента отменяет
+// контекст запроса, а вместе с ним и запрос к Ollama.
+func (s *Server) generateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса generate")
+	span.AddEvent("Обработка запроса generate")
+
+	if s.llm == nil {
+		writeError(w, http.StatusServiceUnavailable, "LLM не настроена")
+		return
+	}
+
+	var in generateInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		span.SetStatus(codes.Error, "не удалось декодировать запрос")
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if strings.TrimSpace(in.Prompt) == "" {
+		writeError(w, http.StatusBadRequest, "не указан запрос")
+		return
+	}
+	if utf8.RuneCountInString(in.Prompt)+utf8.RuneCountInString(in.System) > maxPromptLen {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("запрос длиннее %d символов", maxPromptLen))
+		return
+	}
+
+	sse := newSSEWriter(w)
+	req := &api.GenerateRequest{
+		Model:  s.cfg.LLM.Model,
+		Prompt: in.Prompt,
+		System: in.System,
+	}
+	err := s.llm.Generate(ctx, req, func(resp api.GenerateResponse) error {
+		if resp.Response != "" {
+			if err := sse.Send("chunk", generateChunk{Response: resp.Response}); err != nil {
+				return err
+			}
+		}
+		if !resp.Done {
+			return nil
+		}
+		return sse.Send("done", generateStats{
+			Model:                resp.Model,
+			DoneReason:           resp.DoneReason,
+			PromptEvalCount:      resp.PromptEvalCount,
+			EvalCount:            resp.EvalCount,
+			TotalDurationMs:      resp.TotalDuration.Milliseconds(),
+			LoadDurationMs:       resp.LoadDuration.Milliseconds(),
+			PromptEvalDurationMs: resp.PromptEvalDuration.Milliseconds(),
+			EvalDurationMs:       resp.EvalDuration.Milliseconds(),
+		})
+	})
+	if err != nil {
+		writeStreamError(ctx, w, sse, span, err)
+	}
+}
+
+// writeStreamError сообщает клиенту об ошибке генерации: до начала передачи
+// обычным ответом, после - событием error.
+func writeStreamError(ctx context.Context, w http.ResponseWriter, sse *sseWriter, span trace.Span, err error) {
+	if ctx.Err() != nil {
+		log.Info().Err(err).Msg("Клиент отключился во время генерации")
+		return
+	}
+	log.Error().Err(err).Msg("Ошибка генерации")
+	span.SetStatus(codes.Error, err.Error())
+
+	if !sse.Started() {
+		status := http.StatusBadGateway
+		if errors.Is(err, llm.ErrCircuitOpen) {
+			status = http.StatusServiceUnavailable
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	sse.Send("error", errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-masters/final_project/reviews/internal/catalog"
+	"go-masters/final_project/reviews/internal/chat"
+	"go-masters/final_project/reviews/internal/config"
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/llm"
+	"go-masters/final_project/reviews/internal/llm/ollamatest"
+	"go-masters/final_project/reviews/internal/webhook"
+
+	"github.com/ollama/ollama/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseEvent - событие потока Server-Sent Events.
+type sseEvent struct {
+	Name string
+	Data string
+}
+
+// newLLMServer запускает HTTP сервер, использующий поддельный сервер Ollama.
+func newLLMServer(t *testing.T, ollama *ollamatest.Server) *httptest.Server {
+	t.Helper()
+
+	lc, err := llm.New(llm.Config{Endpoint: ollama.URL, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+
+	m := memdb.New()
+	cfg := &config.Cfg{LLM: config.LLM{Model: "test"}}
+	s := New(cfg, m, nil, catalog.NewRatings(m, catalog.DefaultTTL), webhook.NewDispatcher(m), lc, chat.New(m, lc, chat.Config{Model: "test"}), nil)
+	t.Cleanup(s.stopBg)
+
+	ts := httptest.NewServer(s.router)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func post(t *testing.T, ctx context.Context, url, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// readEvents читает события потока до его завершения.
+func readEvents(t *testing.T, resp *http.Response) []sseEvent {
+	t.Helper()
+
+	var (
+		events []sseEvent
+		ev     sseEvent
+	)
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			events = append(events, ev)
+			ev = sseEvent{}
+		case strings.HasPrefix(line, "event: "):
+			ev.Name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			ev.Data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	require.NoError(t, sc.Err())
+	return events
+}
+
+func TestGenerateStream(t *testing.T) {
+	ollama := ollamatest.NewServer(func(req api.GenerateRequest) (string, error) {
+		return "Отзыв написан понятно", nil
+	})
+	defer ollama.Close()
+	ts := newLLMServer(t, ollama)
+
+	resp := post(t, context.Background(), ts.URL+"/llm/generate", `{"prompt":"Перескажи отзыв","system":"Отвечай кратко"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := readEvents(t, resp)
+	require.Len(t, events, 4)
+
+	var text strings.Builder
+	for _, ev := range events[:3] {
+		require.Equal(t, "chunk", ev.Name)
+		var chunk generateChunk
+		require.NoError(t, json.Unmarshal([]byte(ev.Data), &chunk))
+		text.WriteString(chunk.Response)
+	}
+	assert.Equal(t, "Отзыв написан понятно", text.String())
+
+	// Статистика генерации передается последним событием
+	done := events[3]
+	require.Equal(t, "done", done.Name)
+	var stats generateStats
+	require.NoError(t, json.Unmarshal([]byte(done.Data), &stats))
+	assert.Equal(t, "test", stats.Model)
+	assert.Equal(t, "stop", stats.DoneReason)
+	assert.Equal(t, 2, stats.PromptEvalCount)
+	assert.Equal(t, 3, stats.EvalCount)
+	assert.Equal(t, int64(1), stats.TotalDurationMs)
+
+	reqs := ollama.Requests()
+	require.Len(t, reqs, 1)
+	assert.Equal(t, "test", reqs[0].Model)
+	assert.Equal(t, "Отвечай кратко", reqs[0].System)
+}
+
+func TestGenerateDisconnect(t *testing.T) {
+	ollama := ollamatest.NewServer(func(req api.GenerateRequest) (string, error) {
+		return strings.Repeat("слово ", 100), nil
+	})
+	ollama.ChunkDelay = 10 * time.Millisecond
+	defer ollama.Close()
+	ts := newLLMServer(t, ollama)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp := post(t, ctx, ts.URL+"/llm/generate", `{"prompt":"Расскажи длинную историю"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Клиент отключается после первого фрагмента
+	_, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	cancel()
+
+	// Отключение клиента прерывает запрос к Ollama
+	assert.Eventually(t, func() bool {
+		return ollama.Canceled() == 1
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	ollama := ollamatest.NewServer(func(req api.GenerateRequest) (string, error) {
+		return "", errors.New("model not found")
+	})
+	defer ollama.Close()
+	ts := newLLMServer(t, ollama)
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "пустой запрос", body: `{"prompt":" "}`, want: http.StatusBadRequest},
+		{name: "длинный запрос", body: `{"prompt":"` + strings.Repeat("а", maxPromptLen+1) + `"}`, want: http.StatusBadRequest},
+		{name: "ошибка Ollama", body: `{"prompt":"привет"}`, want: http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := post(t, context.Background(), ts.URL+"/llm/generate", tt.body)
+			assert.Equal(t, tt.want, resp.StatusCode)
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		})
+	}
+}
+
+func TestGenerateWithoutLLM(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := do(s, http.MethodPost, "/llm/generate", `{"prompt":"привет"}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/metrics"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// rejectedResponse - ответ на отзыв, отклоненный автоматической проверкой.
+type rejectedResponse struct {
+	Error   string   `json:"error"`
+	Reasons []string `json:"reasons"`
+}
+
+// moderate проверяет текст отзыва. Отклоненный отзыв не сохраняется:
+// в нем могут быть персональные данные, поэтому автор получает ответ
+// 422 с причинами и может исправить текст. Без автоматической проверки
+// отзыв публикуется.
+func (s *Server) moderate(w http.ResponseWriter, r *http.Request, text string) (models.Moderation, bool) {
+	if s.moderator == nil {
+		return models.Moderation{Status: models.ReviewPublished}, true
+	}
+
+	span := trace.SpanFromContext(r.Context())
+	v := s.moderator.Moderate(r.Context(), text)
+	span.SetAttributes(
+		attribute.String("moderation.status", v.Status),
+		attribute.StringSlice("moderation.reasons", v.Reasons),
+	)
+	metrics.ObserveModeration(models.AutoModerator, v.Status)
+
+	if v.Status == models.ReviewRejected {
+		log.Info().Strs("reasons", v.Reasons).Msg("Отзыв отклонен автоматической проверкой")
+		writeJSON(w, http.StatusUnprocessableEntity, rejectedResponse{
+			Error:   "отзыв нарушает правила публикации",
+			Reasons: v.Reasons,
+		})
+		return models.Moderation{}, false
+	}
+	return v.Moderation(), true
+}
+
+// Число отзывов в ответе очереди модератора по умолчанию и максимальное.
+const (
+	defaultQueueLimit = 20
+	maxQueueLimit     = 100
+)
+
+func (s *Server) moderationQueueHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса moderationQueue")
+	span.AddEvent("Обработка запроса moderationQueue")
+
+	limit := defaultQueueLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxQueueLimit {
+			writeError(w, http.StatusBadRequest, "limit должен быть от 1 до "+strconv.Itoa(maxQueueLimit))
+			return
+		}
+		limit = n
+	}
+
+	reviews, err := s.db.ModerationQueue(r.Context(), limit)
+	if err != nil {
+		writeDBError(w, span, err, "")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reviews)
+}
+
+// decisionInput - решение модератора.
+type decisionInput struct {
+	// Статус: published или rejected.
+	Status    string `json:"status"`
+	Moderator string `json:"moderator"`
+	Reason    string `json:"reason"`
+}
+
+func (s *Server) moderateHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса moderate")
+	span.AddEvent("Обработка запроса moderate")
+
+	var req decisionInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		span.SetStatus(codes.Error, "не удалось декодировать запрос")
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Status != models.ReviewPublished && req.Status != models.ReviewRejected {
+		writeError(w, http.StatusBadRequest, "status должен быть published или rejected")
+		return
+	}
+	req.Moderator = strings.TrimSpace(req.Moderator)
+	if req.Moderator == "" || req.Moderator == models.AutoModerator {
+		writeError(w, http.StatusBadRequest, "не указан модератор")
+		return
+	}
+
+	now := time.Now().UTC()
+	m := models.Moderation{Status: req.Status, Moderator: req.Moderator, ModeratedAt: &now}
+	if reason := strings.TrimSpace(req.Reason); reason != "" {
+		m.Reasons = []string{reason}
+	}
+
+	review, err := s.db.Moderate(r.Context(), chi.URLParam(r, "id"), m)
+	if errors.Is(err, db.ErrConflict) {
+		writeError(w, http.StatusConflict, "отзыв не ожидает модерации")
+		return
+	}
+	if err != nil {
+		writeDBError(w, span, err, "отзыв не найден")
+		return
+	}
+	metrics.ObserveModeration("manual", req.Status)
+
+	writeJSON(w, http.StatusOK, review)
+}
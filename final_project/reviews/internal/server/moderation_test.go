@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/moderation"
+	"go-masters/final_project/reviews/internal/sentiment/lexicon"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModeration(t *testing.T) {
+	s := newTestServer(t)
+	pid := addProduct(t, s, "Чайник")
+	base := "/products/" + pid + "/reviews"
+
+	// Нецензурный отзыв отклоняется и не сохраняется
+	rec := do(s, http.MethodPost, base, `{"author":"A","text":"Fucking чайник","rating":1}`)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	rejected := decode[rejectedResponse](t, rec)
+	assert.Equal(t, []string{moderation.ReasonProfanity}, rejected.Reasons)
+
+	// Отзыв с телефоном ждет модератора
+	rec = do(s, http.MethodPost, base, `{"author":"A","text":"Отличный чайник, звоните +7 912 345-67-89","rating":5}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	held := decode[models.Review](t, rec)
+	assert.Equal(t, models.ReviewPending, held.Moderation.Status)
+	assert.Equal(t, []string{moderation.ReasonPhone}, held.Moderation.Reasons)
+
+	rec = do(s, http.MethodPost, base, `{"author":"B","text":"Хороший чайник","rating":4}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, models.ReviewPublished, decode[models.Review](t, rec).Moderation.Status)
+
+	// Неопубликованный отзыв не виден в списке и по ссылке и не классифицируется
+	rec = do(s, http.MethodGet, base, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, decode[[]models.Review](t, rec), 1)
+	rec = do(s, http.MethodGet, base+"/"+held.ID, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	n, err := jobs.NewPool(s.db.(*memdb.MemDB), lexicon.New()).RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	rec = doAdmin(s, http.MethodGet, "/admin/moderation", "", adminToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+	queue := decode[[]models.Review](t, rec)
+	require.Len(t, queue, 1)
+	assert.Equal(t, held.ID, queue[0].ID)
+
+	rec = doAdmin(s, http.MethodPost, "/admin/moderation/"+held.ID, `{"status":"deleted","moderator":"anna"}`, adminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doAdmin(s, http.MethodPost, "/admin/moderation/"+held.ID, `{"status":"published"}`, adminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doAdmin(s, http.MethodPost, "/admin/moderation/"+held.ID, `{"status":"published","moderator":"anna"}`, adminToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	published := decode[models.Review](t, rec)
+	assert.Equal(t, models.ReviewPublished, published.Moderation.Status)
+	assert.Equal(t, "anna", published.Moderation.Moderator)
+
+	// Повторное решение по отзыву невозможно
+	rec = doAdmin(s, http.MethodPost, "/admin/moderation/"+held.ID, `{"status":"rejected","moderator":"anna"}`, adminToken)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// Опубликованный модератором отзыв классифицируется и виден в списке
+	n, err = jobs.NewPool(s.db.(*memdb.MemDB), lexicon.New()).RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	rec = do(s, http.MethodGet, base, "")
+	assert.Len(t, decode[[]models.Review](t, rec), 2)
+	rec = do(s, http.MethodGet, base+"/"+held.ID, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Изменение текста проверяется заново
+	rec = do(s, http.MethodPut, base+"/"+held.ID, `{"author":"A","text":"Пишите на a@b.ru","rating":5}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	rec = do(s, http.MethodPut, base+"/"+held.ID, `{"author":"A","text":"Продавец идиот","rating":1}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, models.ReviewPending, decode[models.Review](t, rec).Moderation.Status)
+
+	rec = doAdmin(s, http.MethodPost, "/admin/moderation/unknown", `{"status":"rejected","moderator":"anna"}`, adminToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = doAdmin(s, http.MethodGet, "/admin/moderation?limit=0", "", adminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestUpdateReviewModeration(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	pid := addProduct(t, s, "Чайник")
+
+	rec := do(s, http.MethodPost, "/products/"+pid+"/reviews", `{"author":"A","text":"Хороший чайник","rating":4}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	review := decode[models.Review](t, rec)
+
+	// Изменение по устаревшему тексту конфликтует с параллельным изменением.
+	edited := review
+	edited.Text = "Отличный чайник"
+	edited.Moderation = models.Moderation{}
+	_, err := s.db.UpdateReview(ctx, edited, "Плохой чайник")
+	assert.ErrorIs(t, err, db.ErrConflict)
+
+	// Новый текст без результата модерации не публикуется.
+	got, err := s.db.UpdateReview(ctx, edited, review.Text)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReviewPending, got.Moderation.Status)
+
+	// Для прежнего текста сохраняется прежний результат модерации.
+	edited.Author = "B"
+	edited.Moderation = models.Moderation{Status: models.ReviewPublished}
+	got, err = s.db.UpdateReview(ctx, edited, edited.Text)
+	require.NoError(t, err)
+	assert.Equal(t, "B", got.Author)
+	assert.Equal(t, models.ReviewPending, got.Moderation.Status)
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/rating"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// productInput - тело запроса на создание или изменение товара.
+type productInput struct {
+	Name       string `json:"name"`
+	CategoryID string `json:"category_id"`
+}
+
+// decodeProduct декодирует и проверяет тело запроса с товаром.
+func decodeProduct(w http.ResponseWriter, r *http.Request, span trace.Span) (productInput, bool) {
+	var req productInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		span.SetStatus(codes.Error, "не удалось декодировать запрос")
+		writeError(w, http.StatusBadRequest, err.Error())
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "не указано название товара")
+		return req, false
+	}
+	return req, true
+}
+
+func (s *Server) addProductHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса addProduct")
+	span.AddEvent("Обработка запроса addProduct")
+
+	req, ok := decodeProduct(w, r, span)
+	if !ok {
+		return
+	}
+
+	p, err := s.db.AddProduct(r.Context(), models.Product{Name: req.Name, CategoryID: req.CategoryID})
+	if err != nil {
+		writeDBError(w, span, err, "категория не найдена")
+		return
+	}
+	if p.CategoryID != "" {
+		s.categoryRatings.Invalidate()
+	}
+
+	writeJSON(w, http.StatusCreated, p)
+}
+
+func (s *Server) listProductsHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса listProducts")
+	span.AddEvent("Обработка запроса listProducts")
+
+	products, err := s.db.ListProducts(r.Context())
+	if err != nil {
+		writeDBError(w, span, err, "")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, products)
+}
+
+func (s *Server) getProductHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса getProduct")
+	span.AddEvent("Обработка запроса getProduct")
+
+	p, err := s.db.GetProduct(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeDBError(w, span, err, "товар не найден")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) updateProductHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса updateProduct")
+	span.AddEvent("Обработка запроса updateProduct")
+
+	req, ok := decodeProduct(w, r, span)
+	if !ok {
+		return
+	}
+
+	p, err := s.db.UpdateProduct(r.Context(), models.Product{
+		ID:         chi.URLParam(r, "id"),
+		Name:       req.Name,
+		CategoryID: req.CategoryID,
+	})
+	if err != nil {
+		writeDBError(w, span, err, "товар или категория не найдены")
+		return
+	}
+	s.categoryRatings.Invalidate()
+
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) deleteProductHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса deleteProduct")
+	span.AddEvent("Обработка запроса deleteProduct")
+
+	err := s.db.DeleteProduct(r.Context(), chi.URLParam(r, "id"))
+	if errors.Is(err, db.ErrConflict) {
+		writeError(w, http.StatusConflict, "у товара есть отзывы")
+		return
+	}
+	if err != nil {
+		writeDBError(w, span, err, "товар не найден")
+		return
+	}
+	s.categoryRatings.Invalidate()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getProductRatingHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса getProductRating")
+	span.AddEvent("Обработка запроса getProductRating")
+
+	id := chi.URLParam(r, "id")
+	agg, err := s.db.ProductRating(r.Context(), id)
+	if err != nil {
+		writeDBError(w, span, err, "товар не найден")
+		return
+	}
+
+	aspects, err := s.db.AspectRatings(r.Context(), id)
+	if err != nil {
+		writeDBError(w, span, err, "товар не найден")
+		return
+	}
+
+	languages, err := s.db.LanguageRatings(r.Context(), id)
+	if err != nil {
+		writeDBError(w, span, err, "товар не найден")
+		return
+	}
+
+	now := time.Now()
+	res := rating.Compute(id, agg, now)
+	res.Aspects = rating.ComputeGroups(aspects, now)
+	res.Languages = rating.ComputeGroups(languages, now)
+	writeJSON(w, http.StatusOK, res)
+}
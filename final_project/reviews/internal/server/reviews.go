@@ -0,0 +1,285 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/helpful"
+	"go-masters/final_project/reviews/internal/language"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// errInvalidLanguage - сообщение о некорректном параметре language.
+const errInvalidLanguage = "language должен быть кодом языка ISO 639-1 или und"
+
+// reviewInput - тело запроса на создание или изменение отзыва.
+type reviewInput struct {
+	Author string `json:"author"`
+	Text   string `json:"text"`
+	Rating int    `json:"rating"`
+}
+
+func (in *reviewInput) validate() error {
+	in.Author = strings.TrimSpace(in.Author)
+	in.Text = strings.TrimSpace(in.Text)
+
+	return models.Review{Author: in.Author, Text: in.Text, Rating: in.Rating}.Validate()
+}
+
+// decodeReview декодирует и проверяет тело запроса с отзывом.
+func decodeReview(w http.ResponseWriter, r *http.Request, span trace.Span) (reviewInput, bool) {
+	var req reviewInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		span.SetStatus(codes.Error, "не удалось декодировать запрос")
+		writeError(w, http.StatusBadRequest, err.Error())
+		return req, false
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return req, false
+	}
+	return req, true
+}
+
+func (s *Server) addReviewHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса addReview")
+	span.AddEvent("Обработка запроса addReview")
+
+	req, ok := decodeReview(w, r, span)
+	if !ok {
+		return
+	}
+	mod, ok := s.moderate(w, r, req.Text)
+	if !ok {
+		return
+	}
+
+	review, err := s.db.AddReview(r.Context(), models.Review{
+		ProductID:  chi.URLParam(r, "id"),
+		Author:     req.Author,
+		Text:       req.Text,
+		Rating:     req.Rating,
+		Language:   language.Detect(req.Text),
+		Moderation: mod,
+	})
+	if err != nil {
+		writeDBError(w, span, err, "товар не найден")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, review)
+}
+
+func (s *Server) listReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса listReviews")
+	span.AddEvent("Обработка запроса listReviews")
+
+	order := r.URL.Query().Get("sort")
+	if !helpful.ValidSort(order) {
+		writeError(w, http.StatusBadRequest, "sort должен быть helpful или recent")
+		return
+	}
+
+	lang := r.URL.Query().Get("language")
+	if lang != "" && !language.Valid(lang) {
+		writeError(w, http.StatusBadRequest, errInvalidLanguage)
+		return
+	}
+
+	reviews, err := s.db.ListReviews(r.Context(), chi.URLParam(r, "id"), lang)
+	if err != nil {
+		writeDBError(w, span, err, "товар не найден")
+		return
+	}
+	helpful.Sort(reviews, order, time.Now())
+
+	writeJSON(w, http.StatusOK, reviews)
+}
+
+func (s *Server) getReviewHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса getReview")
+	span.AddEvent("Обработка запроса getReview")
+
+	review, err := s.db.GetReview(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "reviewID"))
+	if err != nil {
+		writeDBError(w, span, err, "отзыв не найден")
+		return
+	}
+	// Неопубликованные отзывы видны только в очереди модератора.
+	if review.Moderation.Status != models.ReviewPublished {
+		writeError(w, http.StatusNotFound, "отзыв не найден")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, review)
+}
+
+func (s *Server) updateReviewHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса updateReview")
+	span.AddEvent("Обработка запроса updateReview")
+
+	req, ok := decodeReview(w, r, span)
+	if !ok {
+		return
+	}
+
+	productID, id := chi.URLParam(r, "id"), chi.URLParam(r, "reviewID")
+	old, err := s.db.GetReview(r.Context(), productID, id)
+	if err != nil {
+		writeDBError(w, span, err, "отзыв не найден")
+		return
+	}
+	// Хранилище сохраняет результат модерации, только если текст изменился,
+	// но проверяется текст всегда: решение о модерации по отзыву, прочитанному
+	// без блокировки, могло устареть.
+	mod, ok := s.moderate(w, r, req.Text)
+	if !ok {
+		return
+	}
+
+	// Язык, как и результат модерации, сохраняется только для нового текста.
+	review, err := s.db.UpdateReview(r.Context(), models.Review{
+		ID:         id,
+		ProductID:  productID,
+		Author:     req.Author,
+		Text:       req.Text,
+		Rating:     req.Rating,
+		Language:   language.Detect(req.Text),
+		Moderation: mod,
+	}, old.Text)
+	if errors.Is(err, db.ErrConflict) {
+		writeError(w, http.StatusConflict, "отзыв одновременно изменен другим запросом")
+		return
+	}
+	if err != nil {
+		writeDBError(w, span, err, "отзыв не найден")
+		return
+	}
+	// Измененный текст сбрасывает настроение и вклад отзыва в рейтинг.
+	if old.Text != review.Text {
+		s.categoryRatings.Invalidate()
+	}
+
+	writeJSON(w, http.StatusOK, review)
+}
+
+func (s *Server) deleteReviewHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса deleteReview")
+	span.AddEvent("Обработка запроса deleteReview")
+
+	err := s.db.DeleteReview(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "reviewID"))
+	if err != nil {
+		writeDBError(w, span, err, "отзыв не найден")
+		return
+	}
+	s.categoryRatings.Invalidate()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Число похожих отзывов в ответе по умолчанию и максимальное.
+const (
+	defaultSimilarLimit = 10
+	maxSimilarLimit     = 50
+)
+
+func (s *Server) similarReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса similarReviews")
+	span.AddEvent("Обработка запроса similarReviews")
+
+	limit := defaultSimilarLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSimilarLimit {
+			writeError(w, http.StatusBadRequest, "limit должен быть от 1 до "+strconv.Itoa(maxSimilarLimit))
+			return
+		}
+		limit = n
+	}
+
+	similar, err := s.db.SimilarReviews(r.Context(), chi.URLParam(r, "id"), limit)
+	if errors.Is(err, db.ErrNoEmbedding) {
+		writeError(w, http.StatusConflict, "отзыв еще не проиндексирован")
+		return
+	}
+	if err != nil {
+		writeDBError(w, span, err, "отзыв не найден")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, similar)
+}
+
+// Число отзывов в ответе поиска по умолчанию и максимальное.
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+func (s *Server) searchReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса searchReviews")
+	span.AddEvent("Обработка запроса searchReviews")
+
+	query := r.URL.Query()
+	q := db.SearchQuery{
+		Text:      strings.TrimSpace(query.Get("q")),
+		ProductID: query.Get("product_id"),
+		Language:  query.Get("language"),
+		Limit:     defaultSearchLimit,
+	}
+	if q.Text == "" {
+		writeError(w, http.StatusBadRequest, "не указан запрос q")
+		return
+	}
+	if q.Language != "" && !language.Valid(q.Language) {
+		writeError(w, http.StatusBadRequest, errInvalidLanguage)
+		return
+	}
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSearchLimit {
+			writeError(w, http.StatusBadRequest, "limit должен быть от 1 до "+strconv.Itoa(maxSearchLimit))
+			return
+		}
+		q.Limit = n
+	}
+
+	results, err := s.db.SearchReviews(r.Context(), q)
+	if err != nil {
+		writeDBError(w, span, err, "")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
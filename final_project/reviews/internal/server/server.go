@@ -0,0 +1,259 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"net/netip"
+	"time"
+
+	"go-masters/final_project/reviews/internal/catalog"
+	"go-masters/final_project/reviews/internal/chat"
+	"go-masters/final_project/reviews/internal/config"
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/helpful"
+	"go-masters/final_project/reviews/internal/llm"
+	"go-masters/final_project/reviews/internal/metrics"
+	"go-masters/final_project/reviews/internal/moderation"
+	"go-masters/final_project/reviews/internal/reclassify"
+	"go-masters/final_project/reviews/internal/telemetry"
+	"go-masters/final_project/reviews/internal/webhook"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Server struct {
+	cfg    *config.Cfg
+	router *chi.Mux
+	server *http.Server
+	db     db.DB
+
+	reclassify *reclassify.Runner
+	// Рейтинги категорий с кэшем, сбрасываемым при изменении отзывов.
+	categoryRatings *catalog.Ratings
+	// Подписки на события и их доставки.
+	webhooks *webhook.Dispatcher
+	// Проверка отзывов перед публикацией; nil - отзывы публикуются без проверки.
+	moderator *moderation.Moderator
+	// Ограничение частоты голосов за полезность с одного клиента.
+	voteLimiter *helpful.Limiter
+	// Прокси, которым доверяется адрес клиента из X-Forwarded-For.
+	trustedProxies []netip.Prefix
+	// Клиент Ollama и диалоги с ним; nil, если LLM не используется.
+	llm  *llm.Client
+	chat *chat.Service
+	// Контекст фоновых задач сервера, отменяется при остановке.
+	bg     context.Context
+	stopBg context.CancelFunc
+}
+
+func New(cfg *config.Cfg, db db.DB, rc *reclassify.Runner, cr *catalog.Ratings, wh *webhook.Dispatcher, lc *llm.Client, cs *chat.Service, mod *moderation.Moderator) *Server {
+	r := chi.NewRouter()
+	bg, stopBg := context.WithCancel(context.Background())
+
+	s := Server{
+		cfg:    cfg,
+		router: r,
+		server: &http.Server{
+			Addr:         fmt.Sprintf(":%v", cfg.Port),
+			Handler:      r,
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 10 * time.Second,
+			IdleTimeout:  15 * time.Second,
+		},
+		db:              db,
+		reclassify:      rc,
+		categoryRatings: cr,
+		webhooks:        wh,
+		moderator:       mod,
+		voteLimiter:     helpful.NewLimiter(cfg.Votes.RateLimit, cfg.Votes.RateWindow),
+		llm:             lc,
+		chat:            cs,
+		bg:              bg,
+		stopBg:          stopBg,
+	}
+
+	proxies, err := parseProxies(cfg.Votes.TrustedProxies)
+	if err != nil {
+		// Без доверенных прокси адрес клиента берется из соединения,
+		// подделать его заголовком нельзя.
+		log.Error().Err(err).Msg("Ошибка в votes.trusted_proxies, прокси не учитываются")
+	}
+	s.trustedProxies = proxies
+
+	s.endpoints()
+
+	return &s
+}
+
+func (s *Server) endpoints() {
+	// Настройка middleware
+	s.router.Use(
+		middleware.RequestID,                 // Добавляет X-Request-Id в заголовки
+		telemetry.TracingMiddleware,          // OpenTelemetry трейсинг
+		metrics.PrometheusMiddleware,         // Метрики Prometheus
+		RequestLoggerMiddleware(&log.Logger), // Логирование запросов
+		middleware.Recoverer,                 // Восстановление после паник
+	)
+
+	// Эндпоинты pprof
+	// http://localhost:8080/debug/pprof/
+	s.router.Get("/debug/pprof/", pprof.Index)
+	s.router.Get("/debug/pprof/cmdline", pprof.Cmdline)
+	s.router.Get("/debug/pprof/profile", pprof.Profile)
+	s.router.Get("/debug/pprof/symbol", pprof.Symbol)
+	s.router.Get("/debug/pprof/trace", pprof.Trace)
+	s.router.Get("/debug/pprof/allocs", pprof.Handler("allocs").ServeHTTP)
+	s.router.Get("/debug/pprof/block", pprof.Handler("block").ServeHTTP)
+	s.router.Get("/debug/pprof/goroutine", pprof.Handler("goroutine").ServeHTTP)
+	s.router.Get("/debug/pprof/heap", pprof.Handler("heap").ServeHTTP)
+	s.router.Get("/debug/pprof/mutex", pprof.Handler("mutex").ServeHTTP)
+	s.router.Get("/debug/pprof/threadcreate", pprof.Handler("threadcreate").ServeHTTP)
+
+	// HealthCheck - статус системы
+	s.router.Get("/health", healthHandler)
+
+	// Эндпоинт для Prometheus
+	s.router.Get("/metrics", promhttp.Handler().ServeHTTP)
+
+	// Товары
+	s.router.Post("/products", s.addProductHandler)
+	s.router.Get("/products", s.listProductsHandler)
+	s.router.Get("/products/{id}", s.getProductHandler)
+	s.router.Put("/products/{id}", s.updateProductHandler)
+	s.router.Delete("/products/{id}", s.deleteProductHandler)
+	s.router.Get("/products/{id}/rating", s.getProductRatingHandler)
+	s.router.Get("/products/{id}/rating/history", s.getRatingHistoryHandler)
+
+	// Категории товаров
+	s.router.Route("/categories", func(r chi.Router) {
+		r.Post("/", s.addCategoryHandler)
+		r.Get("/", s.listCategoriesHandler)
+		r.Get("/{id}", s.getCategoryHandler)
+		r.Put("/{id}", s.updateCategoryHandler)
+		r.Delete("/{id}", s.deleteCategoryHandler)
+		r.Get("/{id}/rating", s.getCategoryRatingHandler)
+	})
+
+	// Отзывы о товаре
+	s.router.Route("/products/{id}/reviews", func(r chi.Router) {
+		r.Post("/", s.addReviewHandler)
+		r.Get("/", s.listReviewsHandler)
+		r.Get("/{reviewID}", s.getReviewHandler)
+		r.Put("/{reviewID}", s.updateReviewHandler)
+		r.Delete("/{reviewID}", s.deleteReviewHandler)
+		r.Put("/{reviewID}/vote", s.voteHandler)
+		r.Delete("/{reviewID}/vote", s.unvoteHandler)
+	})
+
+	// Похожие отзывы
+	s.router.Get("/reviews/{id}/similar", s.similarReviewsHandler)
+
+	// Полнотекстовый поиск отзывов
+	s.router.Get("/reviews/search", s.searchReviewsHandler)
+
+	// Импорт и выгрузка отзывов
+	s.router.With(s.adminOnly).Post("/reviews/import", s.importReviewsHandler)
+	s.router.With(s.adminOnly).Get("/reviews/export", s.exportReviewsHandler)
+
+	// Генерация ответа LLM
+	s.router.Post("/llm/generate", s.generateHandler)
+
+	// Диалоги с LLM
+	s.router.Route("/chat/sessions", func(r chi.Router) {
+		r.Post("/", s.startChatHandler)
+		r.Get("/{id}", s.getChatHandler)
+		r.Post("/{id}/messages", s.sendChatHandler)
+	})
+
+	// Административный API
+	s.router.Route("/admin", func(r chi.Router) {
+		r.Use(s.adminOnly)
+		r.Post("/reclassify", s.startReclassifyHandler)
+		r.Get("/reclassify/{id}", s.getReclassifyHandler)
+		r.Post("/reclassify/{id}/resume", s.resumeReclassifyHandler)
+		r.Get("/moderation", s.moderationQueueHandler)
+		r.Post("/moderation/{id}", s.moderateHandler)
+		r.Post("/webhooks", s.addWebhookHandler)
+		r.Get("/webhooks", s.listWebhooksHandler)
+		r.Get("/webhooks/{id}", s.getWebhookHandler)
+		r.Put("/webhooks/{id}", s.updateWebhookHandler)
+		r.Delete("/webhooks/{id}", s.deleteWebhookHandler)
+		r.Get("/webhooks/{id}/deliveries", s.listDeliveriesHandler)
+		r.Post("/webhooks/{id}/deliveries/{deliveryID}/replay", s.replayDeliveryHandler)
+	})
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	log.Info().Msg("Инициализация телеметрии")
+
+	shutdown, err := telemetry.SetupOTelSDK(ctx, s.cfg.OTelEndpoint)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = shutdown(ctx)
+		if err != nil {
+			log.Err(err).Msg("cannot shutdown OTel")
+		}
+	}()
+
+	log.Info().Str("addr", s.server.Addr).Msg("Запуск HTTP сервера")
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		log.Info().Msg("Остановка HTTP сервера")
+		s.stopBg()
+		if err := s.server.Shutdown(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("Ошибка при остановке сервера")
+		}
+	}()
+
+	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
+
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+// RequestLoggerMiddleware - middleware для логирования запросов
+func RequestLoggerMiddleware(logger *zerolog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			logger.Info().
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Str("remote_addr", r.RemoteAddr).
+				Str("user_agent", r.UserAgent()).
+				Int("status", ww.Status()).
+				Int("bytes", ww.BytesWritten()).
+				Dur("duration", time.Since(start)).
+				Str("request_id", middleware.GetReqID(r.Context())).
+				Msg("Обработан HTTP запрос")
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"go-masters/final_project/reviews/internal/catalog"
+	"go-masters/final_project/reviews/internal/config"
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/moderation"
+	"go-masters/final_project/reviews/internal/rating"
+	"go-masters/final_project/reviews/internal/reclassify"
+	"go-masters/final_project/reviews/internal/sentiment"
+	"go-masters/final_project/reviews/internal/sentiment/lexicon"
+	"go-masters/final_project/reviews/internal/similarity"
+	"go-masters/final_project/reviews/internal/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// adminToken - токен административного API тестового сервера.
+const adminToken = "secret"
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	m := memdb.New()
+	s := New(&config.Cfg{AdminToken: adminToken}, m, reclassify.NewRunner(m, lexicon.New()), catalog.NewRatings(m, catalog.DefaultTTL), webhook.NewDispatcher(m), nil, nil, moderation.Default())
+	t.Cleanup(s.stopBg)
+	return s
+}
+
+func do(s *Server, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var v T
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&v))
+	return v
+}
+
+// addProduct создает товар и возвращает его идентификатор.
+func addProduct(t *testing.T, s *Server, name string) string {
+	t.Helper()
+
+	rec := do(s, http.MethodPost, "/products", `{"name":"`+name+`"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	return decode[models.Product](t, rec).ID
+}
+
+func TestReviewsCRUD(t *testing.T) {
+	s := newTestServer(t)
+	pid := addProduct(t, s, "Чайник")
+	base := "/products/" + pid + "/reviews"
+
+	rec := do(s, http.MethodPost, base, `{"author":"Иван","text":"Отличный чайник","rating":5}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	created := decode[models.Review](t, rec)
+	assert.Equal(t, pid, created.ProductID)
+	assert.Equal(t, 5, created.Rating)
+	assert.False(t, created.CreatedAt.IsZero())
+	assert.Equal(t, models.SentimentPending, created.Sentiment.Status)
+
+	rec = do(s, http.MethodGet, base, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, decode[[]models.Review](t, rec), 1)
+
+	rec = do(s, http.MethodPut, base+"/"+created.ID, `{"author":"Иван","text":"Сломался через месяц","rating":2}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	updated := decode[models.Review](t, rec)
+	assert.Equal(t, "Сломался через месяц", updated.Text)
+	assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+
+	rec = do(s, http.MethodGet, base+"/"+created.ID, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 2, decode[models.Review](t, rec).Rating)
+
+	rec = do(s, http.MethodDelete, base+"/"+created.ID, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = do(s, http.MethodGet, base+"/"+created.ID, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestReviewsValidation(t *testing.T) {
+	s := newTestServer(t)
+	pid := addProduct(t, s, "Чайник")
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		status int
+	}{
+		{"unknown product", "/products/unknown/reviews", `{"author":"A","text":"T","rating":3}`, http.StatusNotFound},
+		{"malformed json", "/products/" + pid + "/reviews", `{"author":`, http.StatusBadRequest},
+		{"empty author", "/products/" + pid + "/reviews", `{"author":" ","text":"T","rating":3}`, http.StatusBadRequest},
+		{"empty text", "/products/" + pid + "/reviews", `{"author":"A","text":"","rating":3}`, http.StatusBadRequest},
+		{"rating too low", "/products/" + pid + "/reviews", `{"author":"A","text":"T","rating":0}`, http.StatusBadRequest},
+		{"rating too high", "/products/" + pid + "/reviews", `{"author":"A","text":"T","rating":6}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(s, http.MethodPost, tt.target, tt.body)
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestReviewBelongsToProduct(t *testing.T) {
+	s := newTestServer(t)
+	first := addProduct(t, s, "Чайник")
+	second := addProduct(t, s, "Утюг")
+
+	rec := do(s, http.MethodPost, "/products/"+first+"/reviews", `{"author":"A","text":"T","rating":4}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	review := decode[models.Review](t, rec)
+
+	rec = do(s, http.MethodGet, "/products/"+second+"/reviews/"+review.ID, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = do(s, http.MethodDelete, "/products/"+second+"/reviews/"+review.ID, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestProductRating(t *testing.T) {
+	s := newTestServer(t)
+	pid := addProduct(t, s, "Чайник")
+
+	rec := do(s, http.MethodGet, "/products/"+pid+"/rating", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	empty := decode[models.Rating](t, rec)
+	assert.Equal(t, rating.PriorMean, empty.Score)
+	assert.Zero(t, empty.Reviews)
+
+	for _, text := range []string{"Отличный чайник", "Прекрасный чайник", "Ужасный чайник"} {
+		rec := do(s, http.MethodPost, "/products/"+pid+"/reviews", `{"author":"A","text":"`+text+`","rating":4}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+	}
+	_, err := jobs.NewPool(s.db.(*memdb.MemDB), lexicon.New()).RunOnce(context.Background())
+	require.NoError(t, err)
+
+	rec = do(s, http.MethodGet, "/products/"+pid+"/rating", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	got := decode[models.Rating](t, rec)
+	assert.Equal(t, 3, got.Reviews)
+	assert.Equal(t, models.Distribution{Positive: 2, Negative: 1}, got.Distribution)
+	assert.Greater(t, got.Score, rating.PriorMean)
+
+	rec = do(s, http.MethodGet, "/products/unknown/rating", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestProductRatingAspects(t *testing.T) {
+	s := newTestServer(t)
+	pid := addProduct(t, s, "Чайник")
+
+	for _, text := range []string{
+		"Отличное качество, но цена слишком дорогая",
+		"Качество хорошее, доставка быстрая",
+		"Хороший чайник",
+	} {
+		rec := do(s, http.MethodPost, "/products/"+pid+"/reviews", `{"author":"A","text":"`+text+`","rating":4}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+	}
+	_, err := jobs.NewPool(s.db.(*memdb.MemDB), lexicon.New()).RunOnce(context.Background())
+	require.NoError(t, err)
+
+	rec := do(s, http.MethodGet, "/products/"+pid+"/rating", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	got := decode[models.Rating](t, rec)
+	assert.Equal(t, 3, got.Reviews)
+	require.Len(t, got.Aspects, 3)
+	assert.Equal(t, models.Distribution{Positive: 2}, got.Aspects["quality"].Distribution)
+	assert.Greater(t, got.Aspects["quality"].Score, rating.PriorMean)
+	assert.Equal(t, 1, got.Aspects["price"].Reviews)
+	assert.Less(t, got.Aspects["price"].Score, rating.PriorMean)
+	assert.Equal(t, 1, got.Aspects["delivery"].Reviews)
+
+	// Аспекты отзыва возвращаются вместе с его настроением
+	rec = do(s, http.MethodGet, "/products/"+pid+"/reviews", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	reviews := decode[[]models.Review](t, rec)
+	require.Len(t, reviews, 3)
+	labels := make(map[string]string)
+	for _, a := range reviews[0].Sentiment.Aspects {
+		labels[a.Aspect] = a.Label
+	}
+	assert.Equal(t, map[string]string{"price": "negative", "quality": "positive"}, labels)
+
+	// Удаление отзыва вычитает его вклад из агрегатов аспектов.
+	rec = do(s, http.MethodDelete, "/products/"+pid+"/reviews/"+reviews[0].ID, "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	rec = do(s, http.MethodGet, "/products/"+pid+"/rating", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	got = decode[models.Rating](t, rec)
+	require.Len(t, got.Aspects, 2)
+	assert.NotContains(t, got.Aspects, "price")
+	assert.Equal(t, 1, got.Aspects["quality"].Reviews)
+}
+
+// englishClassifier - классификатор английских отзывов, отмечающий
+// результат своей моделью.
+type englishClassifier struct{}
+
+func (englishClassifier) Classify(context.Context, string) (sentiment.Result, error) {
+	return sentiment.Result{Label: sentiment.Negative, Confidence: 1, Model: "english"}, nil
+}
+
+func TestReviewLanguages(t *testing.T) {
+	s := newTestServer(t)
+	pid := addProduct(t, s, "Чайник")
+	base := "/products/" + pid + "/reviews"
+
+	for _, text := range []string{"Отличный чайник", "Прекрасный чайник", "The kettle is great"} {
+		rec := do(s, http.MethodPost, base, `{"author":"A","text":"`+text+`","rating":4}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+	}
+	classifier := sentiment.ByLanguage(lexicon.New(), map[string]sentiment.Classifier{"en": englishClassifier{}})
+	_, err := jobs.NewPool(s.db.(*memdb.MemDB), classifier).RunOnce(context.Background())
+	require.NoError(t, err)
+
+	rec := do(s, http.MethodGet, base+"?language=en", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	reviews := decode[[]models.Review](t, rec)
+	require.Len(t, reviews, 1)
+	assert.Equal(t, "en", reviews[0].Language)
+	assert.Equal(t, "english", reviews[0].Sentiment.Model)
+
+	rec = do(s, http.MethodGet, base+"?language=ru", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, decode[[]models.Review](t, rec), 2)
+
+	rec = do(s, http.MethodGet, base+"?language=russian", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = do(s, http.MethodGet, "/products/"+pid+"/rating", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	got := decode[models.Rating](t, rec)
+	require.Len(t, got.Languages, 2)
+	assert.Equal(t, models.Distribution{Positive: 2}, got.Languages["ru"].Distribution)
+	assert.Equal(t, models.Distribution{Negative: 1}, got.Languages["en"].Distribution)
+
+	// Язык определяется заново при изменении текста
+	rec = do(s, http.MethodPut, base+"/"+reviews[0].ID, `{"author":"A","text":"Хороший чайник","rating":4}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ru", decode[models.Review](t, rec).Language)
+
+	// Вклад отзыва вычитается из агрегата прежнего языка до повторной классификации.
+	rec = do(s, http.MethodGet, "/products/"+pid+"/rating", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	got = decode[models.Rating](t, rec)
+	require.Len(t, got.Languages, 1)
+	assert.Equal(t, 2, got.Languages["ru"].Reviews)
+}
+
+// lengthEmbedder - векторизатор для тестов: вектор зависит только от длины текста.
+type lengthEmbedder struct{}
+
+func (lengthEmbedder) Model() string {
+	return "length"
+}
+
+func (lengthEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	res := make([][]float32, len(texts))
+	for i, text := range texts {
+		res[i] = []float32{1, float32(len(text)) / 100}
+	}
+	return res, nil
+}
+
+func TestSimilarReviews(t *testing.T) {
+	s := newTestServer(t)
+	pid := addProduct(t, s, "Чайник")
+
+	var ids []string
+	for _, text := range []string{"Хороший чайник", "Хороший чайник", "Чайник протекает с первого дня, не покупайте"} {
+		rec := do(s, http.MethodPost, "/products/"+pid+"/reviews", `{"author":"A","text":"`+text+`","rating":4}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		ids = append(ids, decode[models.Review](t, rec).ID)
+	}
+
+	rec := do(s, http.MethodGet, "/reviews/"+ids[0]+"/similar", "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	_, err := similarity.NewIndexer(s.db.(*memdb.MemDB), lengthEmbedder{}).RunOnce(context.Background())
+	require.NoError(t, err)
+
+	rec = do(s, http.MethodGet, "/reviews/"+ids[0]+"/similar?limit=1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	similar := decode[[]models.SimilarReview](t, rec)
+	require.Len(t, similar, 1)
+	assert.Equal(t, ids[1], similar[0].Review.ID)
+
+	// Копия отмечена при индексации
+	rec = do(s, http.MethodGet, "/products/"+pid+"/reviews/"+ids[1], "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	dup := decode[models.Review](t, rec).Duplicate
+	require.NotNil(t, dup)
+	assert.Equal(t, ids[0], dup.ReviewID)
+
+	rec = do(s, http.MethodGet, "/reviews/"+ids[0]+"/similar?limit=0", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = do(s, http.MethodGet, "/reviews/unknown/similar", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSearchReviews(t *testing.T) {
+	s := newTestServer(t)
+	kettle := addProduct(t, s, "Чайник")
+	iron := addProduct(t, s, "Утюг")
+
+	var ids []string
+	for _, r := range []struct{ pid, text string }{
+		{kettle, "Чайник быстро закипает, но крышка <b>скрипит</b>"},
+		{kettle, "Крышки у чайников этой серии ломаются"},
+		{iron, "Утюг хороший, крышка резервуара плотная"},
+		{kettle, "The lid of this kettle is loose"},
+	} {
+		rec := do(s, http.MethodPost, "/products/"+r.pid+"/reviews", `{"author":"A","text":"`+r.text+`","rating":4}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		ids = append(ids, decode[models.Review](t, rec).ID)
+	}
+	search := func(params url.Values) []models.SearchResult {
+		t.Helper()
+		rec := do(s, http.MethodGet, "/reviews/search?"+params.Encode(), "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		return decode[[]models.SearchResult](t, rec)
+	}
+
+	// Формы слова находятся по общей основе
+	found := search(url.Values{"q": {"крышка"}})
+	require.Len(t, found, 3)
+	assert.Equal(t, "Чайник быстро закипает, но <mark>крышка</mark> &lt;b&gt;скрипит&lt;/b&gt;", found[slices.IndexFunc(found, func(r models.SearchResult) bool {
+		return r.Review.ID == ids[0]
+	})].Highlight)
+
+	found = search(url.Values{"q": {"крышки чайника"}})
+	require.Len(t, found, 2)
+	assert.ElementsMatch(t, []string{ids[0], ids[1]}, []string{found[0].Review.ID, found[1].Review.ID})
+
+	assert.Len(t, search(url.Values{"q": {"крышка"}, "product_id": {iron}}), 1)
+	assert.Len(t, search(url.Values{"q": {"kettle"}, "language": {"en"}}), 1)
+	assert.Len(t, search(url.Values{"q": {"kettle"}, "language": {"ru"}}), 0)
+	assert.Len(t, search(url.Values{"q": {"крышка"}, "limit": {"1"}}), 1)
+
+	// Измененные и удаленные отзывы не находятся по прежнему тексту
+	rec := do(s, http.MethodPut, "/products/"+kettle+"/reviews/"+ids[1], `{"author":"A","text":"Ручка удобная","rating":4}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = do(s, http.MethodDelete, "/products/"+iron+"/reviews/"+ids[2], "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	found = search(url.Values{"q": {"крышка"}})
+	require.Len(t, found, 1)
+	assert.Equal(t, ids[0], found[0].Review.ID)
+	assert.Len(t, search(url.Values{"q": {"ручка"}}), 1)
+
+	for _, target := range []string{
+		"/reviews/search",
+		"/reviews/search?q=+",
+		"/reviews/search?q=a&limit=0",
+		"/reviews/search?q=a&language=russian",
+	} {
+		assert.Equal(t, http.StatusBadRequest, do(s, http.MethodGet, target, "").Code, target)
+	}
+}
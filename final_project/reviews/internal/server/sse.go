@@ -0,0 +1,52 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// streamWriteTimeout - время на отправку одного события клиенту.
+// Медленный клиент задерживает чтение ответа Ollama, но не дольше этого времени.
+const streamWriteTimeout = 10 * time.Second
+
+// sseWriter записывает ответ в формате Server-Sent Events.
+// Заголовки отправляются вместе с первым событием, поэтому до него
+// ошибку можно вернуть обычным ответом.
+type sseWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	started bool
+}
+
+func newSSEWriter(w http.ResponseWriter) *sseWriter {
+	return &sseWriter{w: w, rc: http.NewResponseController(w)}
+}
+
+// Send записывает событие и сразу отправляет его клиенту.
+func (s *sseWriter) Send(event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if !s.started {
+		s.w.Header().Set("Content-Type", "text/event-stream")
+		s.w.Header().Set("Cache-Control", "no-cache")
+		s.w.Header().Set("Connection", "keep-alive")
+		s.w.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+	if err := s.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// Started сообщает, отправлено ли клиенту хотя бы одно событие.
+func (s *sseWriter) Started() bool {
+	return s.started
+}
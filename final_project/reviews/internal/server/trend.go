@@ -0,0 +1,72 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"go-masters/final_project/reviews/internal/trend"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// historyRanges - интервал истории рейтинга по умолчанию.
+var historyRanges = map[string]time.Duration{
+	trend.Day:  30 * 24 * time.Hour,
+	trend.Week: 26 * 7 * 24 * time.Hour,
+}
+
+func (s *Server) getRatingHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса getRatingHistory")
+	span.AddEvent("Обработка запроса getRatingHistory")
+
+	q := r.URL.Query()
+	bucket := q.Get("bucket")
+	if bucket == "" {
+		bucket = trend.Day
+	}
+	if !trend.ValidBucket(bucket) {
+		writeError(w, http.StatusBadRequest, "bucket должен быть day или week")
+		return
+	}
+
+	var from, to time.Time
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, p.name+" должен быть в формате RFC 3339")
+			return
+		}
+		*p.t = t
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-historyRanges[bucket])
+	}
+	if !from.Before(to) {
+		writeError(w, http.StatusBadRequest, "from должен быть раньше to")
+		return
+	}
+
+	// Первый период истории берется целиком.
+	id := chi.URLParam(r, "id")
+	daily, err := s.db.RatingHistory(r.Context(), id, trend.Start(from, bucket), to)
+	if err != nil {
+		writeDBError(w, span, err, "товар не найден")
+		return
+	}
+	writeJSON(w, http.StatusOK, trend.History(id, bucket, trend.Series(daily, bucket)))
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/sentiment/lexicon"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRatingHistory(t *testing.T) {
+	s := newTestServer(t)
+	pid := addProduct(t, s, "Чайник")
+	m := s.db.(*memdb.MemDB)
+
+	// 2026-10-19 - понедельник.
+	mon := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	_, err := m.ImportReviews(context.Background(), []models.Review{
+		{ProductID: pid, Author: "Анна", Text: "Отличный чайник", Rating: 5, CreatedAt: mon},
+		{ProductID: pid, Author: "Олег", Text: "Ужасный чайник", Rating: 1, CreatedAt: mon.AddDate(0, 0, 2)},
+		{ProductID: pid, Author: "Иван", Text: "Отличный чайник", Rating: 5, CreatedAt: mon.AddDate(0, 0, 7)},
+	})
+	require.NoError(t, err)
+	_, err = jobs.NewPool(m, lexicon.New()).RunOnce(context.Background())
+	require.NoError(t, err)
+
+	base := "/products/" + pid + "/rating/history"
+	rec := do(s, http.MethodGet, base+"?from=2026-10-01T00:00:00Z&to=2026-11-01T00:00:00Z", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	days := decode[models.RatingHistory](t, rec)
+	assert.Equal(t, "day", days.Bucket)
+	require.Len(t, days.Points, 3)
+	assert.Equal(t, time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC), days.Points[1].Start)
+	assert.Equal(t, 1.0, days.Points[1].Score)
+
+	// Первая неделя берется целиком, хотя from приходится на среду.
+	rec = do(s, http.MethodGet, base+"?bucket=week&from=2026-10-21T00:00:00Z&to=2026-11-01T00:00:00Z", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	weeks := decode[models.RatingHistory](t, rec)
+	require.Len(t, weeks.Points, 2)
+	week := weeks.Points[0]
+	assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), week.Start)
+	assert.Equal(t, models.Distribution{Positive: 1, Negative: 1}, week.Distribution)
+	// Вес отзывов зависит от уверенности классификатора и свежести.
+	assert.InDelta(t, 3, week.Score, 0.1)
+
+	// По умолчанию - дни за последний месяц.
+	rec = do(s, http.MethodGet, base, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotNil(t, decode[models.RatingHistory](t, rec).Points)
+
+	rec = do(s, http.MethodGet, base+"?bucket=month", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = do(s, http.MethodGet, base+"?from=2026-11-01T00:00:00Z&to=2026-10-01T00:00:00Z", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = do(s, http.MethodGet, "/products/unknown/rating/history", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// voteInput - тело запроса с голосом за полезность отзыва.
+type voteInput struct {
+	Voter string `json:"voter"`
+	// Голос: up (полезно) или down (бесполезно).
+	Vote string `json:"vote"`
+}
+
+// clientKey возвращает ключ клиента запроса - хэш адреса, чтобы не хранить
+// адреса пользователей.
+func (s *Server) clientKey(r *http.Request) string {
+	sum := sha256.Sum256([]byte(s.clientIP(r)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// clientIP возвращает адрес клиента запроса. За доверенным прокси адрес
+// берется из X-Forwarded-For: это самый правый адрес, не принадлежащий
+// доверенным прокси, так как левее клиент может дописать что угодно.
+func (s *Server) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !s.trustedProxy(host) {
+		return host
+	}
+
+	var hops []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(h, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		host = strings.TrimSpace(hops[i])
+		if !s.trustedProxy(host) {
+			break
+		}
+	}
+	return host
+}
+
+// trustedProxy сообщает, принадлежит ли адрес доверенным прокси.
+func (s *Server) trustedProxy(host string) bool {
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range s.trustedProxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseProxies разбирает доверенные прокси: адреса или подсети в нотации CIDR.
+func parseProxies(proxies []string) ([]netip.Prefix, error) {
+	res := make([]netip.Prefix, 0, len(proxies))
+	for _, p := range proxies {
+		if addr, err := netip.ParseAddr(p); err == nil {
+			addr = addr.Unmap()
+			res = append(res, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный адрес прокси %q: %w", p, err)
+		}
+		res = append(res, prefix.Masked())
+	}
+	return res, nil
+}
+
+// allowVote учитывает голос клиента в ограничении частоты и отвечает 429,
+// если лимит исчерпан.
+func (s *Server) allowVote(w http.ResponseWriter, client string) bool {
+	ok, retry := s.voteLimiter.Allow(client)
+	if !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+		writeError(w, http.StatusTooManyRequests, "слишком много голосов, повторите позже")
+	}
+	return ok
+}
+
+func (s *Server) voteHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса vote")
+	span.AddEvent("Обработка запроса vote")
+
+	var req voteInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		span.SetStatus(codes.Error, "не удалось декодировать запрос")
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.Voter = strings.TrimSpace(req.Voter)
+	if req.Voter == "" {
+		writeError(w, http.StatusBadRequest, "не указан пользователь")
+		return
+	}
+	if req.Vote != "up" && req.Vote != "down" {
+		writeError(w, http.StatusBadRequest, "vote должен быть up или down")
+		return
+	}
+
+	client := s.clientKey(r)
+	if !s.allowVote(w, client) {
+		return
+	}
+
+	review, err := s.db.Vote(r.Context(), chi.URLParam(r, "id"), models.Vote{
+		ReviewID: chi.URLParam(r, "reviewID"),
+		Voter:    req.Voter,
+		Client:   client,
+		Helpful:  req.Vote == "up",
+	})
+	if errors.Is(err, db.ErrConflict) {
+		writeError(w, http.StatusConflict, "за отзыв уже голосовал другой пользователь с этого адреса или этот пользователь с другого")
+		return
+	}
+	if err != nil {
+		writeDBError(w, span, err, "отзыв не найден")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, review)
+}
+
+func (s *Server) unvoteHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса unvote")
+	span.AddEvent("Обработка запроса unvote")
+
+	voter := strings.TrimSpace(r.URL.Query().Get("voter"))
+	if voter == "" {
+		writeError(w, http.StatusBadRequest, "не указан пользователь")
+		return
+	}
+	client := s.clientKey(r)
+	if !s.allowVote(w, client) {
+		return
+	}
+
+	// Голос отменяется только с клиента, с которого он подан.
+	review, err := s.db.Unvote(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "reviewID"), voter, client)
+	if err != nil {
+		writeDBError(w, span, err, "отзыв или голос не найден")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, review)
+}
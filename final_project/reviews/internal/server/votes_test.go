@@ -0,0 +1,153 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-masters/final_project/reviews/internal/helpful"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// doFrom выполняет запрос с адреса клиента addr.
+func doFrom(s *Server, addr, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = addr + ":5000"
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestVotes(t *testing.T) {
+	s := newTestServer(t)
+	pid := addProduct(t, s, "Чайник")
+	base := "/products/" + pid + "/reviews"
+
+	var ids []string
+	for _, text := range []string{"Хороший чайник", "Отличный чайник", "Нормальный чайник"} {
+		rec := do(s, http.MethodPost, base, `{"author":"Иван","text":"`+text+`","rating":4}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		ids = append(ids, decode[models.Review](t, rec).ID)
+	}
+
+	rec := doFrom(s, "10.0.0.1", http.MethodPut, base+"/"+ids[0]+"/vote", `{"voter":"anna","vote":"maybe"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doFrom(s, "10.0.0.1", http.MethodPut, base+"/"+ids[0]+"/vote", `{"vote":"up"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doFrom(s, "10.0.0.1", http.MethodPut, base+"/"+ids[1]+"/vote", `{"voter":"anna","vote":"up"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, models.Helpfulness{Up: 1}, decode[models.Review](t, rec).Helpful)
+
+	// Повторный голос пользователя заменяет прежний
+	rec = doFrom(s, "10.0.0.1", http.MethodPut, base+"/"+ids[1]+"/vote", `{"voter":"anna","vote":"down"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, models.Helpfulness{Down: 1}, decode[models.Review](t, rec).Helpful)
+	rec = doFrom(s, "10.0.0.1", http.MethodPut, base+"/"+ids[1]+"/vote", `{"voter":"anna","vote":"up"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// С того же адреса другой пользователь не голосует
+	rec = doFrom(s, "10.0.0.1", http.MethodPut, base+"/"+ids[1]+"/vote", `{"voter":"bot","vote":"up"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = doFrom(s, "10.0.0.2", http.MethodPut, base+"/"+ids[1]+"/vote", `{"voter":"oleg","vote":"up"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, models.Helpfulness{Up: 2}, decode[models.Review](t, rec).Helpful)
+	rec = doFrom(s, "10.0.0.2", http.MethodPut, base+"/"+ids[2]+"/vote", `{"voter":"oleg","vote":"down"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// Голос пользователя с другого адреса не заменяется
+	rec = doFrom(s, "10.0.0.9", http.MethodPut, base+"/"+ids[1]+"/vote", `{"voter":"oleg","vote":"down"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// Самые полезные первыми, без голосов - новые первыми
+	rec = do(s, http.MethodGet, base+"?sort=helpful", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	reviews := decode[[]models.Review](t, rec)
+	require.Len(t, reviews, 3)
+	assert.Equal(t, ids[1], reviews[0].ID)
+	assert.Equal(t, ids[2], reviews[1].ID)
+	assert.Equal(t, ids[0], reviews[2].ID)
+
+	rec = do(s, http.MethodGet, base+"?sort=rating", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Чужой голос с другого адреса не отменяется
+	rec = doFrom(s, "10.0.0.9", http.MethodDelete, base+"/"+ids[1]+"/vote?voter=oleg", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doFrom(s, "10.0.0.2", http.MethodDelete, base+"/"+ids[1]+"/vote?voter=oleg", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, models.Helpfulness{Up: 1}, decode[models.Review](t, rec).Helpful)
+
+	// Частота голосов с одного клиента ограничена
+	s.voteLimiter = helpful.NewLimiter(1, time.Minute)
+	rec = doFrom(s, "10.0.0.3", http.MethodPut, base+"/"+ids[0]+"/vote", `{"voter":"ivan","vote":"up"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = doFrom(s, "10.0.0.3", http.MethodPut, base+"/"+ids[2]+"/vote", `{"voter":"ivan","vote":"up"}`)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+}
+
+func TestClientIP(t *testing.T) {
+	s := newTestServer(t)
+	var err error
+	s.trustedProxies, err = parseProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{"без прокси", "203.0.113.5:5000", nil, "203.0.113.5"},
+		{"заголовок от недоверенного адреса", "203.0.113.5:5000", []string{"198.51.100.1"}, "203.0.113.5"},
+		{"доверенный прокси", "10.1.2.3:5000", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"цепочка прокси", "10.1.2.3:5000", []string{"1.1.1.1, 198.51.100.1, 192.168.1.1"}, "198.51.100.1"},
+		{"несколько заголовков", "192.168.1.1:5000", []string{"198.51.100.1", "10.0.0.7"}, "198.51.100.1"},
+		{"прокси без заголовка", "10.1.2.3:5000", nil, "10.1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, h := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", h)
+			}
+			assert.Equal(t, tt.want, s.clientIP(req))
+		})
+	}
+
+	_, err = parseProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
+
+func TestVotes_BehindProxy(t *testing.T) {
+	s := newTestServer(t)
+	var err error
+	s.trustedProxies, err = parseProxies([]string{"10.0.0.1"})
+	require.NoError(t, err)
+	pid := addProduct(t, s, "Чайник")
+	rec := do(s, http.MethodPost, "/products/"+pid+"/reviews", `{"author":"Иван","text":"Хороший чайник","rating":4}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	target := "/products/" + pid + "/reviews/" + decode[models.Review](t, rec).ID + "/vote"
+
+	// Пользователи за одним прокси различаются по X-Forwarded-For
+	for i, voter := range []string{"anna", "oleg"} {
+		req := httptest.NewRequest(http.MethodPut, target, strings.NewReader(`{"voter":"`+voter+`","vote":"up"}`))
+		req.RemoteAddr = "10.0.0.1:5000"
+		req.Header.Set("X-Forwarded-For", "198.51.100."+strconv.Itoa(i+1))
+		rec = httptest.NewRecorder()
+		s.router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	}
+	assert.Equal(t, models.Helpfulness{Up: 2}, decode[models.Review](t, rec).Helpful)
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-masters/final_project/reviews/internal/webhook"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// webhookInput - тело запроса на создание или изменение подписки.
+type webhookInput struct {
+	URL string `json:"url"`
+	// Ключ подписи; если не указан, генерируется при создании
+	// и сохраняется прежний при изменении.
+	Secret     string   `json:"secret"`
+	Events     []string `json:"events"`
+	ProductIDs []string `json:"product_ids"`
+	Labels     []string `json:"labels"`
+}
+
+func (in webhookInput) webhook(id string) webhook.Webhook {
+	return webhook.Webhook{
+		ID:         id,
+		URL:        in.URL,
+		Secret:     in.Secret,
+		Events:     in.Events,
+		ProductIDs: in.ProductIDs,
+		Labels:     in.Labels,
+	}
+}
+
+// decodeWebhook декодирует тело запроса с подпиской.
+func decodeWebhook(w http.ResponseWriter, r *http.Request, span trace.Span) (webhookInput, bool) {
+	var req webhookInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		span.SetStatus(codes.Error, "не удалось декодировать запрос")
+		writeError(w, http.StatusBadRequest, err.Error())
+		return req, false
+	}
+	return req, true
+}
+
+// writeWebhookError записывает ответ для ошибки изменения подписки.
+func writeWebhookError(w http.ResponseWriter, span trace.Span, err error) {
+	if errors.Is(err, webhook.ErrInvalid) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeDBError(w, span, err, "подписка не найдена")
+}
+
+func (s *Server) addWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса addWebhook")
+	span.AddEvent("Обработка запроса addWebhook")
+
+	req, ok := decodeWebhook(w, r, span)
+	if !ok {
+		return
+	}
+
+	wh, err := s.webhooks.Create(r.Context(), req.webhook(""))
+	if err != nil {
+		writeWebhookError(w, span, err)
+		return
+	}
+
+	// Секрет возвращается только при создании подписки.
+	writeJSON(w, http.StatusCreated, wh)
+}
+
+func (s *Server) listWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса listWebhooks")
+	span.AddEvent("Обработка запроса listWebhooks")
+
+	webhooks, err := s.webhooks.List(r.Context())
+	if err != nil {
+		writeDBError(w, span, err, "")
+		return
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	writeJSON(w, http.StatusOK, webhooks)
+}
+
+func (s *Server) getWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса getWebhook")
+	span.AddEvent("Обработка запроса getWebhook")
+
+	wh, err := s.webhooks.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeDBError(w, span, err, "подписка не найдена")
+		return
+	}
+	wh.Secret = ""
+
+	writeJSON(w, http.StatusOK, wh)
+}
+
+func (s *Server) updateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса updateWebhook")
+	span.AddEvent("Обработка запроса updateWebhook")
+
+	req, ok := decodeWebhook(w, r, span)
+	if !ok {
+		return
+	}
+
+	wh, err := s.webhooks.Update(r.Context(), req.webhook(chi.URLParam(r, "id")))
+	if err != nil {
+		writeWebhookError(w, span, err)
+		return
+	}
+	wh.Secret = ""
+
+	writeJSON(w, http.StatusOK, wh)
+}
+
+func (s *Server) deleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса deleteWebhook")
+	span.AddEvent("Обработка запроса deleteWebhook")
+
+	if err := s.webhooks.Delete(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeDBError(w, span, err, "подписка не найдена")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Число доставок в ответе журнала по умолчанию и максимальное.
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+func (s *Server) listDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса listDeliveries")
+	span.AddEvent("Обработка запроса listDeliveries")
+
+	limit := defaultDeliveriesLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxDeliveriesLimit {
+			writeError(w, http.StatusBadRequest, "limit должен быть от 1 до "+strconv.Itoa(maxDeliveriesLimit))
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := s.webhooks.Deliveries(r.Context(), chi.URLParam(r, "id"), limit)
+	if err != nil {
+		writeDBError(w, span, err, "подписка не найдена")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+func (s *Server) replayDeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	log.Info().Msg("Обработка запроса replayDelivery")
+	span.AddEvent("Обработка запроса replayDelivery")
+
+	d, err := s.webhooks.Replay(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "deliveryID"))
+	if err != nil {
+		writeDBError(w, span, err, "доставка не найдена")
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, d)
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/sentiment/lexicon"
+	"go-masters/final_project/reviews/internal/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver - подписчик, проверяющий подпись и отвечающий заданными кодами.
+type receiver struct {
+	t      *testing.T
+	secret string
+
+	mu       sync.Mutex
+	statuses []int
+	events   []json.RawMessage
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	require.NoError(rc.t, err)
+	ts, err := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+	require.NoError(rc.t, err)
+	assert.Equal(rc.t, webhook.Sign(rc.secret, time.Unix(ts, 0), body), r.Header.Get(webhook.HeaderSignature))
+	assert.Equal(rc.t, webhook.EventReviewClassified, r.Header.Get(webhook.HeaderEvent))
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	if status == http.StatusOK {
+		rc.events = append(rc.events, body)
+	}
+	w.WriteHeader(status)
+}
+
+func TestWebhooks(t *testing.T) {
+	s := newTestServer(t)
+	s.webhooks.MinBackoff = 0
+	s.webhooks.MaxAttempts = 2
+	m := s.db.(*memdb.MemDB)
+	ctx := context.Background()
+	pid := addProduct(t, s, "Чайник")
+	other := addProduct(t, s, "Утюг")
+
+	rc := &receiver{t: t, statuses: []int{http.StatusInternalServerError}}
+	ts := httptest.NewServer(rc)
+	t.Cleanup(ts.Close)
+
+	rec := doAdmin(s, http.MethodPost, "/admin/webhooks", `{"url":"ftp://example.com"}`, adminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doAdmin(s, http.MethodPost, "/admin/webhooks", `{"url":"`+ts.URL+`","labels":["angry"]}`, adminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doAdmin(s, http.MethodPost, "/admin/webhooks", `{"url":"`+ts.URL+`","events":["review.classified"],"product_ids":["`+pid+`"],"labels":["positive"]}`, adminToken)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	created := decode[webhook.Webhook](t, rec)
+	require.NotEmpty(t, created.Secret)
+	rc.secret = created.Secret
+
+	rec = doAdmin(s, http.MethodGet, "/admin/webhooks", "", adminToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+	list := decode[[]webhook.Webhook](t, rec)
+	require.Len(t, list, 1)
+	assert.Empty(t, list[0].Secret)
+
+	// Под фильтры подходит только положительный отзыв о первом товаре
+	do(s, http.MethodPost, "/products/"+pid+"/reviews", `{"author":"A","text":"Отличный чайник","rating":5}`)
+	do(s, http.MethodPost, "/products/"+pid+"/reviews", `{"author":"B","text":"Ужасный чайник","rating":1}`)
+	do(s, http.MethodPost, "/products/"+other+"/reviews", `{"author":"C","text":"Отличный утюг","rating":5}`)
+	_, err := jobs.NewPool(m, lexicon.New()).RunOnce(ctx)
+	require.NoError(t, err)
+
+	// Первая попытка получает 500, вторая доставляет событие
+	n, err := s.webhooks.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = s.webhooks.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.Len(t, rc.events, 1)
+	var event struct {
+		Type string        `json:"type"`
+		Data models.Review `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rc.events[0], &event))
+	assert.Equal(t, webhook.EventReviewClassified, event.Type)
+	assert.Equal(t, "Отличный чайник", event.Data.Text)
+	assert.Equal(t, "positive", event.Data.Sentiment.Label)
+
+	base := "/admin/webhooks/" + created.ID
+	rec = doAdmin(s, http.MethodGet, base+"/deliveries", "", adminToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+	deliveries := decode[[]webhook.Delivery](t, rec)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, webhook.StatusDelivered, deliveries[0].Status)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusOK, deliveries[0].ResponseStatus)
+
+	// Повторная отправка создает новую доставку того же события
+	rec = doAdmin(s, http.MethodPost, base+"/deliveries/"+deliveries[0].ID+"/replay", "", adminToken)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	replay := decode[webhook.Delivery](t, rec)
+	assert.Equal(t, deliveries[0].EventID, replay.EventID)
+	assert.Equal(t, webhook.StatusPending, replay.Status)
+
+	// Исчерпанные попытки переводят доставку в failed
+	rc.statuses = []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}
+	s.webhooks.RunOnce(ctx)
+	s.webhooks.RunOnce(ctx)
+	rec = doAdmin(s, http.MethodGet, base+"/deliveries?limit=1", "", adminToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+	deliveries = decode[[]webhook.Delivery](t, rec)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, replay.ID, deliveries[0].ID)
+	assert.Equal(t, webhook.StatusFailed, deliveries[0].Status)
+	assert.Contains(t, deliveries[0].Error, "503")
+
+	rec = doAdmin(s, http.MethodGet, base+"/deliveries?limit=0", "", adminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doAdmin(s, http.MethodPut, base, `{"url":"`+ts.URL+`/v2"}`, adminToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Empty(t, decode[webhook.Webhook](t, rec).Secret)
+	got, err := m.GetWebhook(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.Secret, got.Secret)
+	assert.Empty(t, got.Events)
+
+	rec = doAdmin(s, http.MethodDelete, base, "", adminToken)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = doAdmin(s, http.MethodGet, base, "", adminToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = doAdmin(s, http.MethodGet, base+"/deliveries", "", adminToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,145 @@
+package similarity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Параметры индексатора по умолчанию.
+const (
+	DefaultBatchSize    = 32
+	DefaultPollInterval = 2 * time.Second
+	DefaultTimeout      = time.Minute
+	// Порог сходства, начиная с которого отзыв считается копией.
+	DefaultThreshold = 0.95
+	// Число ближайших отзывов, среди которых ищется оригинал.
+	candidates = 5
+)
+
+// Indexer вычисляет векторы новых и измененных отзывов
+// и отмечает почти дословные копии.
+type Indexer struct {
+	store    Store
+	embedder Embedder
+
+	BatchSize    int
+	PollInterval time.Duration
+	// Ограничение времени вычисления векторов одного пакета.
+	Timeout   time.Duration
+	Threshold float64
+}
+
+func NewIndexer(store Store, embedder Embedder) *Indexer {
+	return &Indexer{
+		store:        store,
+		embedder:     embedder,
+		BatchSize:    DefaultBatchSize,
+		PollInterval: DefaultPollInterval,
+		Timeout:      DefaultTimeout,
+		Threshold:    DefaultThreshold,
+	}
+}
+
+// Run индексирует отзывы до отмены контекста.
+func (ix *Indexer) Run(ctx context.Context) {
+	log.Info().Str("model", ix.embedder.Model()).Msg("Запуск индексации отзывов")
+
+	ticker := time.NewTicker(ix.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := ix.RunOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Error().Err(err).Msg("Ошибка индексации отзывов")
+			}
+			// Если пакет заполнен целиком, сразу выбираем следующий.
+			if n < ix.BatchSize || err != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Остановка индексации отзывов")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce индексирует пакет отзывов без вектора.
+// Возвращает количество выбранных отзывов.
+func (ix *Indexer) RunOnce(ctx context.Context) (int, error) {
+	model := ix.embedder.Model()
+	reviews, err := ix.store.PendingEmbeddings(ctx, model, ix.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка выборки отзывов: %w", err)
+	}
+	if len(reviews) == 0 {
+		return 0, nil
+	}
+
+	texts := make([]string, len(reviews))
+	for i, r := range reviews {
+		texts[i] = r.Text
+	}
+	ectx, cancel := context.WithTimeout(ctx, ix.Timeout)
+	vectors, err := ix.embedder.Embed(ectx, texts)
+	cancel()
+	if err != nil {
+		return len(reviews), fmt.Errorf("ошибка вычисления векторов: %w", err)
+	}
+
+	// Отзывы сохраняются по порядку, чтобы копия отзыва
+	// из того же пакета нашла уже сохраненный оригинал.
+	for i, r := range reviews {
+		e := Embedding{ReviewID: r.ID, Model: model, Vector: vectors[i]}
+		dup, err := ix.duplicateOf(ctx, r, e)
+		if err != nil {
+			return len(reviews), fmt.Errorf("отзыв %s: %w", r.ID, err)
+		}
+		if err := ix.store.SaveEmbedding(ctx, r, e, dup); err != nil {
+			return len(reviews), fmt.Errorf("отзыв %s: %w", r.ID, err)
+		}
+		if dup != nil {
+			log.Warn().
+				Str("review_id", r.ID).
+				Str("duplicate_of", dup.ReviewID).
+				Float64("similarity", dup.Similarity).
+				Msg("Отзыв повторяет ранее опубликованный")
+		}
+	}
+	return len(reviews), nil
+}
+
+// duplicateOf возвращает самый похожий из более ранних отзывов,
+// если его сходство с r не ниже порога.
+func (ix *Indexer) duplicateOf(ctx context.Context, r models.Review, e Embedding) (*models.Duplicate, error) {
+	nearest, err := ix.store.NearestReviews(ctx, e, candidates)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range nearest {
+		if n.Similarity < ix.Threshold {
+			break
+		}
+		if earlier(n.Review, r) {
+			return &models.Duplicate{ReviewID: n.Review.ID, Similarity: n.Similarity}, nil
+		}
+	}
+	return nil, nil
+}
+
+// earlier сообщает, опубликован ли отзыв a раньше b.
+func earlier(a, b models.Review) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
@@ -0,0 +1,160 @@
+package similarity_test
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/similarity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bagOfWords - векторизатор для тестов: вектор - число вхождений слов,
+// разложенных хешем по измерениям.
+type bagOfWords struct {
+	err error
+}
+
+func (b *bagOfWords) Model() string {
+	return "bow"
+}
+
+func (b *bagOfWords) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	res := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, 64)
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r)
+		})
+		for _, w := range words {
+			h := fnv.New32a()
+			h.Write([]byte(w))
+			v[h.Sum32()%64]++
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+func addReview(t *testing.T, m *memdb.MemDB, productID, text string) models.Review {
+	t.Helper()
+
+	r, err := m.AddReview(context.Background(), models.Review{ProductID: productID, Author: "author", Text: text, Rating: 5})
+	require.NoError(t, err)
+	return r
+}
+
+func getReview(t *testing.T, m *memdb.MemDB, r models.Review) models.Review {
+	t.Helper()
+
+	got, err := m.GetReview(context.Background(), r.ProductID, r.ID)
+	require.NoError(t, err)
+	return got
+}
+
+func TestIndexer(t *testing.T) {
+	ctx := context.Background()
+	m := memdb.New()
+	p, err := m.AddProduct(ctx, models.Product{Name: "Телефон"})
+	require.NoError(t, err)
+
+	original := addReview(t, m, p.ID, "Отличный телефон, батарея держит два дня")
+	other := addReview(t, m, p.ID, "Экран тусклый, камера слабая")
+	copied := addReview(t, m, p.ID, "Отличный телефон! Батарея держит два дня.")
+
+	ix := similarity.NewIndexer(m, &bagOfWords{})
+	n, err := ix.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	// Отмечается только более поздний отзыв
+	assert.Nil(t, getReview(t, m, original).Duplicate)
+	assert.Nil(t, getReview(t, m, other).Duplicate)
+	dup := getReview(t, m, copied).Duplicate
+	require.NotNil(t, dup)
+	assert.Equal(t, original.ID, dup.ReviewID)
+	assert.InDelta(t, 1, dup.Similarity, 1e-6)
+
+	similar, err := m.SimilarReviews(ctx, original.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, similar, 2)
+	assert.Equal(t, copied.ID, similar[0].Review.ID)
+	assert.Greater(t, similar[0].Similarity, similar[1].Similarity)
+
+	// Все отзывы проиндексированы
+	n, err = ix.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	// Измененный текст оригинала снимает отметку с копии,
+	// и оба отзыва индексируются заново
+	seen := original.Text
+	original.Text = "Телефон сломался через неделю"
+	_, err = m.UpdateReview(ctx, original, seen)
+	require.NoError(t, err)
+	assert.Nil(t, getReview(t, m, copied).Duplicate)
+	_, err = m.SimilarReviews(ctx, original.ID, 10)
+	assert.ErrorIs(t, err, db.ErrNoEmbedding)
+
+	n, err = ix.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Nil(t, getReview(t, m, copied).Duplicate)
+}
+
+func TestIndexer_IgnoresUnpublished(t *testing.T) {
+	ctx := context.Background()
+	m := memdb.New()
+	p, err := m.AddProduct(ctx, models.Product{Name: "Телефон"})
+	require.NoError(t, err)
+
+	// Отклоненный спам не считается оригиналом опубликованного отзыва
+	_, err = m.AddReview(ctx, models.Review{
+		ProductID:  p.ID,
+		Author:     "spam",
+		Text:       "Отличный телефон, батарея держит два дня",
+		Rating:     5,
+		Moderation: models.Moderation{Status: models.ReviewRejected},
+	})
+	require.NoError(t, err)
+	r := addReview(t, m, p.ID, "Отличный телефон, батарея держит два дня")
+
+	n, err := similarity.NewIndexer(m, &bagOfWords{}).RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Nil(t, getReview(t, m, r).Duplicate)
+}
+
+func TestIndexer_EmbedError(t *testing.T) {
+	ctx := context.Background()
+	m := memdb.New()
+	p, err := m.AddProduct(ctx, models.Product{Name: "Телефон"})
+	require.NoError(t, err)
+	addReview(t, m, p.ID, "Хороший телефон")
+
+	embedder := &bagOfWords{err: errors.New("ollama недоступна")}
+	ix := similarity.NewIndexer(m, embedder)
+	_, err = ix.RunOnce(ctx)
+	assert.Error(t, err)
+
+	// Отзыв остается в очереди индексации
+	pending, err := m.PendingEmbeddings(ctx, embedder.Model(), 10)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+
+	embedder.err = nil
+	n, err := ix.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
@@ -0,0 +1,96 @@
+// Package similarity ищет похожие отзывы по векторам текстов (embeddings)
+// и отмечает почти дословные копии ранее опубликованных отзывов.
+//
+// Векторы вычисляет Ollama. Индексатор (Indexer) в фоне выбирает отзывы без
+// вектора текущей модели, вычисляет векторы пакетом, ищет ближайший более
+// ранний отзыв и, если сходство не ниже порога, отмечает новый отзыв как
+// копию. Измененный текст сбрасывает вектор и отметку, и отзыв индексируется
+// заново.
+package similarity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"go-masters/final_project/reviews/internal/llm"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/ollama/ollama/api"
+)
+
+// Embedding - вектор текста отзыва.
+type Embedding struct {
+	ReviewID string
+	Model    string
+	Vector   []float32
+}
+
+// Store - хранилище векторов отзывов.
+type Store interface {
+	// PendingEmbeddings возвращает до limit отзывов без вектора модели model
+	// в порядке создания.
+	PendingEmbeddings(ctx context.Context, model string, limit int) ([]models.Review, error)
+	// NearestReviews возвращает до limit опубликованных отзывов с векторами
+	// той же модели, наиболее близких к e, не считая отзыва e.ReviewID.
+	// Отклоненный или ожидающий модерации отзыв не может быть оригиналом.
+	NearestReviews(ctx context.Context, e Embedding, limit int) ([]models.SimilarReview, error)
+	// SaveEmbedding сохраняет вектор отзыва r и отметку о копии dup.
+	// Если текст отзыва изменился или отзыв удален, вектор не сохраняется.
+	SaveEmbedding(ctx context.Context, r models.Review, e Embedding, dup *models.Duplicate) error
+}
+
+// Embedder вычисляет векторы текстов.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Model возвращает имя модели; векторы разных моделей несравнимы.
+	Model() string
+}
+
+// ollamaEmbedder вычисляет векторы моделью Ollama.
+type ollamaEmbedder struct {
+	client *llm.Client
+	model  string
+}
+
+// NewEmbedder возвращает Embedder, использующий модель Ollama.
+func NewEmbedder(client *llm.Client, model string) Embedder {
+	return &ollamaEmbedder{client: client, model: model}
+}
+
+func (e *ollamaEmbedder) Model() string {
+	return e.model
+}
+
+func (e *ollamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	resp, err := e.client.Embed(ctx, &api.EmbedRequest{Model: e.model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("получено %d векторов для %d текстов", len(resp.Embeddings), len(texts))
+	}
+	return resp.Embeddings, nil
+}
+
+// ErrDimensions - векторы разной размерности.
+var ErrDimensions = errors.New("векторы разной размерности")
+
+// Cosine возвращает косинусное сходство векторов a и b
+// (0 для нулевого вектора).
+func Cosine(a, b []float32) (float64, error) {
+	if len(a) != len(b) {
+		return 0, ErrDimensions
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0, nil
+	}
+	return dot / math.Sqrt(na*nb), nil
+}
@@ -0,0 +1,59 @@
+package similarity
+
+import (
+	"context"
+	"testing"
+
+	"go-masters/final_project/reviews/internal/llm"
+	"go-masters/final_project/reviews/internal/llm/ollamatest"
+
+	"github.com/ollama/ollama/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{name: "совпадают", a: []float32{1, 2, 3}, b: []float32{2, 4, 6}, want: 1},
+		{name: "ортогональны", a: []float32{1, 0}, b: []float32{0, 1}, want: 0},
+		{name: "противоположны", a: []float32{1, 1}, b: []float32{-1, -1}, want: -1},
+		{name: "нулевой вектор", a: []float32{0, 0}, b: []float32{1, 1}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Cosine(tt.a, tt.b)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+
+	_, err := Cosine([]float32{1}, []float32{1, 2})
+	assert.ErrorIs(t, err, ErrDimensions)
+}
+
+func TestOllamaEmbedder(t *testing.T) {
+	srv := ollamatest.NewServer(func(api.GenerateRequest) (string, error) {
+		return "", nil
+	})
+	srv.HandleEmbed(func(text string) []float32 {
+		return []float32{float32(len(text)), 1}
+	})
+	defer srv.Close()
+
+	client, err := llm.New(llm.Config{Endpoint: srv.URL})
+	require.NoError(t, err)
+	e := NewEmbedder(client, "embed")
+
+	vectors, err := e.Embed(context.Background(), []string{"a", "bb"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 1}, {2, 1}}, vectors)
+
+	// Тексты пакета передаются одним запросом
+	reqs := srv.EmbedRequests()
+	require.Len(t, reqs, 1)
+	assert.Equal(t, "embed", reqs[0].Model)
+}
@@ -0,0 +1,209 @@
+package trend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/metrics"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/rating"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Параметры обнаружения по умолчанию.
+const (
+	DefaultWindow       = 7 * 24 * time.Hour
+	DefaultBaseline     = 28 * 24 * time.Hour
+	DefaultDrop         = 1.0
+	DefaultMinReviews   = 5
+	DefaultPollInterval = time.Minute
+	// Запас, на который интервалы поиска изменений перекрываются:
+	// транзакция, начатая до проверки, может зафиксироваться после нее
+	// с более ранним временем изменения.
+	changesOverlap = time.Minute
+)
+
+// Detector обнаруживает резкое падение рейтинга: средняя оценка отзывов
+// за окно Window сравнивается со средней оценкой за предшествующий окну
+// период Baseline. Окно и период отсчитываются целыми днями.
+type Detector struct {
+	Window   time.Duration
+	Baseline time.Duration
+	// Снижение средней оценки, начиная с которого падение считается резким.
+	Drop float64
+	// Минимальное число отзывов в окне и в предшествующем периоде:
+	// средняя оценка по нескольким отзывам неустойчива.
+	MinReviews int
+}
+
+// bounds возвращает начало предшествующего периода, начало окна
+// и конец окна (начало следующего дня) на момент now.
+func (d Detector) bounds(now time.Time) (base, window, end time.Time) {
+	end = db.Day(now).AddDate(0, 0, 1)
+	window = db.Day(end.Add(-d.Window))
+	base = db.Day(window.Add(-d.Baseline))
+	return base, window, end
+}
+
+// Detect проверяет дневные точки товара на момент now.
+func (d Detector) Detect(productID string, days []Point, now time.Time) (models.RatingAnomaly, bool) {
+	baseStart, windowStart, end := d.bounds(now)
+
+	var base, cur rating.Aggregate
+	for _, p := range days {
+		switch {
+		case p.Timestamp.Before(baseStart) || !p.Timestamp.Before(end):
+		case p.Timestamp.Before(windowStart):
+			base = base.Add(p.Rating)
+		default:
+			cur = cur.Add(p.Rating)
+		}
+	}
+	if base.Count() < d.MinReviews || cur.Count() < d.MinReviews {
+		return models.RatingAnomaly{}, false
+	}
+	if base.Mean()-cur.Mean() < d.Drop {
+		return models.RatingAnomaly{}, false
+	}
+	return models.RatingAnomaly{
+		ProductID:       productID,
+		WindowStart:     windowStart,
+		Score:           cur.Mean(),
+		Reviews:         cur.Count(),
+		Baseline:        base.Mean(),
+		BaselineReviews: base.Count(),
+		DetectedAt:      now,
+	}, true
+}
+
+// Store - хранилище дневных агрегатов рейтинга.
+type Store interface {
+	RatingHistory(ctx context.Context, productID string, from, to time.Time) ([]db.DailyRating, error)
+	// ChangedRatings возвращает товары, дневные агрегаты которых
+	// изменились начиная с момента since.
+	ChangedRatings(ctx context.Context, since time.Time) ([]string, error)
+}
+
+// Monitor периодически проверяет товары, рейтинг которых изменился,
+// и сообщает о резком падении рейтинга: пишет предупреждение в журнал,
+// учитывает его в метриках и вызывает обработчики OnAnomaly.
+type Monitor struct {
+	store Store
+	Detector
+	PollInterval time.Duration
+
+	handlers []func(context.Context, models.RatingAnomaly)
+	// Момент, начиная с которого ищутся изменения.
+	since time.Time
+	// Время последнего события по товару. Пока падение остается в окне
+	// наблюдения, событие по товару повторно не отправляется.
+	reported map[string]time.Time
+}
+
+func NewMonitor(store Store) *Monitor {
+	return &Monitor{
+		store: store,
+		Detector: Detector{
+			Window:     DefaultWindow,
+			Baseline:   DefaultBaseline,
+			Drop:       DefaultDrop,
+			MinReviews: DefaultMinReviews,
+		},
+		PollInterval: DefaultPollInterval,
+		reported:     make(map[string]time.Time),
+	}
+}
+
+// OnAnomaly добавляет обработчик событий падения рейтинга.
+// Обработчики вызываются последовательно в горутине Run
+// и должны быть добавлены до ее запуска.
+func (m *Monitor) OnAnomaly(fn func(context.Context, models.RatingAnomaly)) {
+	m.handlers = append(m.handlers, fn)
+}
+
+// Run проверяет рейтинги товаров до отмены контекста.
+func (m *Monitor) Run(ctx context.Context) {
+	log.Info().
+		Dur("window", m.Window).
+		Float64("drop", m.Drop).
+		Msg("Запуск отслеживания рейтинга товаров")
+
+	ticker := time.NewTicker(m.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := m.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("Ошибка проверки рейтинга товаров")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Остановка отслеживания рейтинга товаров")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce проверяет товары, рейтинг которых изменился после предыдущей
+// проверки (при первой проверке - за окно наблюдения). Возвращает число
+// обнаруженных падений. Не вызывается одновременно из нескольких горутин.
+func (m *Monitor) RunOnce(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	since := m.since
+	if since.IsZero() {
+		since = now.Add(-m.Window)
+	}
+	products, err := m.store.ChangedRatings(ctx, since)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка выборки товаров: %w", err)
+	}
+
+	for id, at := range m.reported {
+		if now.Sub(at) >= m.Window {
+			delete(m.reported, id)
+		}
+	}
+
+	found := 0
+	baseStart, _, end := m.bounds(now)
+	for _, id := range products {
+		if _, ok := m.reported[id]; ok {
+			continue
+		}
+		daily, err := m.store.RatingHistory(ctx, id, baseStart, end)
+		// Товар удален после изменения рейтинга.
+		if errors.Is(err, db.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return found, fmt.Errorf("товар %s: %w", id, err)
+		}
+		a, ok := m.Detect(id, Series(daily, Day), now)
+		if !ok {
+			continue
+		}
+		found++
+		m.reported[id] = now
+		m.emit(ctx, a)
+	}
+	m.since = now.Add(-changesOverlap)
+	return found, nil
+}
+
+func (m *Monitor) emit(ctx context.Context, a models.RatingAnomaly) {
+	log.Warn().
+		Str("product_id", a.ProductID).
+		Float64("score", a.Score).
+		Float64("baseline", a.Baseline).
+		Int("reviews", a.Reviews).
+		Msg("Резкое падение рейтинга товара")
+	metrics.ObserveRatingAnomaly()
+	for _, fn := range m.handlers {
+		fn(ctx, a)
+	}
+}
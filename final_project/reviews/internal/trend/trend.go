@@ -0,0 +1,93 @@
+// Package trend строит историю рейтинга товаров и обнаруживает резкое
+// падение рейтинга.
+//
+// Хранилище поддерживает дневные агрегаты классифицированных отзывов
+// по дню создания отзыва (см. db.DailyRating), недельные периоды сводятся
+// из дневных. Точка истории - измерение generics.Measure: момент начала
+// периода и средняя оценка отзывов, оставленных за период.
+package trend
+
+import (
+	"time"
+
+	generics "go-masters/03-generics"
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/rating"
+)
+
+// Длины периодов истории.
+const (
+	Day  = "day"
+	Week = "week"
+)
+
+// Metric - название показателя точек истории.
+const Metric = "rating"
+
+// ValidBucket сообщает, поддерживается ли длина периода.
+func ValidBucket(bucket string) bool {
+	return bucket == Day || bucket == Week
+}
+
+// Start возвращает начало периода bucket (UTC), содержащего момент t.
+// Неделя начинается с понедельника.
+func Start(t time.Time, bucket string) time.Time {
+	day := db.Day(t)
+	if bucket != Week {
+		return day
+	}
+	// Дни недели отсчитываются от воскресенья.
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// Point - рейтинг товара за период.
+type Point struct {
+	// Timestamp - начало периода, Value - средняя оценка отзывов периода
+	// (см. rating.Aggregate.Mean).
+	generics.Measure[float64]
+	Rating rating.Aggregate
+}
+
+// Series сводит дневные агрегаты, упорядоченные по дням, в точки
+// периодов bucket.
+func Series(daily []db.DailyRating, bucket string) []Point {
+	res := []Point{}
+	for _, d := range daily {
+		start := Start(d.Day, bucket)
+		if n := len(res); n > 0 && res[n-1].Timestamp.Equal(start) {
+			res[n-1].Rating = res[n-1].Rating.Add(d.Rating)
+			continue
+		}
+		res = append(res, Point{
+			Measure: generics.Measure[float64]{Timestamp: start, Metric: Metric},
+			Rating:  d.Rating,
+		})
+	}
+	for i := range res {
+		res[i].Value = res[i].Rating.Mean()
+	}
+	return res
+}
+
+// History возвращает историю рейтинга товара по точкам периодов bucket.
+func History(productID, bucket string, points []Point) models.RatingHistory {
+	h := models.RatingHistory{
+		ProductID: productID,
+		Bucket:    bucket,
+		Points:    make([]models.RatingPoint, len(points)),
+	}
+	for i, p := range points {
+		h.Points[i] = models.RatingPoint{
+			Start:   p.Timestamp,
+			Score:   p.Value,
+			Reviews: p.Rating.Count(),
+			Distribution: models.Distribution{
+				Positive: p.Rating.Positive,
+				Neutral:  p.Rating.Neutral,
+				Negative: p.Rating.Negative,
+			},
+		}
+	}
+	return h
+}
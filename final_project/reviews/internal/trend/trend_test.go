@@ -0,0 +1,160 @@
+package trend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/jobs"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/rating"
+	"go-masters/final_project/reviews/internal/sentiment/lexicon"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// agg возвращает агрегат n отзывов с меткой label, оставленных в момент t.
+func agg(label string, n int, t time.Time) rating.Aggregate {
+	var a rating.Aggregate
+	for range n {
+		a = a.Add(rating.Of(models.Sentiment{Status: models.SentimentDone, Label: label, Confidence: 1}, t))
+	}
+	return a
+}
+
+func TestStart(t *testing.T) {
+	// 2026-10-21 - среда.
+	wed := time.Date(2026, 10, 21, 15, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC), Start(wed, Day))
+	assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), Start(wed, Week))
+
+	// Воскресенье относится к неделе, начатой в понедельник.
+	sun := time.Date(2026, 10, 25, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), Start(sun, Week))
+	// Момент в другом часовом поясе приводится к UTC.
+	msk := time.Date(2026, 10, 19, 1, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	assert.Equal(t, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), Start(msk, Week))
+}
+
+func TestSeries(t *testing.T) {
+	mon := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	daily := []db.DailyRating{
+		{Day: mon, Rating: agg("positive", 2, mon)},
+		{Day: mon.AddDate(0, 0, 3), Rating: agg("negative", 2, mon)},
+		{Day: mon.AddDate(0, 0, 7), Rating: agg("neutral", 1, mon)},
+	}
+
+	days := Series(daily, Day)
+	require.Len(t, days, 3)
+	assert.Equal(t, 5.0, days[0].Value)
+	assert.Equal(t, Metric, days[0].Metric)
+
+	weeks := Series(daily, Week)
+	require.Len(t, weeks, 2)
+	assert.Equal(t, mon, weeks[0].Timestamp)
+	assert.Equal(t, 3.0, weeks[0].Value)
+	assert.Equal(t, 4, weeks[0].Rating.Count())
+	assert.Equal(t, mon.AddDate(0, 0, 7), weeks[1].Timestamp)
+
+	h := History("p", Week, weeks)
+	assert.Equal(t, models.RatingPoint{
+		Start:        mon,
+		Score:        3,
+		Reviews:      4,
+		Distribution: models.Distribution{Positive: 2, Negative: 2},
+	}, h.Points[0])
+	assert.Empty(t, Series(nil, Day))
+}
+
+func TestDetector(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	d := Detector{Window: 7 * 24 * time.Hour, Baseline: 28 * 24 * time.Hour, Drop: 1, MinReviews: 3}
+	point := func(daysAgo int, a rating.Aggregate) Point {
+		var p Point
+		p.Timestamp = db.Day(now).AddDate(0, 0, -daysAgo)
+		p.Rating = a
+		return p
+	}
+
+	base := point(10, agg("positive", 3, now))
+	// Окно - 7 дней, включая текущий.
+	a, ok := d.Detect("p", []Point{base, point(6, agg("negative", 3, now))}, now)
+	require.True(t, ok)
+	assert.Equal(t, models.RatingAnomaly{
+		ProductID:       "p",
+		WindowStart:     db.Day(now).AddDate(0, 0, -6),
+		Score:           1,
+		Reviews:         3,
+		Baseline:        5,
+		BaselineReviews: 3,
+		DetectedAt:      now,
+	}, a)
+
+	// Отзывы седьмого дня назад относятся к предшествующему периоду.
+	_, ok = d.Detect("p", []Point{base, point(7, agg("negative", 3, now))}, now)
+	assert.False(t, ok)
+	// Мало отзывов в окне.
+	_, ok = d.Detect("p", []Point{base, point(0, agg("negative", 2, now))}, now)
+	assert.False(t, ok)
+	// Снижение меньше порога.
+	_, ok = d.Detect("p", []Point{base, point(0, agg("positive", 2, now).Add(agg("neutral", 1, now)))}, now)
+	assert.False(t, ok)
+	// Отзывы старше предшествующего периода не учитываются.
+	_, ok = d.Detect("p", []Point{point(40, agg("positive", 3, now)), point(0, agg("negative", 3, now))}, now)
+	assert.False(t, ok)
+}
+
+func TestMonitor(t *testing.T) {
+	ctx := context.Background()
+	m := memdb.New()
+	p, err := m.AddProduct(ctx, models.Product{Name: "Чайник"})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	var reviews []models.Review
+	for i := range 3 {
+		reviews = append(reviews,
+			models.Review{ProductID: p.ID, Author: "Иван", Text: "Отличный чайник, рекомендую", Rating: 5, CreatedAt: now.AddDate(0, 0, -10-i)},
+			models.Review{ProductID: p.ID, Author: "Петр", Text: "Ужасный чайник, сломался", Rating: 1, CreatedAt: now.Add(-time.Duration(i) * time.Minute)},
+		)
+	}
+	_, err = m.ImportReviews(ctx, reviews)
+	require.NoError(t, err)
+
+	mon := NewMonitor(m)
+	mon.MinReviews = 3
+	var got []models.RatingAnomaly
+	mon.OnAnomaly(func(_ context.Context, a models.RatingAnomaly) {
+		got = append(got, a)
+	})
+
+	// Отзывы еще не классифицированы.
+	n, err := mon.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = jobs.NewPool(m, lexicon.New()).RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 6, n)
+
+	n, err = mon.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Len(t, got, 1)
+	assert.Equal(t, p.ID, got[0].ProductID)
+	assert.Equal(t, 3, got[0].Reviews)
+	assert.Greater(t, got[0].Baseline-got[0].Score, mon.Drop)
+
+	// Пока падение в окне, событие не повторяется.
+	_, err = m.AddReview(ctx, models.Review{ProductID: p.ID, Author: "Анна", Text: "Ужасный, сломался", Rating: 1})
+	require.NoError(t, err)
+	_, err = jobs.NewPool(m, lexicon.New()).RunOnce(ctx)
+	require.NoError(t, err)
+	n, err = mon.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Len(t, got, 1)
+}
@@ -0,0 +1,304 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/metrics"
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Параметры отправки по умолчанию.
+const (
+	DefaultWorkers      = 4
+	DefaultBatchSize    = 32
+	DefaultPollInterval = time.Second
+	DefaultLease        = time.Minute
+	DefaultTimeout      = 10 * time.Second
+	DefaultMaxAttempts  = 8
+	DefaultMinBackoff   = 10 * time.Second
+	DefaultMaxBackoff   = time.Hour
+	// Часть тела ответа с ошибкой, сохраняемая в доставке.
+	maxErrorBody = 512
+	// Длина генерируемого секрета в байтах.
+	secretBytes = 32
+)
+
+// Dispatcher управляет подписками и отправляет доставки подписчикам.
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+
+	// Число одновременных запросов; значения меньше 1 означают один запрос.
+	Workers      int
+	BatchSize    int
+	PollInterval time.Duration
+	// Наименьшее время аренды доставок. Пакет арендуется целиком, поэтому
+	// аренда увеличивается до времени отправки всего пакета (см. lease).
+	Lease time.Duration
+	// Ограничение времени одного запроса к подписчику.
+	Timeout     time.Duration
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+func NewDispatcher(store Store) *Dispatcher {
+	return &Dispatcher{
+		store:        store,
+		client:       &http.Client{},
+		Workers:      DefaultWorkers,
+		BatchSize:    DefaultBatchSize,
+		PollInterval: DefaultPollInterval,
+		Lease:        DefaultLease,
+		Timeout:      DefaultTimeout,
+		MaxAttempts:  DefaultMaxAttempts,
+		MinBackoff:   DefaultMinBackoff,
+		MaxBackoff:   DefaultMaxBackoff,
+	}
+}
+
+// Create проверяет и сохраняет подписку. Если секрет не задан,
+// он генерируется; сохраненная подписка возвращается с секретом.
+func (d *Dispatcher) Create(ctx context.Context, w Webhook) (Webhook, error) {
+	if err := w.Validate(); err != nil {
+		return Webhook{}, err
+	}
+	if w.Secret == "" {
+		b := make([]byte, secretBytes)
+		rand.Read(b)
+		w.Secret = hex.EncodeToString(b)
+	}
+	return d.store.AddWebhook(ctx, w)
+}
+
+// Update проверяет и изменяет подписку. Пустой секрет не меняет прежний.
+func (d *Dispatcher) Update(ctx context.Context, w Webhook) (Webhook, error) {
+	if err := w.Validate(); err != nil {
+		return Webhook{}, err
+	}
+	if w.Secret == "" {
+		old, err := d.store.GetWebhook(ctx, w.ID)
+		if err != nil {
+			return Webhook{}, err
+		}
+		w.Secret = old.Secret
+	}
+	return d.store.UpdateWebhook(ctx, w)
+}
+
+func (d *Dispatcher) Get(ctx context.Context, id string) (Webhook, error) {
+	return d.store.GetWebhook(ctx, id)
+}
+
+func (d *Dispatcher) List(ctx context.Context) ([]Webhook, error) {
+	return d.store.ListWebhooks(ctx)
+}
+
+func (d *Dispatcher) Delete(ctx context.Context, id string) error {
+	return d.store.DeleteWebhook(ctx, id)
+}
+
+// Deliveries возвращает до limit последних доставок подписки.
+func (d *Dispatcher) Deliveries(ctx context.Context, webhookID string, limit int) ([]Delivery, error) {
+	if _, err := d.store.GetWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	return d.store.ListDeliveries(ctx, webhookID, limit)
+}
+
+// Replay ставит событие доставки в очередь повторно.
+func (d *Dispatcher) Replay(ctx context.Context, webhookID, deliveryID string) (Delivery, error) {
+	return d.store.ReplayDelivery(ctx, webhookID, deliveryID)
+}
+
+// NotifyRatingDrop сохраняет доставки события падения рейтинга товара.
+// Подходит как обработчик trend.Monitor.OnAnomaly.
+func (d *Dispatcher) NotifyRatingDrop(ctx context.Context, a models.RatingAnomaly) {
+	if err := d.store.Publish(ctx, RatingDropped(a)); err != nil {
+		log.Error().Err(err).Str("product_id", a.ProductID).Msg("Ошибка сохранения уведомления о падении рейтинга")
+	}
+}
+
+// Run отправляет доставки до отмены контекста.
+func (d *Dispatcher) Run(ctx context.Context) {
+	log.Info().Int("workers", d.Workers).Msg("Запуск отправки уведомлений")
+
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := d.RunOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Error().Err(err).Msg("Ошибка отправки уведомлений")
+			}
+			// Если пакет заполнен целиком, сразу выбираем следующий.
+			if n < d.BatchSize || err != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Остановка отправки уведомлений")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lease возвращает время аренды пакета. Доставки пакета ждут свободного
+// воркера и не должны быть захвачены другим экземпляром сервиса, пока стоят
+// в очереди: аренда покрывает ceil(BatchSize/Workers) запросов к подписчикам
+// с запасом еще на один.
+func (d *Dispatcher) lease() time.Duration {
+	workers := max(d.Workers, 1)
+	rounds := (max(d.BatchSize, 1) + workers - 1) / workers
+	return max(d.Lease, time.Duration(rounds+1)*d.Timeout)
+}
+
+// RunOnce выбирает пакет доставок, отправляет их не более чем Workers
+// запросами одновременно и ждет завершения. Возвращает количество
+// выбранных доставок.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	deliveries, err := d.store.ClaimDeliveries(ctx, d.BatchSize, d.lease())
+	if err != nil {
+		return 0, fmt.Errorf("ошибка выборки доставок: %w", err)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs error
+		sem  = make(chan struct{}, max(d.Workers, 1))
+	)
+	for _, dl := range deliveries {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := d.process(ctx, dl); err != nil {
+				mu.Lock()
+				errs = errors.Join(errs, fmt.Errorf("доставка %s: %w", dl.ID, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return len(deliveries), errs
+}
+
+// process отправляет доставку и сохраняет результат попытки.
+func (d *Dispatcher) process(ctx context.Context, dl Delivery) error {
+	w, err := d.store.GetWebhook(ctx, dl.WebhookID)
+	// Подписка удалена вместе с доставками.
+	if errors.Is(err, db.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	dl.ResponseStatus, err = d.send(ctx, w, dl)
+	// Если отправка остановлена, доставка будет выбрана повторно
+	// после истечения аренды.
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	switch {
+	case err == nil:
+		metrics.ObserveWebhookDelivery(metrics.WebhookDelivered)
+		dl.Status = StatusDelivered
+		dl.Error = ""
+	case dl.Attempts >= d.MaxAttempts:
+		metrics.ObserveWebhookDelivery(metrics.WebhookFailed)
+		log.Error().
+			Err(err).
+			Str("webhook_id", w.ID).
+			Str("delivery_id", dl.ID).
+			Int("attempt", dl.Attempts).
+			Msg("Попытки доставки уведомления исчерпаны")
+		dl.Status = StatusFailed
+		dl.Error = err.Error()
+	default:
+		metrics.ObserveWebhookDelivery(metrics.WebhookRetry)
+		dl.NextAttemptAt = time.Now().Add(d.backoff(dl.Attempts))
+		log.Warn().
+			Err(err).
+			Str("webhook_id", w.ID).
+			Str("delivery_id", dl.ID).
+			Int("attempt", dl.Attempts).
+			Time("next_attempt", dl.NextAttemptAt).
+			Msg("Не удалось доставить уведомление, повтор запланирован")
+		dl.Status = StatusPending
+		dl.Error = err.Error()
+	}
+	err = d.store.SaveDelivery(ctx, dl)
+	// Аренда истекла, и доставка захвачена заново: результат новой попытки
+	// не перезаписывается.
+	if errors.Is(err, db.ErrNotFound) {
+		log.Warn().
+			Str("webhook_id", w.ID).
+			Str("delivery_id", dl.ID).
+			Int("attempt", dl.Attempts).
+			Msg("Результат попытки доставки устарел и не сохранен")
+		return nil
+	}
+	return err
+}
+
+// send отправляет тело доставки подписчику и возвращает код ответа.
+// Ответ с кодом не из диапазона 2xx считается ошибкой.
+func (d *Dispatcher) send(ctx context.Context, w Webhook, dl Delivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(dl.Payload))
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, dl.Event)
+	req.Header.Set(HeaderDelivery, dl.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(w.Secret, now, dl.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff - экспоненциальная задержка перед повторной попыткой.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	b := d.MinBackoff
+	for i := 1; i < attempt && b < d.MaxBackoff; i++ {
+		b *= 2
+	}
+	return min(b, d.MaxBackoff)
+}
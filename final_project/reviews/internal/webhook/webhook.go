@@ -0,0 +1,202 @@
+// Package webhook уведомляет внешние системы о событиях отзывов.
+//
+// Подписка (Webhook) задает адрес, секрет и фильтры по событиям, товарам
+// и меткам настроения. Хранилище сохраняет доставки события всем подходящим
+// подпискам в той же транзакции, что и само событие (например, результат
+// классификации отзыва), поэтому события не теряются при остановке сервиса.
+// Dispatcher отправляет доставки POST-запросами с подписью HMAC-SHA256
+// и повторяет неудачные попытки с экспоненциальной задержкой. Доставки
+// хранятся как журнал и могут быть отправлены повторно.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"go-masters/final_project/reviews/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// События.
+const (
+	// Отзыв классифицирован очередью классификации. Повторная
+	// классификация (reclassify) событий не создает.
+	EventReviewClassified = "review.classified"
+	// Обнаружено резкое падение рейтинга товара (см. пакет trend).
+	EventRatingDropped = "rating.dropped"
+)
+
+// Состояния доставки.
+const (
+	// Доставка ожидает первой или повторной попытки.
+	StatusPending = "pending"
+	// Подписчик ответил кодом 2xx.
+	StatusDelivered = "delivered"
+	// Попытки исчерпаны.
+	StatusFailed = "failed"
+)
+
+// Заголовки запроса к подписчику.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// ErrInvalid - некорректная подписка.
+var ErrInvalid = errors.New("некорректная подписка")
+
+// labels - метки настроения, допустимые в фильтре подписки.
+var labels = []string{"positive", "neutral", "negative"}
+
+// Webhook - подписка на события.
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Ключ подписи запросов. Возвращается только при создании подписки.
+	Secret string `json:"secret,omitempty"`
+	// Фильтры; пустой фильтр не ограничивает события.
+	Events     []string `json:"events,omitempty"`
+	ProductIDs []string `json:"product_ids,omitempty"`
+	// Метки настроения отзыва; события без метки (падение рейтинга)
+	// фильтром по меткам не отсекаются.
+	Labels    []string  `json:"labels,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate проверяет адрес и фильтры подписки.
+func (w Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Join(ErrInvalid, errors.New("url должен быть абсолютным адресом http или https"))
+	}
+	for _, e := range w.Events {
+		if e != EventReviewClassified && e != EventRatingDropped {
+			return errors.Join(ErrInvalid, errors.New("неизвестное событие "+e))
+		}
+	}
+	for _, l := range w.Labels {
+		if !slices.Contains(labels, l) {
+			return errors.Join(ErrInvalid, errors.New("неизвестная метка "+l))
+		}
+	}
+	return nil
+}
+
+// Match сообщает, подходит ли событие под фильтры подписки.
+func (w Webhook) Match(e Event) bool {
+	return (len(w.Events) == 0 || slices.Contains(w.Events, e.Type)) &&
+		(len(w.ProductIDs) == 0 || slices.Contains(w.ProductIDs, e.ProductID)) &&
+		(e.Label == "" || len(w.Labels) == 0 || slices.Contains(w.Labels, e.Label))
+}
+
+// Event - событие для подписчиков.
+type Event struct {
+	ID   string
+	Type string
+	// Товар и метка настроения для фильтров подписок.
+	ProductID string
+	Label     string
+	// Тело запроса к подписчику.
+	Payload json.RawMessage
+}
+
+// payload - тело запроса к подписчику.
+type payload struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+func newEvent(typ, productID, label string, data any) Event {
+	e := Event{ID: uuid.NewString(), Type: typ, ProductID: productID, Label: label}
+	// Данные - модели без каналов и функций, ошибки кодирования нет.
+	e.Payload, _ = json.Marshal(payload{ID: e.ID, Type: typ, CreatedAt: time.Now().UTC(), Data: data})
+	return e
+}
+
+// ReviewClassified возвращает событие классификации отзыва.
+func ReviewClassified(r models.Review) Event {
+	return newEvent(EventReviewClassified, r.ProductID, r.Sentiment.Label, r)
+}
+
+// RatingDropped возвращает событие падения рейтинга товара.
+func RatingDropped(a models.RatingAnomaly) Event {
+	return newEvent(EventRatingDropped, a.ProductID, "", a)
+}
+
+// Delivery - доставка события подписчику.
+type Delivery struct {
+	ID        string `json:"id"`
+	WebhookID string `json:"webhook_id"`
+	// Идентификатор события; совпадает у повторных отправок одного события,
+	// по нему подписчик отбрасывает дубликаты.
+	EventID string          `json:"event_id"`
+	Event   string          `json:"event"`
+	Payload json.RawMessage `json:"payload"`
+	Status  string          `json:"status"`
+	// Число выполненных попыток.
+	Attempts int `json:"attempts"`
+	// Время следующей попытки ожидающей доставки.
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	// Код ответа и ошибка последней попытки.
+	ResponseStatus int       `json:"response_status,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	// Token - идентификатор захвата доставки. Если аренда истекла и доставка
+	// захвачена заново, результат устаревшей попытки не сохраняется.
+	Token string `json:"-"`
+}
+
+// Store - хранилище подписок и доставок. Отсутствующие записи
+// обозначаются ошибкой db.ErrNotFound.
+type Store interface {
+	AddWebhook(context.Context, Webhook) (Webhook, error)
+	GetWebhook(ctx context.Context, id string) (Webhook, error)
+	// ListWebhooks возвращает подписки в порядке создания.
+	ListWebhooks(context.Context) ([]Webhook, error)
+	// UpdateWebhook изменяет адрес, секрет и фильтры подписки.
+	UpdateWebhook(context.Context, Webhook) (Webhook, error)
+	// DeleteWebhook удаляет подписку вместе с ее доставками.
+	DeleteWebhook(ctx context.Context, id string) error
+
+	// Publish сохраняет доставки события подходящим подпискам.
+	Publish(context.Context, Event) error
+	// ClaimDeliveries захватывает до limit ожидающих доставок, время
+	// попытки которых наступило, на время lease и увеличивает число попыток.
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error)
+	// SaveDelivery сохраняет результат попытки: состояние, время следующей
+	// попытки, код ответа и ошибку. Если доставка удалена или захвачена
+	// заново (токен не совпадает), возвращает db.ErrNotFound.
+	SaveDelivery(context.Context, Delivery) error
+	// ListDeliveries возвращает до limit последних доставок подписки,
+	// начиная с новых.
+	ListDeliveries(ctx context.Context, webhookID string, limit int) ([]Delivery, error)
+	// ReplayDelivery создает новую ожидающую доставку с тем же событием.
+	ReplayDelivery(ctx context.Context, webhookID, deliveryID string) (Delivery, error)
+}
+
+// Sign возвращает подпись тела запроса: HMAC-SHA256 ключом secret
+// от строки "<timestamp>.<body>" в шестнадцатеричном виде с префиксом
+// "sha256=". Время в подписи защищает от повторной отправки
+// перехваченного запроса.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,178 @@
+package webhook_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-masters/final_project/reviews/internal/db"
+	"go-masters/final_project/reviews/internal/db/memdb"
+	"go-masters/final_project/reviews/internal/models"
+	"go-masters/final_project/reviews/internal/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	w := webhook.Webhook{
+		Events:     []string{webhook.EventReviewClassified, webhook.EventRatingDropped},
+		ProductIDs: []string{"p1"},
+		Labels:     []string{"negative"},
+	}
+
+	tests := []struct {
+		name  string
+		event webhook.Event
+		want  bool
+	}{
+		{"match", webhook.Event{Type: webhook.EventReviewClassified, ProductID: "p1", Label: "negative"}, true},
+		{"other product", webhook.Event{Type: webhook.EventReviewClassified, ProductID: "p2", Label: "negative"}, false},
+		{"other label", webhook.Event{Type: webhook.EventReviewClassified, ProductID: "p1", Label: "positive"}, false},
+		{"no label", webhook.Event{Type: webhook.EventRatingDropped, ProductID: "p1"}, true},
+		{"other event", webhook.Event{Type: "review.deleted", ProductID: "p1"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, w.Match(tt.event))
+		})
+	}
+	assert.True(t, webhook.Webhook{}.Match(webhook.Event{Type: webhook.EventRatingDropped, ProductID: "p2"}))
+}
+
+func TestSign(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"1"}`)
+
+	sig := webhook.Sign("key", ts, body)
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, sig)
+	assert.Equal(t, sig, webhook.Sign("key", ts, body))
+	assert.NotEqual(t, sig, webhook.Sign("other", ts, body))
+	assert.NotEqual(t, sig, webhook.Sign("key", ts.Add(time.Second), body))
+}
+
+func TestNotifyRatingDrop(t *testing.T) {
+	ctx := context.Background()
+	m := memdb.New()
+	d := webhook.NewDispatcher(m)
+	d.MinBackoff = time.Hour
+
+	var got []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(webhook.HeaderEvent))
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(ts.Close)
+
+	// Подписка только на классификацию не получает падение рейтинга
+	_, err := d.Create(ctx, webhook.Webhook{URL: ts.URL, Events: []string{webhook.EventReviewClassified}})
+	require.NoError(t, err)
+	w, err := d.Create(ctx, webhook.Webhook{URL: ts.URL, Labels: []string{"negative"}})
+	require.NoError(t, err)
+
+	d.NotifyRatingDrop(ctx, models.RatingAnomaly{ProductID: "p1", Score: 2, Baseline: 4.5})
+	n, err := d.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{webhook.EventRatingDropped}, got)
+
+	// Неудачная попытка откладывается на MinBackoff
+	n, err = d.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	deliveries, err := d.Deliveries(ctx, w.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, webhook.StatusPending, deliveries[0].Status)
+	assert.Equal(t, http.StatusBadGateway, deliveries[0].ResponseStatus)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), deliveries[0].NextAttemptAt, time.Minute)
+}
+
+func TestRunOnce_NoWorkers(t *testing.T) {
+	ctx := context.Background()
+	m := memdb.New()
+	d := webhook.NewDispatcher(m)
+	// Некорректное число воркеров не должно блокировать отправку.
+	d.Workers = 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	t.Cleanup(ts.Close)
+	_, err := d.Create(ctx, webhook.Webhook{URL: ts.URL})
+	require.NoError(t, err)
+	d.NotifyRatingDrop(ctx, models.RatingAnomaly{ProductID: "p1", Score: 2, Baseline: 4.5})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		n, err := d.RunOnce(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunOnce заблокирован")
+	}
+}
+
+func TestSaveDelivery_StaleLease(t *testing.T) {
+	ctx := context.Background()
+	m := memdb.New()
+	d := webhook.NewDispatcher(m)
+	w, err := d.Create(ctx, webhook.Webhook{URL: "http://localhost"})
+	require.NoError(t, err)
+	d.NotifyRatingDrop(ctx, models.RatingAnomaly{ProductID: "p1", Score: 2, Baseline: 4.5})
+
+	// Аренда истекла, и доставку захватила следующая попытка
+	stale, err := m.ClaimDeliveries(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, stale, 1)
+	fresh, err := m.ClaimDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, fresh, 1)
+
+	fresh[0].Status = webhook.StatusDelivered
+	require.NoError(t, m.SaveDelivery(ctx, fresh[0]))
+	stale[0].Status = webhook.StatusFailed
+	assert.ErrorIs(t, m.SaveDelivery(ctx, stale[0]), db.ErrNotFound)
+
+	deliveries, err := d.Deliveries(ctx, w.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, webhook.StatusDelivered, deliveries[0].Status)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+}
+
+// leaseStore запоминает время аренды, с которым выбираются доставки.
+type leaseStore struct {
+	webhook.Store
+	lease time.Duration
+}
+
+func (s *leaseStore) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error) {
+	s.lease = lease
+	return s.Store.ClaimDeliveries(ctx, limit, lease)
+}
+
+func TestRunOnce_LeaseCoversBatch(t *testing.T) {
+	store := &leaseStore{Store: memdb.New()}
+	d := webhook.NewDispatcher(store)
+	d.Workers = 4
+	d.BatchSize = 32
+	d.Timeout = 10 * time.Second
+	d.Lease = time.Minute
+
+	// 32 доставки на 4 воркерах - 8 запросов подряд и один в запас.
+	_, err := d.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, store.lease)
+
+	// Большая аренда из настроек не уменьшается.
+	d.Lease = time.Hour
+	_, err = d.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, store.lease)
+}
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+create table products (
+    id uuid primary key,
+    name text not null,
+    created_at timestamptz not null default now()
+);
+
+create table reviews (
+    id uuid primary key,
+    product_id uuid not null references products (id) on delete cascade,
+    author text not null,
+    text text not null,
+    rating smallint not null check (rating between 1 and 5),
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+
+create index reviews_product_id_idx on reviews (product_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table reviews;
+drop table products;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table reviews
+    add column sentiment_status text not null default 'pending',
+    add column sentiment_label text not null default '',
+    add column sentiment_confidence double precision not null default 0;
+
+-- Очередь фоновой классификации настроения отзывов.
+-- На каждый отзыв приходится не более одного задания.
+create table classification_jobs (
+    review_id uuid primary key references reviews (id) on delete cascade,
+    -- queued или dead (попытки исчерпаны).
+    state text not null default 'queued',
+    attempts integer not null default 0,
+    -- Время следующей попытки (также используется как аренда задания).
+    run_at timestamptz not null default now(),
+    -- Идентификатор текущего захвата задания воркером.
+    token uuid,
+    last_error text,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+
+create index classification_jobs_queued_idx on classification_jobs (run_at)
+    where state = 'queued';
+
+-- Существующие отзывы ставятся в очередь.
+insert into classification_jobs (review_id) select id from reviews;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table classification_jobs;
+
+alter table reviews
+    drop column sentiment_status,
+    drop column sentiment_label,
+    drop column sentiment_confidence;
+-- +goose StatementEnd
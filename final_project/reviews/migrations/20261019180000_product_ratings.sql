@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Агрегаты рейтинга товаров по настроению отзывов (см. пакет internal/rating).
+-- Обновляются инкрементально при изменении настроения отзыва.
+create table product_ratings (
+    product_id uuid primary key references products (id) on delete cascade,
+    positive integer not null default 0,
+    neutral integer not null default 0,
+    negative integer not null default 0,
+    -- Суммы весов и взвешенных оценок относительно эпохи 2025-01-01.
+    weight_sum double precision not null default 0,
+    score_sum double precision not null default 0,
+    updated_at timestamptz not null default now()
+);
+
+-- Заполнение по уже классифицированным отзывам. Формула веса совпадает
+-- с rating.Of: уверенность * 2^((created_at - эпоха) / 180 дней).
+insert into product_ratings (product_id, positive, neutral, negative, weight_sum, score_sum)
+select
+    product_id,
+    count(*) filter (where sentiment_label = 'positive'),
+    count(*) filter (where sentiment_label = 'neutral'),
+    count(*) filter (where sentiment_label = 'negative'),
+    sum(w),
+    sum(w * case sentiment_label when 'positive' then 5 when 'neutral' then 3 else 1 end)
+from (
+    select
+        product_id,
+        sentiment_label,
+        sentiment_confidence * power(2, extract(epoch from created_at - '2025-01-01 00:00:00+00'::timestamptz) / (180 * 86400)) as w
+    from reviews
+    where sentiment_status = 'done'
+        and sentiment_label in ('positive', 'neutral', 'negative')
+) r
+group by product_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table product_ratings;
+-- +goose StatementEnd
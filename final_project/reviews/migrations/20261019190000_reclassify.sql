@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table reviews
+    add column sentiment_model text not null default '',
+    add column sentiment_prompt_version text not null default '';
+
+create index reviews_created_at_idx on reviews (created_at, id);
+
+-- Запуски повторной классификации отзывов.
+create table reclassify_runs (
+    id uuid primary key,
+    filter jsonb not null,
+    -- Последний обработанный отзыв; запуск продолжается с него.
+    cursor_created_at timestamptz,
+    cursor_id uuid,
+    processed integer not null default 0,
+    failed integer not null default 0,
+    -- running, done или failed.
+    status text not null,
+    error text not null default '',
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table reclassify_runs;
+drop index reviews_created_at_idx;
+
+alter table reviews
+    drop column sentiment_model,
+    drop column sentiment_prompt_version;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Сессии диалогов с LLM.
+create table chat_sessions (
+    id uuid primary key,
+    model text not null,
+    system text not null default '',
+    -- Число сообщений; используется для обнаружения параллельных изменений.
+    message_count integer not null default 0,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+
+create table chat_messages (
+    session_id uuid not null references chat_sessions (id) on delete cascade,
+    -- Порядковый номер сообщения в сессии, начиная с 0.
+    seq integer not null,
+    role text not null,
+    content text not null,
+    created_at timestamptz not null,
+    primary key (session_id, seq)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table chat_messages;
+drop table chat_sessions;
+-- +goose StatementEnd
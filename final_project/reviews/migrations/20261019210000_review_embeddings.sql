@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Требуется расширение pgvector (например, образ pgvector/pgvector).
+create extension if not exists vector;
+
+-- Отзыв, копией которого признан отзыв, и сходство с ним.
+alter table reviews
+    add column duplicate_of uuid references reviews (id) on delete set null,
+    add column duplicate_similarity double precision not null default 0;
+
+-- Векторы текстов отзывов. Размерность не фиксирована, так как зависит
+-- от модели; сравниваются только векторы одной модели.
+create table review_embeddings (
+    review_id uuid primary key references reviews (id) on delete cascade,
+    model text not null,
+    embedding vector not null,
+    indexed_at timestamptz not null default now()
+);
+
+create index review_embeddings_model_idx on review_embeddings (model);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table review_embeddings;
+
+alter table reviews
+    drop column duplicate_of,
+    drop column duplicate_similarity;
+-- +goose StatementEnd
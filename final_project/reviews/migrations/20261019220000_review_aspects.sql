@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Настроение отзывов по аспектам товара (качество, цена, доставка, поддержка).
+-- Аспекты, не упомянутые в отзыве, не хранятся.
+create table review_aspects (
+    review_id uuid not null references reviews (id) on delete cascade,
+    aspect text not null,
+    label text not null,
+    confidence double precision not null,
+    primary key (review_id, aspect)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table review_aspects;
+-- +goose StatementEnd
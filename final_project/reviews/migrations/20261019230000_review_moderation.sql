@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Статус публикации отзыва и результат модерации. Отзывы, добавленные
+-- до появления модерации, считаются опубликованными.
+alter table reviews
+    add column status text not null default 'published',
+    add column moderation_reasons text[] not null default '{}',
+    add column moderated_by text not null default '',
+    add column moderated_at timestamptz;
+
+-- Очередь модератора.
+create index reviews_pending_idx on reviews (created_at) where status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index reviews_pending_idx;
+
+alter table reviews
+    drop column status,
+    drop column moderation_reasons,
+    drop column moderated_by,
+    drop column moderated_at;
+-- +goose StatementEnd
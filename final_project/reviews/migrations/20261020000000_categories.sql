@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Иерархия категорий каталога. Категория с подкатегориями или товарами
+-- не удаляется (см. DeleteCategory), поэтому каскадного удаления нет.
+create table categories (
+    id uuid primary key,
+    name text not null,
+    parent_id uuid references categories (id),
+    created_at timestamptz not null default now()
+);
+
+create index categories_parent_id_idx on categories (parent_id);
+
+alter table products add column category_id uuid references categories (id);
+
+create index products_category_id_idx on products (category_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table products drop column category_id;
+
+drop table categories;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Счетчики голосов за полезность хранятся в отзыве, чтобы список отзывов
+-- ранжировался без соединения с таблицей голосов.
+alter table reviews
+    add column helpful_up integer not null default 0,
+    add column helpful_down integer not null default 0;
+
+-- Голоса за полезность: один голос пользователя за отзыв.
+create table review_votes (
+    review_id uuid not null references reviews (id) on delete cascade,
+    voter text not null,
+    -- Хэш адреса клиента; с одного клиента за отзыв голосует один пользователь.
+    client text not null,
+    helpful boolean not null,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    primary key (review_id, voter)
+);
+
+create unique index review_votes_client_idx on review_votes (review_id, client);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table review_votes;
+
+alter table reviews
+    drop column helpful_up,
+    drop column helpful_down;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Дневные агрегаты рейтинга товаров по дню создания отзыва (UTC) для истории
+-- рейтинга. Обновляются вместе с product_ratings при изменении настроения отзыва.
+create table product_rating_daily (
+    product_id uuid not null references products (id) on delete cascade,
+    day date not null,
+    positive integer not null default 0,
+    neutral integer not null default 0,
+    negative integer not null default 0,
+    weight_sum double precision not null default 0,
+    score_sum double precision not null default 0,
+    updated_at timestamptz not null default now(),
+    primary key (product_id, day)
+);
+
+-- Поиск товаров с изменившимися агрегатами для обнаружения падения рейтинга.
+create index product_rating_daily_updated_at_idx on product_rating_daily (updated_at);
+
+-- Заполнение по уже классифицированным отзывам, как в миграции product_ratings.
+insert into product_rating_daily (product_id, day, positive, neutral, negative, weight_sum, score_sum)
+select
+    product_id,
+    day,
+    count(*) filter (where sentiment_label = 'positive'),
+    count(*) filter (where sentiment_label = 'neutral'),
+    count(*) filter (where sentiment_label = 'negative'),
+    sum(w),
+    sum(w * case sentiment_label when 'positive' then 5 when 'neutral' then 3 else 1 end)
+from (
+    select
+        product_id,
+        (created_at at time zone 'UTC')::date as day,
+        sentiment_label,
+        sentiment_confidence * power(2, extract(epoch from created_at - '2025-01-01 00:00:00+00'::timestamptz) / (180 * 86400)) as w
+    from reviews
+    where sentiment_status = 'done'
+        and sentiment_label in ('positive', 'neutral', 'negative')
+) r
+group by product_id, day;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table product_rating_daily;
+-- +goose StatementEnd
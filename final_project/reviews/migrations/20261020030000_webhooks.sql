@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Подписки на события (см. пакет internal/webhook). Пустой фильтр
+-- не ограничивает события.
+create table webhooks (
+    id uuid primary key,
+    url text not null,
+    -- Ключ подписи HMAC-SHA256 запросов к подписчику.
+    secret text not null,
+    events text[] not null default '{}',
+    -- Товары не связаны внешним ключом: подписка переживает удаление товара.
+    product_ids text[] not null default '{}',
+    labels text[] not null default '{}',
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+
+-- Доставки событий подписчикам; служат и журналом отправки.
+create table webhook_deliveries (
+    id uuid primary key,
+    webhook_id uuid not null references webhooks (id) on delete cascade,
+    event_id uuid not null,
+    event text not null,
+    payload jsonb not null,
+    -- pending, delivered или failed.
+    status text not null default 'pending',
+    attempts integer not null default 0,
+    next_attempt_at timestamptz not null default now(),
+    response_status integer not null default 0,
+    error text not null default '',
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+
+create index webhook_deliveries_pending_idx on webhook_deliveries (next_attempt_at)
+    where status = 'pending';
+create index webhook_deliveries_webhook_id_idx on webhook_deliveries (webhook_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table webhook_deliveries;
+drop table webhooks;
+-- +goose StatementEnd
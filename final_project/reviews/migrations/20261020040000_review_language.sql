@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Код языка отзыва (ISO 639-1), определяемый сервисом при сохранении отзыва.
+alter table reviews add column language text not null default 'und';
+
+-- Существующие отзывы размечаются упрощенно по алфавиту; точнее язык
+-- определяется при следующем изменении текста отзыва.
+update reviews set language = case
+    when text ~* '[іїєґ]' and text !~* '[ыэъё]' then 'uk'
+    when text ~* '[а-яё]' then 'ru'
+    when text ~* '[a-z]' then 'en'
+    else 'und'
+end;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table reviews drop column language;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Полнотекстовый индекс текста отзыва. Отзывы пишут на русском и английском,
+-- поэтому текст разбирается обеими конфигурациями.
+alter table reviews add column search tsvector generated always as (
+    to_tsvector('russian', text) || to_tsvector('english', text)
+) stored;
+
+create index reviews_search_idx on reviews using gin (search);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index reviews_search_idx;
+alter table reviews drop column search;
+-- +goose StatementEnd
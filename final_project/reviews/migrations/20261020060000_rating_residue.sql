@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Агрегаты без отзывов могли сохранить ошибку округления после вычитания
+-- всех вкладов; теперь их суммы обнуляются при обновлении.
+update product_ratings set weight_sum = 0, score_sum = 0
+where positive + neutral + negative = 0 and (weight_sum <> 0 or score_sum <> 0);
+
+update product_rating_daily set weight_sum = 0, score_sum = 0
+where positive + neutral + negative = 0 and (weight_sum <> 0 or score_sum <> 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Ошибка округления не восстанавливается.
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Токен захвата доставки: результат попытки сохраняется, только если
+-- доставка не была захвачена заново после истечения аренды.
+alter table webhook_deliveries add column lease_token uuid;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table webhook_deliveries drop column lease_token;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Аренда запуска переклассификации: запуск выполняет один экземпляр
+-- сервиса, пока аренда не истекла.
+alter table reclassify_runs
+    add column lease_token uuid,
+    add column leased_until timestamptz;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table reclassify_runs
+    drop column lease_token,
+    drop column leased_until;
+-- +goose StatementEnd
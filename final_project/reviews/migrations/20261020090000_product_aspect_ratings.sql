@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Агрегаты рейтинга товаров по аспектам. Обновляются вместе с product_ratings
+-- при изменении настроения отзыва, чтобы рейтинг товара не сворачивал
+-- аспекты всех его отзывов при каждом запросе.
+create table product_aspect_ratings (
+    product_id uuid not null references products (id) on delete cascade,
+    aspect text not null,
+    positive integer not null default 0,
+    neutral integer not null default 0,
+    negative integer not null default 0,
+    weight_sum double precision not null default 0,
+    score_sum double precision not null default 0,
+    updated_at timestamptz not null default now(),
+    primary key (product_id, aspect)
+);
+
+-- Заполнение по аспектам уже классифицированных отзывов, как в миграции product_ratings.
+insert into product_aspect_ratings (product_id, aspect, positive, neutral, negative, weight_sum, score_sum)
+select
+    product_id,
+    aspect,
+    count(*) filter (where label = 'positive'),
+    count(*) filter (where label = 'neutral'),
+    count(*) filter (where label = 'negative'),
+    sum(w),
+    sum(w * case label when 'positive' then 5 when 'neutral' then 3 else 1 end)
+from (
+    select
+        r.product_id,
+        a.aspect,
+        a.label,
+        a.confidence * power(2, extract(epoch from r.created_at - '2025-01-01 00:00:00+00'::timestamptz) / (180 * 86400)) as w
+    from review_aspects a
+    join reviews r on r.id = a.review_id
+    where r.sentiment_status = 'done'
+        and a.label in ('positive', 'neutral', 'negative')
+) r
+group by product_id, aspect;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table product_aspect_ratings;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Агрегаты рейтинга товаров по языкам отзывов. Обновляются вместе с
+-- product_ratings при изменении настроения отзыва.
+create table product_language_ratings (
+    product_id uuid not null references products (id) on delete cascade,
+    language text not null,
+    positive integer not null default 0,
+    neutral integer not null default 0,
+    negative integer not null default 0,
+    weight_sum double precision not null default 0,
+    score_sum double precision not null default 0,
+    updated_at timestamptz not null default now(),
+    primary key (product_id, language)
+);
+
+-- Заполнение по уже классифицированным отзывам, как в миграции product_ratings.
+insert into product_language_ratings (product_id, language, positive, neutral, negative, weight_sum, score_sum)
+select
+    product_id,
+    language,
+    count(*) filter (where sentiment_label = 'positive'),
+    count(*) filter (where sentiment_label = 'neutral'),
+    count(*) filter (where sentiment_label = 'negative'),
+    sum(w),
+    sum(w * case sentiment_label when 'positive' then 5 when 'neutral' then 3 else 1 end)
+from (
+    select
+        product_id,
+        language,
+        sentiment_label,
+        sentiment_confidence * power(2, extract(epoch from created_at - '2025-01-01 00:00:00+00'::timestamptz) / (180 * 86400)) as w
+    from reviews
+    where sentiment_status = 'done'
+        and sentiment_label in ('positive', 'neutral', 'negative')
+) r
+group by product_id, language;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table product_language_ratings;
+-- +goose StatementEnd